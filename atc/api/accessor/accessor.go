@@ -129,6 +129,7 @@ var requiredRoles = map[string]string{
 	atc.GetBuildPreparation:           "viewer",
 	atc.GetJob:                        "viewer",
 	atc.CreateJobBuild:                "member",
+	atc.RerunJobBuild:                 "member",
 	atc.ListAllJobs:                   "viewer",
 	atc.ListJobs:                      "viewer",
 	atc.ListJobBuilds:                 "viewer",
@@ -181,9 +182,15 @@ var requiredRoles = map[string]string{
 	atc.DeleteWorker:                  "member",
 	atc.SetLogLevel:                   "member",
 	atc.GetLogLevel:                   "viewer",
+	atc.GetEmitters:                   "viewer",
+	atc.SetEmitterState:               "member",
+	atc.GetMetricCatalog:              "viewer",
+	atc.GetMetrics:                    "viewer",
 	atc.DownloadCLI:                   "viewer",
 	atc.GetInfo:                       "viewer",
 	atc.GetInfoCreds:                  "viewer",
+	atc.Healthz:                       "viewer",
+	atc.Readyz:                        "viewer",
 	atc.ListContainers:                "viewer",
 	atc.GetContainer:                  "viewer",
 	atc.HijackContainer:               "member",
@@ -200,4 +207,5 @@ var requiredRoles = map[string]string{
 	atc.CreateArtifact:                "member",
 	atc.GetArtifact:                   "member",
 	atc.ListBuildArtifacts:            "viewer",
+	atc.GetBuildArtifact:              "viewer",
 }
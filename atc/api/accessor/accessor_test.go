@@ -390,6 +390,10 @@ var _ = Describe("Accessor", func() {
 		Entry("member :: "+atc.CreateJobBuild, atc.CreateJobBuild, "member", true),
 		Entry("viewer :: "+atc.CreateJobBuild, atc.CreateJobBuild, "viewer", false),
 
+		Entry("owner :: "+atc.RerunJobBuild, atc.RerunJobBuild, "owner", true),
+		Entry("member :: "+atc.RerunJobBuild, atc.RerunJobBuild, "member", true),
+		Entry("viewer :: "+atc.RerunJobBuild, atc.RerunJobBuild, "viewer", false),
+
 		Entry("owner :: "+atc.ListAllJobs, atc.ListAllJobs, "owner", true),
 		Entry("member :: "+atc.ListAllJobs, atc.ListAllJobs, "member", true),
 		Entry("viewer :: "+atc.ListAllJobs, atc.ListAllJobs, "viewer", true),
@@ -586,6 +590,22 @@ var _ = Describe("Accessor", func() {
 		Entry("member :: "+atc.GetLogLevel, atc.GetLogLevel, "member", true),
 		Entry("viewer :: "+atc.GetLogLevel, atc.GetLogLevel, "viewer", true),
 
+		Entry("owner :: "+atc.GetEmitters, atc.GetEmitters, "owner", true),
+		Entry("member :: "+atc.GetEmitters, atc.GetEmitters, "member", true),
+		Entry("viewer :: "+atc.GetEmitters, atc.GetEmitters, "viewer", true),
+
+		Entry("owner :: "+atc.SetEmitterState, atc.SetEmitterState, "owner", true),
+		Entry("member :: "+atc.SetEmitterState, atc.SetEmitterState, "member", true),
+		Entry("viewer :: "+atc.SetEmitterState, atc.SetEmitterState, "viewer", false),
+
+		Entry("owner :: "+atc.GetMetricCatalog, atc.GetMetricCatalog, "owner", true),
+		Entry("member :: "+atc.GetMetricCatalog, atc.GetMetricCatalog, "member", true),
+		Entry("viewer :: "+atc.GetMetricCatalog, atc.GetMetricCatalog, "viewer", true),
+
+		Entry("owner :: "+atc.GetMetrics, atc.GetMetrics, "owner", true),
+		Entry("member :: "+atc.GetMetrics, atc.GetMetrics, "member", true),
+		Entry("viewer :: "+atc.GetMetrics, atc.GetMetrics, "viewer", true),
+
 		Entry("owner :: "+atc.DownloadCLI, atc.DownloadCLI, "owner", true),
 		Entry("member :: "+atc.DownloadCLI, atc.DownloadCLI, "member", true),
 		Entry("viewer :: "+atc.DownloadCLI, atc.DownloadCLI, "viewer", true),
@@ -598,6 +618,14 @@ var _ = Describe("Accessor", func() {
 		Entry("member :: "+atc.GetInfoCreds, atc.GetInfoCreds, "member", true),
 		Entry("viewer :: "+atc.GetInfoCreds, atc.GetInfoCreds, "viewer", true),
 
+		Entry("owner :: "+atc.Healthz, atc.Healthz, "owner", true),
+		Entry("member :: "+atc.Healthz, atc.Healthz, "member", true),
+		Entry("viewer :: "+atc.Healthz, atc.Healthz, "viewer", true),
+
+		Entry("owner :: "+atc.Readyz, atc.Readyz, "owner", true),
+		Entry("member :: "+atc.Readyz, atc.Readyz, "member", true),
+		Entry("viewer :: "+atc.Readyz, atc.Readyz, "viewer", true),
+
 		Entry("owner :: "+atc.ListContainers, atc.ListContainers, "owner", true),
 		Entry("member :: "+atc.ListContainers, atc.ListContainers, "member", true),
 		Entry("viewer :: "+atc.ListContainers, atc.ListContainers, "viewer", true),
@@ -661,5 +689,9 @@ var _ = Describe("Accessor", func() {
 		Entry("owner :: "+atc.ListBuildArtifacts, atc.ListBuildArtifacts, "owner", true),
 		Entry("member :: "+atc.ListBuildArtifacts, atc.ListBuildArtifacts, "member", true),
 		Entry("viewer :: "+atc.ListBuildArtifacts, atc.ListBuildArtifacts, "viewer", true),
+
+		Entry("owner :: "+atc.GetBuildArtifact, atc.GetBuildArtifact, "owner", true),
+		Entry("member :: "+atc.GetBuildArtifact, atc.GetBuildArtifact, "member", true),
+		Entry("viewer :: "+atc.GetBuildArtifact, atc.GetBuildArtifact, "viewer", true),
 	)
 })
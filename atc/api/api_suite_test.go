@@ -41,6 +41,7 @@ var (
 	dbJobFactory            *dbfakes.FakeJobFactory
 	dbResourceFactory       *dbfakes.FakeResourceFactory
 	dbResourceConfigFactory *dbfakes.FakeResourceConfigFactory
+	dbConn                  *dbfakes.FakeConn
 	fakePipeline            *dbfakes.FakePipeline
 	fakeAccessor            *accessorfakes.FakeAccessFactory
 	dbWorkerFactory         *dbfakes.FakeWorkerFactory
@@ -52,6 +53,7 @@ var (
 	fakeVariablesFactory    *credsfakes.FakeVariablesFactory
 	credsManagers           creds.Managers
 	interceptTimeoutFactory *containerserverfakes.FakeInterceptTimeoutFactory
+	maxSpooledArtifactSize  int64
 	interceptTimeout        *containerserverfakes.FakeInterceptTimeout
 	drain                   chan struct{}
 	expire                  time.Duration
@@ -97,6 +99,8 @@ var _ = BeforeEach(func() {
 	interceptTimeout = new(containerserverfakes.FakeInterceptTimeout)
 	interceptTimeoutFactory.NewInterceptTimeoutReturns(interceptTimeout)
 
+	maxSpooledArtifactSize = 64
+
 	dbTeam = new(dbfakes.FakeTeam)
 	dbTeam.IDReturns(734)
 	dbTeamFactory.FindTeamReturns(dbTeam, true, nil)
@@ -109,6 +113,8 @@ var _ = BeforeEach(func() {
 	dbWorkerFactory = new(dbfakes.FakeWorkerFactory)
 	dbWorkerLifecycle = new(dbfakes.FakeWorkerLifecycle)
 
+	dbConn = new(dbfakes.FakeConn)
+
 	drain = make(chan struct{})
 
 	fakeWorkerClient = new(workerfakes.FakeClient)
@@ -168,6 +174,7 @@ var _ = BeforeEach(func() {
 		fakeDestroyer,
 		dbBuildFactory,
 		dbResourceConfigFactory,
+		dbConn,
 
 		constructedEventHandler.Construct,
 		drain,
@@ -186,6 +193,7 @@ var _ = BeforeEach(func() {
 		fakeVariablesFactory,
 		credsManagers,
 		interceptTimeoutFactory,
+		maxSpooledArtifactSize,
 	)
 
 	Expect(err).NotTo(HaveOccurred())
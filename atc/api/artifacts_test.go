@@ -348,4 +348,191 @@ var _ = Describe("Artifacts API", func() {
 			})
 		})
 	})
+
+	Describe("GET /api/v1/builds/:build_id/artifacts/:artifact_name", func() {
+		var request *http.Request
+		var response *http.Response
+
+		BeforeEach(func() {
+			var err error
+			request, err = http.NewRequest("GET", server.URL+"/api/v1/builds/42/artifacts/some-artifact", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			build.TeamIDReturns(734)
+			build.TeamNameReturns("some-team")
+			build.JobNameReturns("some-job")
+			build.PipelineReturns(fakePipeline, true, nil)
+			dbBuildFactory.BuildReturns(build, true, nil)
+
+			fakeaccess.IsAuthenticatedReturns(true)
+			fakeaccess.IsAuthorizedReturns(true)
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			response, err = client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when not authenticated", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(false)
+			})
+
+			It("returns 401", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+
+		Context("when not authorized for the build's team", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthorizedReturns(false)
+			})
+
+			It("returns 403", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(fakeaccess.IsAuthorizedArgsForCall(0)).To(Equal("some-team"))
+			})
+		})
+
+		Context("when getting the artifacts of the build fails", func() {
+			BeforeEach(func() {
+				build.ArtifactsReturns(nil, errors.New("nope"))
+			})
+
+			It("returns 500", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+			})
+		})
+
+		Context("when the build has no artifact with the name", func() {
+			BeforeEach(func() {
+				otherArtifact := new(dbfakes.FakeWorkerArtifact)
+				otherArtifact.NameReturns("some-other-artifact")
+
+				build.ArtifactsReturns([]db.WorkerArtifact{otherArtifact}, nil)
+			})
+
+			It("returns 404", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
+
+		Context("when the build has the artifact", func() {
+			var fakeArtifact *dbfakes.FakeWorkerArtifact
+
+			BeforeEach(func() {
+				fakeArtifact = new(dbfakes.FakeWorkerArtifact)
+				fakeArtifact.NameReturns("some-artifact")
+				fakeArtifact.CreatedAtReturns(time.Unix(42, 0))
+
+				build.ArtifactsReturns([]db.WorkerArtifact{fakeArtifact}, nil)
+			})
+
+			Context("when the artifact has no volume", func() {
+				BeforeEach(func() {
+					fakeArtifact.VolumeReturns(nil, false, nil)
+				})
+
+				It("returns 404", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+				})
+			})
+
+			Context("when the artifact has a volume", func() {
+				var fakeWorkerVolume *workerfakes.FakeVolume
+				var contents []byte
+
+				BeforeEach(func() {
+					fakeVolume := new(dbfakes.FakeCreatedVolume)
+					fakeVolume.HandleReturns("some-handle")
+					fakeArtifact.VolumeReturns(fakeVolume, true, nil)
+
+					contents = []byte("some-content")
+
+					fakeWorkerVolume = new(workerfakes.FakeVolume)
+					fakeWorkerVolume.StreamOutStub = func(string) (io.ReadCloser, error) {
+						return ioutil.NopCloser(bytes.NewReader(contents)), nil
+					}
+					fakeWorkerClient.FindVolumeReturns(fakeWorkerVolume, true, nil)
+				})
+
+				It("looks up the worker volume of the build's team", func() {
+					Expect(fakeArtifact.VolumeArgsForCall(0)).To(Equal(734))
+
+					_, teamID, handle := fakeWorkerClient.FindVolumeArgsForCall(0)
+					Expect(teamID).To(Equal(734))
+					Expect(handle).To(Equal("some-handle"))
+				})
+
+				Context("when the worker can't find the volume", func() {
+					BeforeEach(func() {
+						fakeWorkerClient.FindVolumeReturns(nil, false, nil)
+					})
+
+					It("returns 404", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+					})
+				})
+
+				Context("when streaming volume contents fails", func() {
+					BeforeEach(func() {
+						fakeWorkerVolume.StreamOutReturns(nil, errors.New("nope"))
+					})
+
+					It("returns 500", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+					})
+				})
+
+				It("returns the contents of the volume as a download", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusOK))
+					Expect(response.Header.Get("Content-Type")).To(Equal("application/gzip"))
+					Expect(response.Header.Get("Content-Disposition")).To(Equal(`attachment; filename="some-artifact.tgz"`))
+					Expect(response.Header.Get("Accept-Ranges")).To(Equal("bytes"))
+					Expect(response.Header.Get("Last-Modified")).To(Equal(time.Unix(42, 0).UTC().Format(http.TimeFormat)))
+					Expect(ioutil.ReadAll(response.Body)).To(Equal([]byte("some-content")))
+				})
+
+				It("streams the contents without spooling them", func() {
+					Expect(fakeWorkerVolume.StreamOutCallCount()).To(Equal(1))
+				})
+
+				Context("when a range is requested", func() {
+					BeforeEach(func() {
+						request.Header.Set("Range", "bytes=5-")
+					})
+
+					It("returns the rest of the contents", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusPartialContent))
+						Expect(response.Header.Get("Content-Range")).To(Equal("bytes 5-11/12"))
+						Expect(ioutil.ReadAll(response.Body)).To(Equal([]byte("content")))
+					})
+
+					Context("when the artifact is larger than the maximum spooled size", func() {
+						BeforeEach(func() {
+							contents = bytes.Repeat([]byte("x"), 65)
+						})
+
+						It("returns all of the contents", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusOK))
+							Expect(response.Header.Get("Content-Range")).To(BeEmpty())
+							Expect(ioutil.ReadAll(response.Body)).To(Equal(contents))
+						})
+					})
+
+					Context("when the artifact is exactly the maximum spooled size", func() {
+						BeforeEach(func() {
+							contents = bytes.Repeat([]byte("x"), 64)
+						})
+
+						It("returns the requested range", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusPartialContent))
+							Expect(response.Header.Get("Content-Range")).To(Equal("bytes 5-63/64"))
+						})
+					})
+				})
+			})
+		})
+	})
 })
@@ -0,0 +1,141 @@
+package artifactserver
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/db"
+	"github.com/concourse/concourse/atc/worker"
+)
+
+// GetBuildArtifact serves the contents of the named artifact of the build as
+// a gzipped tarball, streamed from the worker. For range requests the
+// contents are spooled to disk first, so that interrupted downloads can be
+// resumed. Artifacts larger than the maximum spooled size are served as a
+// whole instead, as allowed for servers which do not support ranges.
+func (s *Server) GetBuildArtifact(build db.Build) http.Handler {
+	logger := s.logger.Session("get-build-artifact")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		artifactName := r.FormValue(":artifact_name")
+
+		artifacts, err := build.Artifacts()
+		if err != nil {
+			logger.Error("failed-to-get-build-artifacts", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var artifact db.WorkerArtifact
+		for _, a := range artifacts {
+			if a.Name() == artifactName {
+				artifact = a
+				break
+			}
+		}
+
+		if artifact == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		artifactVolume, found, err := artifact.Volume(build.TeamID())
+		if err != nil {
+			logger.Error("failed-to-get-artifact-volume", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		workerVolume, found, err := s.workerClient.FindVolume(logger, build.TeamID(), artifactVolume.Handle())
+		if err != nil {
+			logger.Error("failed-to-get-worker-volume", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fileName := artifact.Name() + ".tgz"
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+		if r.Header.Get("Range") != "" {
+			spool, fits, err := s.spool(workerVolume)
+			if err != nil {
+				logger.Error("failed-to-spool-artifact", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			if fits {
+				defer os.Remove(spool.Name())
+				defer spool.Close()
+
+				http.ServeContent(w, r, fileName, artifact.CreatedAt(), spool)
+				return
+			}
+		}
+
+		reader, err := workerVolume.StreamOut("/")
+		if err != nil {
+			logger.Error("failed-to-stream-volume-contents", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		defer reader.Close()
+
+		w.Header().Set("Accept-Ranges", "bytes")
+		w.Header().Set("Last-Modified", artifact.CreatedAt().UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+
+		_, err = io.Copy(w, reader)
+		if err != nil {
+			logger.Error("failed-to-stream-artifact", err, lager.Data{"artifact": artifact.Name()})
+		}
+	})
+}
+
+// spool copies the contents of the volume to a temporary file, unless they
+// are larger than the maximum spooled size, in which case false is returned.
+func (s *Server) spool(volume worker.Volume) (*os.File, bool, error) {
+	reader, err := volume.StreamOut("/")
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer reader.Close()
+
+	spool, err := ioutil.TempFile("", "build-artifact")
+	if err != nil {
+		return nil, false, err
+	}
+
+	size, err := io.CopyN(spool, reader, s.maxSpooledArtifactSize+1)
+	if err != nil && err != io.EOF {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, false, err
+	}
+
+	if size > s.maxSpooledArtifactSize {
+		spool.Close()
+		os.Remove(spool.Name())
+		return nil, false, nil
+	}
+
+	return spool, true, nil
+}
@@ -8,14 +8,19 @@ import (
 type Server struct {
 	logger       lager.Logger
 	workerClient worker.Client
+
+	maxSpooledArtifactSize int64
 }
 
 func NewServer(
 	logger lager.Logger,
 	workerClient worker.Client,
+	maxSpooledArtifactSize int64,
 ) *Server {
 	return &Server{
 		logger:       logger,
 		workerClient: workerClient,
+
+		maxSpooledArtifactSize: maxSpooledArtifactSize,
 	}
 }
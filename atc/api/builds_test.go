@@ -233,6 +233,28 @@ var _ = Describe("Builds API", func() {
 				})
 			})
 
+			Context("when the limit is above the maximum", func() {
+				BeforeEach(func() {
+					queryParams = "?limit=100000"
+				})
+
+				It("caps it", func() {
+					_, page := dbBuildFactory.VisibleBuildsArgsForCall(0)
+					Expect(page.Limit).To(Equal(1000))
+				})
+			})
+
+			Context("when the limit is negative", func() {
+				BeforeEach(func() {
+					queryParams = "?limit=-5"
+				})
+
+				It("uses the default limit", func() {
+					_, page := dbBuildFactory.VisibleBuildsArgsForCall(0)
+					Expect(page.Limit).To(Equal(100))
+				})
+			})
+
 			Context("when getting the builds succeeds", func() {
 				BeforeEach(func() {
 					dbBuildFactory.VisibleBuildsReturns(returnedBuilds, db.Pagination{}, nil)
@@ -1024,6 +1046,25 @@ var _ = Describe("Builds API", func() {
 				Context("when authorized", func() {
 					BeforeEach(func() {
 						fakeaccess.IsAuthorizedReturns(true)
+						build.IsRunningReturns(true)
+					})
+
+					Context("when the build has already finished", func() {
+						BeforeEach(func() {
+							build.IsRunningReturns(false)
+							build.StatusReturns(db.BuildStatusSucceeded)
+							build.ReloadReturns(true, nil)
+						})
+
+						It("returns 200 with the build, without aborting it", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusOK))
+							Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+							Expect(build.MarkAsAbortedCallCount()).To(BeZero())
+
+							var presented atc.Build
+							Expect(json.NewDecoder(response.Body).Decode(&presented)).To(Succeed())
+							Expect(presented.Status).To(Equal("succeeded"))
+						})
 					})
 
 					Context("when aborting the build fails", func() {
@@ -1041,8 +1082,43 @@ var _ = Describe("Builds API", func() {
 							build.MarkAsAbortedReturns(nil)
 						})
 
-						It("returns 204", func() {
-							Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+						Context("when reloading the build fails", func() {
+							BeforeEach(func() {
+								build.ReloadReturns(false, errors.New("nope"))
+							})
+
+							It("returns 500", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+							})
+						})
+
+						Context("when the build is gone once reloaded", func() {
+							BeforeEach(func() {
+								build.ReloadReturns(false, nil)
+							})
+
+							It("returns 404", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+							})
+						})
+
+						Context("when reloading the build succeeds", func() {
+							BeforeEach(func() {
+								build.ReloadStub = func() (bool, error) {
+									build.StatusReturns(db.BuildStatusAborted)
+									return true, nil
+								}
+							})
+
+							It("returns 200 with the aborted build", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusOK))
+								Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+								Expect(build.MarkAsAbortedCallCount()).To(Equal(1))
+
+								var presented atc.Build
+								Expect(json.NewDecoder(response.Body).Decode(&presented)).To(Succeed())
+								Expect(presented.Status).To(Equal("aborted"))
+							})
 						})
 					})
 				})
@@ -1330,6 +1406,8 @@ var _ = Describe("Builds API", func() {
 					BeforeEach(func() {
 						build.PipelineReturns(fakePipeline, true, nil)
 						fakePipeline.PublicReturns(true)
+						build.PublicPlanReturns(plan)
+						build.HasPlanReturns(true)
 					})
 
 					It("returns 200", func() {
@@ -1347,6 +1425,7 @@ var _ = Describe("Builds API", func() {
 				Context("when the build returns a plan", func() {
 					BeforeEach(func() {
 						build.PublicPlanReturns(plan)
+						build.HasPlanReturns(true)
 						build.SchemaReturns("some-schema")
 					})
 
@@ -1368,6 +1447,16 @@ var _ = Describe("Builds API", func() {
 					}`))
 					})
 				})
+
+				Context("when the build has not been scheduled and has no plan", func() {
+					BeforeEach(func() {
+						build.HasPlanReturns(false)
+					})
+
+					It("returns Not Found", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+					})
+				})
 			})
 		})
 
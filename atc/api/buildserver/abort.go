@@ -1,9 +1,11 @@
 package buildserver
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/api/present"
 	"github.com/concourse/concourse/atc/db"
 )
 
@@ -13,13 +15,34 @@ func (s *Server) AbortBuild(build db.Build) http.Handler {
 			"build": build.ID(),
 		})
 
-		err := build.MarkAsAborted()
+		// marking a finished build as aborted would overwrite its status
+		if build.IsRunning() {
+			err := build.MarkAsAborted()
+			if err != nil {
+				aLog.Error("failed-to-abort-build", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+
+		found, err := build.Reload()
 		if err != nil {
-			aLog.Error("failed-to-abort-build", err)
+			aLog.Error("failed-to-reload-build", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 
-		w.WriteHeader(http.StatusNoContent)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(present.Build(build))
+		if err != nil {
+			aLog.Error("failed-to-encode-build", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 	})
 }
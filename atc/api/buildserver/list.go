@@ -33,8 +33,10 @@ func (s *Server) ListBuilds(w http.ResponseWriter, r *http.Request) {
 	urlLimit := r.FormValue(atc.PaginationQueryLimit)
 
 	limit, _ = strconv.Atoi(urlLimit)
-	if limit == 0 {
+	if limit <= 0 {
 		limit = atc.PaginationAPIDefaultLimit
+	} else if limit > atc.PaginationAPIMaxLimit {
+		limit = atc.PaginationAPIMaxLimit
 	}
 
 	page := db.Page{Until: until, Since: since, Limit: limit}
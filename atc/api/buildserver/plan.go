@@ -12,6 +12,11 @@ func (s *Server) GetBuildPlan(build db.Build) http.Handler {
 	hLog := s.logger.Session("get-build-plan")
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !build.HasPlan() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		err := json.NewEncoder(w).Encode(atc.PublicBuildPlan{
 			Schema: build.Schema(),
@@ -275,6 +275,10 @@ var _ = Describe("Config API", func() {
 									Expect(response.Header.Get(atc.ConfigVersionHeader)).To(Equal("1"))
 								})
 
+								It("returns the config version as the ETag", func() {
+									Expect(response.Header.Get("ETag")).To(Equal(`"1"`))
+								})
+
 								It("returns the config", func() {
 									var actualConfigResponse atc.ConfigResponse
 									err := json.NewDecoder(response.Body).Decode(&actualConfigResponse)
@@ -389,6 +393,10 @@ var _ = Describe("Config API", func() {
 				"pipeline_name": "a-pipeline",
 			}, nil)
 			Expect(err).NotTo(HaveOccurred())
+
+			savedPipeline := new(dbfakes.FakePipeline)
+			savedPipeline.ConfigVersionReturns(43)
+			dbTeam.SavePipelineReturns(savedPipeline, false, nil)
 		})
 
 		JustBeforeEach(func() {
@@ -495,6 +503,11 @@ var _ = Describe("Config API", func() {
 							Expect(pipelineState).To(Equal(db.PipelineNoChange))
 						})
 
+						It("returns the new config version", func() {
+							Expect(response.Header.Get(atc.ConfigVersionHeader)).To(Equal("43"))
+							Expect(response.Header.Get("ETag")).To(Equal(`"43"`))
+						})
+
 						Context("and saving it fails", func() {
 							BeforeEach(func() {
 								dbTeam.SavePipelineReturns(nil, false, errors.New("oh no!"))
@@ -509,6 +522,16 @@ var _ = Describe("Config API", func() {
 							})
 						})
 
+						Context("and the config has changed since the version", func() {
+							BeforeEach(func() {
+								dbTeam.SavePipelineReturns(nil, false, db.ErrConfigComparisonFailed)
+							})
+
+							It("returns 409", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusConflict))
+							})
+						})
+
 						Context("when it's the first time the pipeline has been created", func() {
 							BeforeEach(func() {
 								returnedPipeline := new(dbfakes.FakePipeline)
@@ -1251,6 +1274,100 @@ jobs:
 				})
 			})
 
+			Context("when a config version is given with If-Match", func() {
+				BeforeEach(func() {
+					request.Header.Set("If-Match", `"42"`)
+					request.Header.Set("Content-Type", "application/json")
+
+					payload, err := json.Marshal(pipelineConfig)
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Body = gbytes.BufferWithBytes(payload)
+				})
+
+				It("saves it with the version", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+					Expect(dbTeam.SavePipelineCallCount()).To(Equal(1))
+					_, _, id, _ := dbTeam.SavePipelineArgsForCall(0)
+					Expect(id).To(Equal(db.ConfigVersion(42)))
+				})
+			})
+
+			Context("when a weak config version is given with If-Match", func() {
+				BeforeEach(func() {
+					request.Header.Set("If-Match", `W/"42"`)
+					request.Header.Set("Content-Type", "application/json")
+
+					payload, err := json.Marshal(pipelineConfig)
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Body = gbytes.BufferWithBytes(payload)
+				})
+
+				It("saves it with the version", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+					Expect(dbTeam.SavePipelineCallCount()).To(Equal(1))
+					_, _, id, _ := dbTeam.SavePipelineArgsForCall(0)
+					Expect(id).To(Equal(db.ConfigVersion(42)))
+				})
+			})
+
+			Context("when If-Match is *", func() {
+				BeforeEach(func() {
+					request.Header.Set("If-Match", "*")
+					request.Header.Set("Content-Type", "application/json")
+
+					payload, err := json.Marshal(pipelineConfig)
+					Expect(err).NotTo(HaveOccurred())
+
+					request.Body = gbytes.BufferWithBytes(payload)
+				})
+
+				Context("when the pipeline exists", func() {
+					BeforeEach(func() {
+						currentPipeline := new(dbfakes.FakePipeline)
+						currentPipeline.ConfigVersionReturns(7)
+						dbTeam.PipelineReturns(currentPipeline, true, nil)
+					})
+
+					It("saves it with the current version", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+						Expect(dbTeam.PipelineCallCount()).To(Equal(1))
+						Expect(dbTeam.PipelineArgsForCall(0)).To(Equal("a-pipeline"))
+
+						Expect(dbTeam.SavePipelineCallCount()).To(Equal(1))
+						_, _, id, _ := dbTeam.SavePipelineArgsForCall(0)
+						Expect(id).To(Equal(db.ConfigVersion(7)))
+					})
+				})
+
+				Context("when the pipeline does not exist", func() {
+					BeforeEach(func() {
+						dbTeam.PipelineReturns(nil, false, nil)
+					})
+
+					It("saves it without a version", func() {
+						Expect(dbTeam.SavePipelineCallCount()).To(Equal(1))
+						_, _, id, _ := dbTeam.SavePipelineArgsForCall(0)
+						Expect(id).To(Equal(db.ConfigVersion(0)))
+					})
+				})
+
+				Context("when finding the pipeline fails", func() {
+					BeforeEach(func() {
+						dbTeam.PipelineReturns(nil, false, errors.New("nope"))
+					})
+
+					It("returns 500", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+						Expect(dbTeam.SavePipelineCallCount()).To(Equal(0))
+					})
+				})
+			})
+
 			Context("when a config version is malformed", func() {
 				BeforeEach(func() {
 					request.Header.Set(atc.ConfigVersionHeader, "forty-two")
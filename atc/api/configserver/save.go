@@ -64,8 +64,17 @@ func (s *Server) SaveConfig(w http.ResponseWriter, r *http.Request) {
 		checkCredentials = true
 	}
 
+	configVersionStr := r.Header.Get(atc.ConfigVersionHeader)
+	if len(configVersionStr) == 0 {
+		// the version may also be given as the ETag returned when getting the
+		// config, e.g. "42" or W/"42", or as * to match any current version
+		configVersionStr = strings.Trim(strings.TrimPrefix(r.Header.Get("If-Match"), "W/"), `"`)
+	}
+
+	matchAnyVersion := configVersionStr == "*"
+
 	var version db.ConfigVersion
-	if configVersionStr := r.Header.Get(atc.ConfigVersionHeader); len(configVersionStr) != 0 {
+	if len(configVersionStr) != 0 && !matchAnyVersion {
 		_, err := fmt.Sscanf(configVersionStr, "%d", &version)
 		if err != nil {
 			session.Error("malformed-config-version", err)
@@ -146,16 +155,37 @@ func (s *Server) SaveConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, created, err := team.SavePipeline(pipelineName, config, version, pausedState)
+	if matchAnyVersion {
+		current, found, err := team.Pipeline(pipelineName)
+		if err != nil {
+			session.Error("failed-to-find-pipeline", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if found {
+			version = current.ConfigVersion()
+		}
+	}
+
+	pipeline, created, err := team.SavePipeline(pipelineName, config, version, pausedState)
 	if err != nil {
 		session.Error("failed-to-save-config", err)
-		w.WriteHeader(http.StatusInternalServerError)
+
+		if err == db.ErrConfigComparisonFailed {
+			w.WriteHeader(http.StatusConflict)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
 		fmt.Fprintf(w, "failed to save config: %s", err)
 		return
 	}
 
 	session.Info("saved")
 
+	w.Header().Set(atc.ConfigVersionHeader, fmt.Sprintf("%d", pipeline.ConfigVersion()))
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, pipeline.ConfigVersion()))
 	w.Header().Set("Content-Type", "application/json")
 
 	if created {
@@ -0,0 +1,277 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/atc/api/accessor/accessorfakes"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metric Emitters API", func() {
+	var (
+		fakeaccess *accessorfakes.FakeAccess
+		response   *http.Response
+	)
+
+	BeforeEach(func() {
+		fakeaccess = new(accessorfakes.FakeAccess)
+
+		emitterFactory := new(metricfakes.FakeEmitterFactory)
+		emitterFactory.DescriptionReturns("Google Pub/Sub")
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(new(metricfakes.FakeEmitter), nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	JustBeforeEach(func() {
+		fakeAccessor.CreateReturns(fakeaccess)
+	})
+
+	Describe("GET /api/v1/metrics/emitters", func() {
+		JustBeforeEach(func() {
+			var err error
+			response, err = client.Get(server.URL + "/api/v1/metrics/emitters")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when authenticated as an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+				fakeaccess.IsAdminReturns(true)
+			})
+
+			It("returns 200", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+			})
+
+			It("returns the configured emitters", func() {
+				body, err := ioutil.ReadAll(response.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(body).To(MatchJSON(`[
+					{"name": "google_pubsub", "description": "Google Pub/Sub", "enabled": true}
+				]`))
+			})
+		})
+
+		Context("when not an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+			})
+
+			It("returns 403", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		Context("when not authenticated", func() {
+			It("returns 401", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+			})
+		})
+	})
+
+	Describe("PUT /api/v1/metrics/emitters/:emitter_name", func() {
+		var (
+			emitterName string
+			payload     string
+		)
+
+		BeforeEach(func() {
+			emitterName = "google_pubsub"
+			payload = `{"enabled": false}`
+		})
+
+		JustBeforeEach(func() {
+			req, err := http.NewRequest("PUT", server.URL+"/api/v1/metrics/emitters/"+emitterName, bytes.NewBufferString(payload))
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err = client.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when authenticated as an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+				fakeaccess.IsAdminReturns(true)
+			})
+
+			It("pauses the emitter", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+				Expect(metric.EmitterStates()).To(Equal([]metric.EmitterState{
+					{Name: "google_pubsub", Description: "Google Pub/Sub", Enabled: false},
+				}))
+			})
+
+			Context("when the emitter does not exist", func() {
+				BeforeEach(func() {
+					emitterName = "bogus"
+				})
+
+				It("returns 404", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+				})
+			})
+
+			Context("when the payload is malformed", func() {
+				BeforeEach(func() {
+					payload = "bogus"
+				})
+
+				It("returns 400", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+				})
+			})
+		})
+
+		Context("when not an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+			})
+
+			It("returns 403", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+				Expect(metric.EmitterStates()[0].Enabled).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("GET /api/v1/metrics/catalog", func() {
+		JustBeforeEach(func() {
+			var err error
+			response, err = client.Get(server.URL + "/api/v1/metrics/catalog")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when authenticated as an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+				fakeaccess.IsAdminReturns(true)
+			})
+
+			It("returns the registered metrics", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+				Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+
+				var catalog []atc.MetricMetadata
+				Expect(json.NewDecoder(response.Body).Decode(&catalog)).To(Succeed())
+
+				Expect(catalog).To(ContainElement(atc.MetricMetadata{
+					Name: "builds started",
+					Help: "Number of builds started.",
+					Unit: "count",
+					Type: "counter",
+				}))
+			})
+		})
+
+		Context("when not an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+			})
+
+			It("returns 403", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+	})
+
+	Describe("GET /api/v1/metrics", func() {
+		JustBeforeEach(func() {
+			var err error
+			response, err = client.Get(server.URL + "/api/v1/metrics")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when authenticated as an admin", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+				fakeaccess.IsAdminReturns(true)
+			})
+
+			It("returns the metrics in the OpenMetrics text format", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+				Expect(response.Header.Get("Content-Type")).To(Equal(metric.OpenMetricsContentType))
+
+				body, err := ioutil.ReadAll(response.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(body)).To(HaveSuffix("# EOF\n"))
+			})
+		})
+
+		Context("when not an admin", func() {
+			Context("when no scrape token is set", func() {
+				It("returns 401", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+				})
+			})
+
+			Context("when a scrape token is set", func() {
+				var header string
+
+				BeforeEach(func() {
+					metric.OpenMetrics.SetScrapeToken("some-token")
+					header = ""
+				})
+
+				AfterEach(func() {
+					metric.OpenMetrics.SetScrapeToken("")
+				})
+
+				JustBeforeEach(func() {
+					request, err := http.NewRequest("GET", server.URL+"/api/v1/metrics", nil)
+					Expect(err).NotTo(HaveOccurred())
+
+					if header != "" {
+						request.Header.Set("Authorization", header)
+					}
+
+					response, err = client.Do(request)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				Context("when the request presents the token", func() {
+					BeforeEach(func() {
+						header = "Bearer some-token"
+					})
+
+					It("returns the metrics", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusOK))
+					})
+				})
+
+				Context("when the request presents another token", func() {
+					BeforeEach(func() {
+						header = "Bearer other-token"
+					})
+
+					It("returns 401", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+					})
+				})
+
+				Context("when the request presents no token", func() {
+					It("returns 401", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusUnauthorized))
+					})
+				})
+			})
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package emitterserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+func (s *Server) GetCatalog(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.Session("get-metric-catalog")
+
+	catalog := []atc.MetricMetadata{}
+	for _, metadata := range metric.Catalog() {
+		catalog = append(catalog, atc.MetricMetadata{
+			Name: metadata.Name,
+			Help: metadata.Help,
+			Unit: metadata.Unit,
+			Type: string(metadata.Type),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(catalog)
+	if err != nil {
+		logger.Error("failed-to-encode-metric-catalog", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,30 @@
+package emitterserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+func (s *Server) ListEmitters(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.Session("list-emitters")
+
+	emitters := []atc.MetricEmitter{}
+	for _, state := range metric.EmitterStates() {
+		emitters = append(emitters, atc.MetricEmitter{
+			Name:        state.Name,
+			Description: state.Description,
+			Enabled:     state.Enabled,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err := json.NewEncoder(w).Encode(emitters)
+	if err != nil {
+		logger.Error("failed-to-encode-emitters", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
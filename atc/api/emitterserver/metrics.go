@@ -0,0 +1,20 @@
+package emitterserver
+
+import (
+	"net/http"
+
+	"github.com/concourse/concourse/atc/api/accessor"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+// GetMetrics is open to admins, and to scrapers presenting the OpenMetrics
+// scrape token if one is set.
+func (s *Server) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	acc := accessor.GetAccessor(r)
+	if !acc.IsAdmin() && !metric.OpenMetrics.Authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	metric.OpenMetrics.ServeHTTP(w, r)
+}
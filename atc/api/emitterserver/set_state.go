@@ -0,0 +1,44 @@
+package emitterserver
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+func (s *Server) SetEmitterState(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue(":emitter_name")
+
+	logger := s.logger.Session("set-emitter-state", lager.Data{
+		"emitter": name,
+	})
+
+	var request atc.SetMetricEmitterStateRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		logger.Error("failed-to-decode-request", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = metric.SetEmitterEnabled(name, request.Enabled)
+	if err == metric.ErrEmitterNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		logger.Error("failed-to-set-emitter-state", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	logger.Info("set-emitter-state", lager.Data{
+		"enabled": request.Enabled,
+	})
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,69 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Health API", func() {
+	var response *http.Response
+
+	Describe("GET /api/v1/healthz", func() {
+		JustBeforeEach(func() {
+			var err error
+
+			response, err = client.Get(server.URL + "/api/v1/healthz")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns 200", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Describe("GET /api/v1/readyz", func() {
+		JustBeforeEach(func() {
+			var err error
+
+			response, err = client.Get(server.URL + "/api/v1/readyz")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the database can be reached", func() {
+			BeforeEach(func() {
+				dbConn.PingReturns(nil)
+			})
+
+			It("returns 200", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusOK))
+			})
+
+			It("pings the database", func() {
+				Expect(dbConn.PingCallCount()).To(Equal(1))
+			})
+
+			Context("when the ATC is draining", func() {
+				BeforeEach(func() {
+					close(drain)
+				})
+
+				It("returns 503", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+				})
+			})
+		})
+
+		Context("when the database cannot be reached", func() {
+			BeforeEach(func() {
+				dbConn.PingReturns(errors.New("connection refused"))
+			})
+
+			It("returns 503", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+	})
+})
@@ -0,0 +1,32 @@
+package healthserver
+
+import (
+	"net/http"
+)
+
+// Healthz responds as long as the process is able to serve requests at all.
+func (s *Server) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// Readyz responds with 503 unless the database can be reached and the ATC is
+// not draining, so that load balancers stop routing to it before it goes away.
+func (s *Server) Readyz(w http.ResponseWriter, r *http.Request) {
+	logger := s.logger.Session("readyz")
+
+	select {
+	case <-s.drain:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	err := s.conn.Ping()
+	if err != nil {
+		logger.Error("failed-to-ping-database", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
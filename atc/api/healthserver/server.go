@@ -0,0 +1,24 @@
+package healthserver
+
+import (
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/db"
+)
+
+type Server struct {
+	logger lager.Logger
+	conn   db.Conn
+	drain  <-chan struct{}
+}
+
+func NewServer(
+	logger lager.Logger,
+	conn db.Conn,
+	drain <-chan struct{},
+) *Server {
+	return &Server{
+		logger: logger,
+		conn:   conn,
+		drain:  drain,
+	}
+}
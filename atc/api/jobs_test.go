@@ -569,10 +569,19 @@ var _ = Describe("Jobs API", func() {
 				Expect(response.StatusCode).To(Equal(http.StatusOK))
 			})
 
-			It("returns Content-Type as image/svg+xml and disables caching", func() {
+			It("returns Content-Type as image/svg+xml and allows brief private caching", func() {
 				Expect(response.Header.Get("Content-Type")).To(Equal("image/svg+xml"))
-				Expect(response.Header.Get("Cache-Control")).To(Equal("no-cache, no-store, must-revalidate"))
-				Expect(response.Header.Get("Expires")).To(Equal("0"))
+				Expect(response.Header.Get("Cache-Control")).To(Equal("private, max-age=30"))
+			})
+
+			Context("when the pipeline is public", func() {
+				BeforeEach(func() {
+					fakePipeline.PublicReturns(true)
+				})
+
+				It("allows shared caches to store the badge", func() {
+					Expect(response.Header.Get("Cache-Control")).To(Equal("public, max-age=30"))
+				})
 			})
 
 			Context("when the finished build is successful", func() {
@@ -787,6 +796,47 @@ var _ = Describe("Jobs API", func() {
 				})
 			})
 
+			Context("when the job's first build is running", func() {
+				BeforeEach(func() {
+					build := new(dbfakes.FakeBuild)
+					build.StatusReturns(db.BuildStatusStarted)
+
+					fakeJob.FinishedAndNextBuildReturns(nil, build, nil)
+				})
+
+				It("returns a pending badge", func() {
+					body, err := ioutil.ReadAll(response.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(body)).To(ContainSubstring(`<path fill="#dfb317" d="M37 0h55v20H37z" />`))
+					Expect(string(body)).To(ContainSubstring(`<text x="63.5" y="14">pending</text>`))
+				})
+			})
+
+			Context("when a title is given", func() {
+				JustBeforeEach(func() {
+					var err error
+
+					response, err = client.Get(server.URL + "/api/v1/teams/some-team/pipelines/some-pipeline/jobs/some-job/badge?title=unit%20%26%20lint")
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				BeforeEach(func() {
+					build := new(dbfakes.FakeBuild)
+					build.StatusReturns(db.BuildStatusSucceeded)
+
+					fakeJob.FinishedAndNextBuildReturns(build, nil, nil)
+				})
+
+				It("labels the badge with the escaped title and widens it to fit", func() {
+					body, err := ioutil.ReadAll(response.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(body)).To(ContainSubstring(`width="130"`))
+					Expect(string(body)).To(ContainSubstring(`<path fill="#555" d="M0 0h79v20H0z" />`))
+					Expect(string(body)).To(ContainSubstring(`<text x="39.5" y="14">unit &amp; lint</text>`))
+					Expect(string(body)).To(ContainSubstring(`<text x="103.5" y="14">passing</text>`))
+				})
+			})
+
 			Context("when there are no running or finished builds", func() {
 				BeforeEach(func() {
 					fakeJob.FinishedAndNextBuildReturns(nil, nil, nil)
@@ -1539,6 +1589,242 @@ var _ = Describe("Jobs API", func() {
 		})
 	})
 
+	Describe("POST /api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds/:build_name/rerun", func() {
+		var request *http.Request
+		var response *http.Response
+
+		BeforeEach(func() {
+			var err error
+
+			request, err = http.NewRequest("POST", server.URL+"/api/v1/teams/some-team/pipelines/some-pipeline/jobs/some-job/builds/1/rerun", nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			var err error
+
+			response, err = client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when not authorized", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthenticatedReturns(true)
+				fakeaccess.IsAuthorizedReturns(false)
+			})
+
+			It("returns 403", func() {
+				Expect(response.StatusCode).To(Equal(http.StatusForbidden))
+			})
+		})
+
+		Context("when authorized and authenticated", func() {
+			BeforeEach(func() {
+				fakeaccess.IsAuthorizedReturns(true)
+				fakeaccess.IsAuthenticatedReturns(true)
+			})
+
+			Context("when getting the job fails", func() {
+				BeforeEach(func() {
+					fakePipeline.JobReturns(nil, false, errors.New("errorrr"))
+				})
+
+				It("returns a 500", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+				})
+			})
+
+			Context("when the job is not found", func() {
+				BeforeEach(func() {
+					fakePipeline.JobReturns(nil, false, nil)
+				})
+
+				It("returns a 404", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+				})
+			})
+
+			Context("when getting the job succeeds", func() {
+				BeforeEach(func() {
+					fakeJob.NameReturns("some-job")
+					fakePipeline.JobReturns(fakeJob, true, nil)
+				})
+
+				Context("when manual triggering is disabled", func() {
+					BeforeEach(func() {
+						fakeJob.ConfigReturns(atc.JobConfig{
+							Name:                 "some-job",
+							DisableManualTrigger: true,
+						})
+					})
+
+					It("should return 409", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusConflict))
+					})
+
+					It("does not rerun the build", func() {
+						Expect(fakeJob.RerunBuildCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("when getting the build fails", func() {
+					BeforeEach(func() {
+						fakeJob.BuildReturns(nil, false, errors.New("nopers"))
+					})
+
+					It("returns a 500", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+					})
+				})
+
+				Context("when the build is not found", func() {
+					BeforeEach(func() {
+						fakeJob.BuildReturns(nil, false, nil)
+					})
+
+					It("returns a 404", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+					})
+
+					It("does not rerun the build", func() {
+						Expect(fakeJob.RerunBuildCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("when the build is found", func() {
+					var buildToRerun *dbfakes.FakeBuild
+
+					BeforeEach(func() {
+						buildToRerun = new(dbfakes.FakeBuild)
+						buildToRerun.IDReturns(41)
+						buildToRerun.NameReturns("1")
+						buildToRerun.IsScheduledReturns(true)
+						buildToRerun.InputsReturns([]db.BuildInput{{Name: "some-input"}}, nil)
+
+						fakeJob.BuildReturns(buildToRerun, true, nil)
+					})
+
+					Context("when the build was never scheduled", func() {
+						BeforeEach(func() {
+							buildToRerun.IsScheduledReturns(false)
+						})
+
+						It("returns 409", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusConflict))
+						})
+
+						It("does not rerun the build", func() {
+							Expect(fakeJob.RerunBuildCallCount()).To(Equal(0))
+						})
+					})
+
+					Context("when getting the inputs of the build fails", func() {
+						BeforeEach(func() {
+							buildToRerun.InputsReturns(nil, errors.New("nopers"))
+						})
+
+						It("returns a 500", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+						})
+					})
+
+					Context("when the build has no recorded inputs", func() {
+						BeforeEach(func() {
+							buildToRerun.InputsReturns([]db.BuildInput{}, nil)
+						})
+
+						Context("when the job has inputs", func() {
+							BeforeEach(func() {
+								fakeJob.ConfigReturns(atc.JobConfig{
+									Name: "some-job",
+									Plan: atc.PlanSequence{{Get: "some-input"}},
+								})
+							})
+
+							It("returns 409", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusConflict))
+							})
+
+							It("does not rerun the build", func() {
+								Expect(fakeJob.RerunBuildCallCount()).To(Equal(0))
+							})
+						})
+
+						Context("when the job has no inputs", func() {
+							BeforeEach(func() {
+								fakeJob.ConfigReturns(atc.JobConfig{
+									Name: "some-job",
+									Plan: atc.PlanSequence{{Task: "some-task"}},
+								})
+								fakeJob.RerunBuildReturns(new(dbfakes.FakeBuild), nil)
+							})
+
+							It("reruns the build", func() {
+								Expect(fakeJob.RerunBuildCallCount()).To(Equal(1))
+							})
+						})
+					})
+
+					Context("when rerunning the build fails", func() {
+						BeforeEach(func() {
+							fakeJob.RerunBuildReturns(nil, errors.New("nopers"))
+						})
+
+						It("returns a 500", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+						})
+					})
+
+					Context("when rerunning the build succeeds", func() {
+						BeforeEach(func() {
+							build := new(dbfakes.FakeBuild)
+							build.IDReturns(42)
+							build.NameReturns("2")
+							build.JobNameReturns("some-job")
+							build.PipelineNameReturns("a-pipeline")
+							build.TeamNameReturns("some-team")
+							build.StatusReturns(db.BuildStatusPending)
+							build.RerunOfReturns(41)
+
+							fakeJob.RerunBuildReturns(build, nil)
+						})
+
+						It("reruns the build", func() {
+							Expect(fakeJob.BuildArgsForCall(0)).To(Equal("1"))
+
+							Expect(fakeJob.RerunBuildCallCount()).To(Equal(1))
+							Expect(fakeJob.RerunBuildArgsForCall(0)).To(Equal(buildToRerun))
+						})
+
+						It("returns 200 OK", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusOK))
+						})
+
+						It("returns Content-Type 'application/json'", func() {
+							Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+						})
+
+						It("returns the new build", func() {
+							body, err := ioutil.ReadAll(response.Body)
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(body).To(MatchJSON(`{
+							"id": 42,
+							"name": "2",
+							"job_name": "some-job",
+							"status": "pending",
+							"api_url": "/api/v1/builds/42",
+							"pipeline_name": "a-pipeline",
+							"team_name": "some-team",
+							"rerun_of": 41
+						}`))
+						})
+					})
+				})
+			})
+		})
+	})
+
 	Describe("GET /api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/inputs", func() {
 		var response *http.Response
 
@@ -3,32 +3,69 @@ package jobserver
 import (
 	"bytes"
 	"fmt"
+	"html"
 	"net/http"
 	"text/template"
+	"time"
+	"unicode/utf8"
 
 	"github.com/concourse/concourse/atc/db"
 )
 
 var (
-	badgePassing = Badge{width: 88, fillColor: `#44cc11`, status: `passing`}
-	badgeFailing = Badge{width: 80, fillColor: `#e05d44`, status: `failing`}
-	badgeUnknown = Badge{width: 98, fillColor: `#9f9f9f`, status: `unknown`}
-	badgeAborted = Badge{width: 90, fillColor: `#8f4b2d`, status: `aborted`}
-	badgeErrored = Badge{width: 88, fillColor: `#fe7d37`, status: `errored`}
+	badgePassing = Badge{statusWidth: 51, fillColor: `#44cc11`, status: `passing`}
+	badgeFailing = Badge{statusWidth: 43, fillColor: `#e05d44`, status: `failing`}
+	badgePending = Badge{statusWidth: 55, fillColor: `#dfb317`, status: `pending`}
+	badgeUnknown = Badge{statusWidth: 61, fillColor: `#9f9f9f`, status: `unknown`}
+	badgeAborted = Badge{statusWidth: 53, fillColor: `#8f4b2d`, status: `aborted`}
+	badgeErrored = Badge{statusWidth: 51, fillColor: `#fe7d37`, status: `errored`}
 )
 
+const defaultBadgeTitle = "build"
+
+// badgeMaxAge is how long clients may cache a job's badge; long enough that
+// READMEs embedding it don't hit the API on every view, short enough that it
+// catches up with new builds promptly.
+const badgeMaxAge = 30 * time.Second
+
 type Badge struct {
-	width     int
-	fillColor string
-	status    string
+	title       string
+	statusWidth int
+	fillColor   string
+	status      string
 }
 
-func (b *Badge) statusWidth() int {
-	return b.width - 37
+// WithTitle returns a copy of the badge labelled with the given title rather
+// than "build".
+func (b Badge) WithTitle(title string) *Badge {
+	b.title = title
+	return &b
+}
+
+func (b *Badge) titleOrDefault() string {
+	if b.title == "" {
+		return defaultBadgeTitle
+	}
+
+	return b.title
+}
+
+// titleWidth approximates the rendered width of the title, which is in an
+// 11px sans-serif font.
+func (b *Badge) titleWidth() int {
+	return utf8.RuneCountInString(b.titleOrDefault())*7 + 2
+}
+
+func (b *Badge) width() int {
+	return b.titleWidth() + b.statusWidth
+}
+
+func (b *Badge) titleTextWidth() string {
+	return fmt.Sprintf("%.1f", float64(b.titleWidth())/2)
 }
 
 func (b *Badge) statusTextWidth() string {
-	return fmt.Sprintf("%.1f", float64(b.width)/2+17.5)
+	return fmt.Sprintf("%.1f", float64(b.titleWidth())+float64(b.statusWidth)/2-1)
 }
 
 func (b *Badge) String() string {
@@ -40,10 +77,13 @@ func (b *Badge) String() string {
 	buffer := &bytes.Buffer{}
 
 	_ = tmpl.Execute(buffer, badgeTemplateConfig{
-		Width:           b.width,
+		Width:           b.width(),
 		FillColor:       b.fillColor,
+		Title:           html.EscapeString(b.titleOrDefault()),
+		TitleWidth:      b.titleWidth(),
+		TitleTextWidth:  b.titleTextWidth(),
 		Status:          b.status,
-		StatusWidth:     b.statusWidth(),
+		StatusWidth:     b.statusWidth,
 		StatusTextWidth: b.statusTextWidth(),
 	})
 
@@ -62,6 +102,8 @@ func BadgeForBuild(build db.Build) *Badge {
 		return &badgeAborted
 	case build.Status() == db.BuildStatusErrored:
 		return &badgeErrored
+	case build.Status() == db.BuildStatusPending, build.Status() == db.BuildStatusStarted:
+		return &badgePending
 	default:
 		return &badgeUnknown
 	}
@@ -77,13 +119,13 @@ const badgeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
       <rect width="{{ .Width }}" height="20" rx="3" fill="#fff" />
    </mask>
    <g mask="url(#a)">
-      <path fill="#555" d="M0 0h37v20H0z" />
-      <path fill="{{ .FillColor }}" d="M37 0h{{ .StatusWidth }}v20H37z" />
+      <path fill="#555" d="M0 0h{{ .TitleWidth }}v20H0z" />
+      <path fill="{{ .FillColor }}" d="M{{ .TitleWidth }} 0h{{ .StatusWidth }}v20H{{ .TitleWidth }}z" />
       <path fill="url(#b)" d="M0 0h{{ .Width }}v20H0z" />
    </g>
    <g fill="#fff" text-anchor="middle" font-family="DejaVu Sans,Verdana,Geneva,sans-serif" font-size="11">
-      <text x="18.5" y="15" fill="#010101" fill-opacity=".3">build</text>
-      <text x="18.5" y="14">build</text>
+      <text x="{{ .TitleTextWidth }}" y="15" fill="#010101" fill-opacity=".3">{{ .Title }}</text>
+      <text x="{{ .TitleTextWidth }}" y="14">{{ .Title }}</text>
       <text x="{{ .StatusTextWidth }}" y="15" fill="#010101" fill-opacity=".3">{{ .Status }}</text>
       <text x="{{ .StatusTextWidth }}" y="14">{{ .Status }}</text>
    </g>
@@ -91,6 +133,9 @@ const badgeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
 
 type badgeTemplateConfig struct {
 	Width           int
+	Title           string
+	TitleWidth      int
+	TitleTextWidth  string
 	StatusWidth     int
 	StatusTextWidth string
 	Status          string
@@ -114,20 +159,29 @@ func (s *Server) JobBadge(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
-		build, _, err := job.FinishedAndNextBuild()
+		build, nextBuild, err := job.FinishedAndNextBuild()
 		if err != nil {
 			logger.Error("could-not-get-job-finished-and-next-build", err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
-		w.Header().Set("Content-type", "image/svg+xml")
+		// a job whose first build is still running is pending rather than
+		// unknown
+		if build == nil {
+			build = nextBuild
+		}
+
+		cacheScope := "private"
+		if pipeline.Public() {
+			cacheScope = "public"
+		}
 
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		w.Header().Set("Expires", "0")
+		w.Header().Set("Content-type", "image/svg+xml")
+		w.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheScope, int(badgeMaxAge.Seconds())))
 
 		w.WriteHeader(http.StatusOK)
 
-		fmt.Fprint(w, BadgeForBuild(build))
+		fmt.Fprint(w, BadgeForBuild(build).WithTitle(r.FormValue("title")))
 	})
 }
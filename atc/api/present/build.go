@@ -26,6 +26,7 @@ func Build(build db.Build) atc.Build {
 		TeamName:     build.TeamName(),
 		Status:       string(build.Status()),
 		APIURL:       apiURL,
+		RerunOf:      build.RerunOf(),
 	}
 
 	if !build.StartTime().IsZero() {
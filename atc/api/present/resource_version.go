@@ -1,10 +1,12 @@
 package present
 
 import (
+	"reflect"
+
 	"github.com/concourse/concourse/atc"
 )
 
-func ResourceVersions(hideMetadata bool, resourceVersions []atc.ResourceVersion) []atc.ResourceVersion {
+func ResourceVersions(hideMetadata bool, pinnedVersion atc.Version, resourceVersions []atc.ResourceVersion) []atc.ResourceVersion {
 	var presented []atc.ResourceVersion
 
 	for _, resourceVersion := range resourceVersions {
@@ -12,6 +14,10 @@ func ResourceVersions(hideMetadata bool, resourceVersions []atc.ResourceVersion)
 			resourceVersion.Metadata = nil
 		}
 
+		if pinnedVersion != nil && reflect.DeepEqual(resourceVersion.Version, pinnedVersion) {
+			resourceVersion.Pinned = true
+		}
+
 		presented = append(presented, resourceVersion)
 	}
 
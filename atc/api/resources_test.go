@@ -19,6 +19,7 @@ import (
 	"github.com/concourse/concourse/atc/creds"
 	"github.com/concourse/concourse/atc/db"
 	"github.com/concourse/concourse/atc/db/dbfakes"
+	"github.com/concourse/concourse/atc/radar"
 	"github.com/concourse/concourse/atc/radar/radarfakes"
 	"github.com/concourse/concourse/atc/resource"
 )
@@ -315,6 +316,56 @@ var _ = Describe("Resources API", func() {
 					]`))
 				})
 
+				Context("when the resources have versions", func() {
+					BeforeEach(func() {
+						fakePipeline.LatestResourceVersionsReturns(map[string]atc.Version{
+							"resource-1": {"ref": "abc"},
+						}, nil)
+					})
+
+					It("returns the latest version of each resource which has one", func() {
+						body, err := ioutil.ReadAll(response.Body)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(body).To(MatchJSON(`[
+							{
+								"name": "resource-1",
+								"pipeline_name": "a-pipeline",
+								"team_name": "a-team",
+								"type": "type-1",
+								"last_checked": 1513364881,
+								"latest_version": {"ref": "abc"}
+							},
+							{
+								"name": "resource-2",
+								"pipeline_name": "a-pipeline",
+								"team_name": "a-team",
+								"type": "type-2",
+								"failing_to_check": true,
+								"check_error": "sup"
+							},
+							{
+								"name": "resource-3",
+								"pipeline_name": "a-pipeline",
+								"team_name": "a-team",
+								"type": "type-3",
+								"check_setup_error": "sup",
+								"failing_to_check": true
+							}
+						]`))
+					})
+				})
+
+				Context("when getting the latest resource versions fails", func() {
+					BeforeEach(func() {
+						fakePipeline.LatestResourceVersionsReturns(nil, errors.New("oh no!"))
+					})
+
+					It("returns 500", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+					})
+				})
+
 				Context("when getting the resource config fails", func() {
 					Context("when the resources are not found", func() {
 						BeforeEach(func() {
@@ -373,6 +424,8 @@ var _ = Describe("Resources API", func() {
 					BeforeEach(func() {
 						fakeResource = new(dbfakes.FakeResource)
 						fakeResource.IDReturns(1)
+						fakeResource.NameReturns("resource-name")
+						fakeResource.ReloadReturns(true, nil)
 						fakePipeline.ResourceReturns(fakeResource, true, nil)
 					})
 
@@ -384,6 +437,27 @@ var _ = Describe("Resources API", func() {
 						It("returns 200", func() {
 							Expect(response.StatusCode).To(Equal(http.StatusOK))
 						})
+
+						It("returns the updated resource", func() {
+							Expect(fakeResource.ReloadCallCount()).To(Equal(1))
+
+							var resource atc.Resource
+							err := json.NewDecoder(response.Body).Decode(&resource)
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(resource.Name).To(Equal("resource-name"))
+							Expect(resource.PinnedVersion).To(BeNil())
+						})
+
+						Context("when reloading the resource fails", func() {
+							BeforeEach(func() {
+								fakeResource.ReloadReturns(false, errors.New("welp"))
+							})
+
+							It("returns 500", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+							})
+						})
 					})
 
 					Context("when unpinning the resource fails", func() {
@@ -587,8 +661,8 @@ var _ = Describe("Resources API", func() {
 			})
 
 			It("tries to scan with no version specified", func() {
-				Expect(fakeScanner.ScanFromVersionCallCount()).To(Equal(1))
-				_, actualResourceName, actualFromVersion := fakeScanner.ScanFromVersionArgsForCall(0)
+				Expect(fakeScanner.TryScanFromVersionCallCount()).To(Equal(1))
+				_, actualResourceName, actualFromVersion := fakeScanner.TryScanFromVersionArgsForCall(0)
 				Expect(actualResourceName).To(Equal("resource-name"))
 				Expect(actualFromVersion).To(BeNil())
 			})
@@ -607,8 +681,8 @@ var _ = Describe("Resources API", func() {
 				})
 
 				It("tries to scan with the version specified", func() {
-					Expect(fakeScanner.ScanFromVersionCallCount()).To(Equal(1))
-					_, actualResourceName, actualFromVersion := fakeScanner.ScanFromVersionArgsForCall(0)
+					Expect(fakeScanner.TryScanFromVersionCallCount()).To(Equal(1))
+					_, actualResourceName, actualFromVersion := fakeScanner.TryScanFromVersionArgsForCall(0)
 					Expect(actualResourceName).To(Equal("resource-name"))
 					Expect(actualFromVersion).To(Equal(checkRequestBody.From))
 				})
@@ -616,7 +690,7 @@ var _ = Describe("Resources API", func() {
 
 			Context("when checking fails with ResourceNotFoundError", func() {
 				BeforeEach(func() {
-					fakeScanner.ScanFromVersionReturns(db.ResourceNotFoundError{})
+					fakeScanner.TryScanFromVersionReturns(db.ResourceNotFoundError{})
 				})
 
 				It("returns 404", func() {
@@ -626,7 +700,7 @@ var _ = Describe("Resources API", func() {
 
 			Context("when checking the resource fails with ResourceTypeNotFoundError", func() {
 				BeforeEach(func() {
-					fakeScanner.ScanFromVersionReturns(db.ResourceTypeNotFoundError{Name: "missing-type"})
+					fakeScanner.TryScanFromVersionReturns(db.ResourceTypeNotFoundError{Name: "missing-type"})
 				})
 
 				It("returns jsonapi 400", func() {
@@ -635,9 +709,19 @@ var _ = Describe("Resources API", func() {
 				})
 			})
 
+			Context("when a check of the resource is already in progress", func() {
+				BeforeEach(func() {
+					fakeScanner.TryScanFromVersionReturns(radar.ErrCheckInProgress)
+				})
+
+				It("returns 409", func() {
+					Expect(response.StatusCode).To(Equal(http.StatusConflict))
+				})
+			})
+
 			Context("when checking the resource fails internally", func() {
 				BeforeEach(func() {
-					fakeScanner.ScanFromVersionReturns(errors.New("welp"))
+					fakeScanner.TryScanFromVersionReturns(errors.New("welp"))
 				})
 
 				It("returns 500", func() {
@@ -652,7 +736,7 @@ var _ = Describe("Resources API", func() {
 
 			Context("when checking the resource fails with ErrResourceScriptFailed", func() {
 				BeforeEach(func() {
-					fakeScanner.ScanFromVersionReturns(
+					fakeScanner.TryScanFromVersionReturns(
 						resource.ErrResourceScriptFailed{
 							ExitStatus: 42,
 							Stderr:     "my tooth",
@@ -1093,6 +1177,52 @@ var _ = Describe("Resources API", func() {
 							}`))
 					})
 				})
+
+				Context("when the resource has versions", func() {
+					BeforeEach(func() {
+						resourceName = "resource-1"
+
+						resource1 := new(dbfakes.FakeResource)
+						resource1.PipelineNameReturns("a-pipeline")
+						resource1.NameReturns("resource-1")
+						resource1.TypeReturns("type-1")
+						resource1.LastCheckedReturns(time.Unix(1513364881, 0))
+						fakePipeline.ResourceReturns(resource1, true, nil)
+
+						fakePipeline.LatestResourceVersionsReturns(map[string]atc.Version{
+							"resource-1": {"ref": "abc"},
+						}, nil)
+					})
+
+					It("returns the latest version in the response json", func() {
+						body, err := ioutil.ReadAll(response.Body)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(body).To(MatchJSON(`
+							{
+								"name": "resource-1",
+								"pipeline_name": "a-pipeline",
+								"team_name": "a-team",
+								"type": "type-1",
+								"last_checked": 1513364881,
+								"latest_version": {"ref": "abc"}
+							}`))
+					})
+				})
+
+				Context("when getting the latest resource versions fails", func() {
+					BeforeEach(func() {
+						resource1 := new(dbfakes.FakeResource)
+						resource1.NameReturns("resource-1")
+						fakePipeline.ResourceReturns(resource1, true, nil)
+
+						fakePipeline.LatestResourceVersionsReturns(nil, errors.New("oh no!"))
+					})
+
+					It("returns 500", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+					})
+				})
 			})
 		})
 
@@ -7,6 +7,7 @@ import (
 	"code.cloudfoundry.org/lager"
 	"github.com/concourse/concourse/atc"
 	"github.com/concourse/concourse/atc/db"
+	"github.com/concourse/concourse/atc/radar"
 	"github.com/concourse/concourse/atc/resource"
 	"github.com/google/jsonapi"
 	"github.com/tedsuo/rata"
@@ -28,7 +29,12 @@ func (s *Server) CheckResource(dbPipeline db.Pipeline) http.Handler {
 
 		scanner := s.scannerFactory.NewResourceScanner(dbPipeline)
 
-		err = scanner.ScanFromVersion(logger, resourceName, reqBody.From)
+		err = scanner.TryScanFromVersion(logger, resourceName, reqBody.From)
+		if err == radar.ErrCheckInProgress {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
 		switch scanErr := err.(type) {
 		case resource.ErrResourceScriptFailed:
 			checkResponseBody := atc.CheckResponseBody{
@@ -29,6 +29,13 @@ func (s *Server) GetResource(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
+		latestVersions, err := pipeline.LatestResourceVersions()
+		if err != nil {
+			logger.Error("failed-to-get-latest-resource-versions", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
 		acc := accessor.GetAccessor(r)
 		resource := present.Resource(
 			dbResource,
@@ -36,6 +43,8 @@ func (s *Server) GetResource(pipeline db.Pipeline) http.Handler {
 			teamName,
 		)
 
+		resource.LatestVersion = latestVersions[resourceName]
+
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
@@ -20,20 +20,28 @@ func (s *Server) ListResources(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
+		latestVersions, err := pipeline.LatestResourceVersions()
+		if err != nil {
+			logger.Error("failed-to-get-latest-resource-versions", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
 		acc := accessor.GetAccessor(r)
 		showCheckErr := acc.IsAuthenticated()
 		teamName := r.FormValue(":team_name")
 
 		var presentedResources []atc.Resource
 		for _, resource := range resources {
-			presentedResources = append(
-				presentedResources,
-				present.Resource(
-					resource,
-					showCheckErr,
-					teamName,
-				),
+			presentedResource := present.Resource(
+				resource,
+				showCheckErr,
+				teamName,
 			)
+
+			presentedResource.LatestVersion = latestVersions[resource.Name()]
+
+			presentedResources = append(presentedResources, presentedResource)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
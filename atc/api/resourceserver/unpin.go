@@ -1,9 +1,12 @@
 package resourceserver
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/api/accessor"
+	"github.com/concourse/concourse/atc/api/present"
 	"github.com/concourse/concourse/atc/db"
 )
 
@@ -11,6 +14,8 @@ func (s *Server) UnpinResource(pipeline db.Pipeline) http.Handler {
 	logger := s.logger.Session("unpin-resource-version")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resourceName := r.FormValue(":resource_name")
+		teamName := r.FormValue(":team_name")
+
 		resource, found, err := pipeline.Resource(resourceName)
 		if err != nil {
 			logger.Error("failed-to-get-resource", err)
@@ -30,6 +35,26 @@ func (s *Server) UnpinResource(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
+		found, err = resource.Reload()
+		if err != nil {
+			logger.Error("failed-to-reload-resource", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		acc := accessor.GetAccessor(r)
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(present.Resource(resource, acc.IsAuthorized(teamName), teamName))
+		if err != nil {
+			logger.Error("failed-to-encode-resource", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 	})
 }
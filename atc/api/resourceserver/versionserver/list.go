@@ -93,7 +93,7 @@ func (s *Server) ListResourceVersions(pipeline db.Pipeline) http.Handler {
 		acc := accessor.GetAccessor(r)
 		hideMetadata := !resource.Public() && !acc.IsAuthorized(teamName)
 
-		versions = present.ResourceVersions(hideMetadata, versions)
+		versions = present.ResourceVersions(hideMetadata, resource.CurrentPinnedVersion(), versions)
 
 		err = json.NewEncoder(w).Encode(versions)
 		if err != nil {
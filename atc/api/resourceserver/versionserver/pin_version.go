@@ -1,10 +1,13 @@
 package versionserver
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/api/accessor"
+	"github.com/concourse/concourse/atc/api/present"
 	"github.com/concourse/concourse/atc/db"
 )
 
@@ -12,6 +15,8 @@ func (s *Server) PinResourceVersion(pipeline db.Pipeline) http.Handler {
 	logger := s.logger.Session("pin-resource-version")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		resourceName := r.FormValue(":resource_name")
+		teamName := r.FormValue(":team_name")
+
 		resource, found, err := pipeline.Resource(resourceName)
 		if err != nil {
 			logger.Error("failed-to-get-resource", err)
@@ -30,6 +35,23 @@ func (s *Server) PinResourceVersion(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
+		enabled, found, err := resource.VersionEnabled(resourceConfigVersionID)
+		if err != nil {
+			logger.Error("failed-to-get-resource-version", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if !enabled {
+			logger.Debug("resource-version-disabled", lager.Data{"resource": resourceName, "version-id": resourceConfigVersionID})
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
 		err = resource.PinVersion(resourceConfigVersionID)
 		if err != nil {
 			logger.Error("failed-to-pin-resource-version", err)
@@ -37,6 +59,26 @@ func (s *Server) PinResourceVersion(pipeline db.Pipeline) http.Handler {
 			return
 		}
 
+		found, err = resource.Reload()
+		if err != nil {
+			logger.Error("failed-to-reload-resource", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		acc := accessor.GetAccessor(r)
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+
+		err = json.NewEncoder(w).Encode(present.Resource(resource, acc.IsAuthorized(teamName), teamName))
+		if err != nil {
+			logger.Error("failed-to-encode-resource", err)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
 	})
 }
@@ -163,6 +163,12 @@ var _ = Describe("Teams API", func() {
 			It("returns 500 Internal Server Error", func() {
 				Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
 			})
+
+			It("does not return a list of teams", func() {
+				body, err := ioutil.ReadAll(response.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(body).To(BeEmpty())
+			})
 		})
 	})
 
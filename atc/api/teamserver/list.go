@@ -2,7 +2,6 @@ package teamserver
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/concourse/concourse/atc"
@@ -15,8 +14,9 @@ func (s *Server) ListTeams(w http.ResponseWriter, r *http.Request) {
 
 	teams, err := s.teamFactory.GetTeams()
 	if err != nil {
-		hLog.Error("failed-to-get-teams", errors.New("sorry"))
+		hLog.Error("failed-to-get-teams", err)
 		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
 	acc := accessor.GetAccessor(r)
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -335,6 +336,23 @@ var _ = Describe("Versions API", func() {
 				]`))
 					})
 
+					Context("when one of the versions is pinned", func() {
+						BeforeEach(func() {
+							returnedVersions[1].Version = atc.Version{"some": "pinned-version"}
+							fakeResource.CurrentPinnedVersionReturns(atc.Version{"some": "pinned-version"})
+						})
+
+						It("marks the version as pinned", func() {
+							var versions []atc.ResourceVersion
+							err := json.NewDecoder(response.Body).Decode(&versions)
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(versions).To(HaveLen(2))
+							Expect(versions[0].Pinned).To(BeFalse())
+							Expect(versions[1].Pinned).To(BeTrue())
+						})
+					})
+
 					Context("when next/previous pages are available", func() {
 						BeforeEach(func() {
 							fakePipeline.NameReturns("some-pipeline")
@@ -636,7 +654,17 @@ var _ = Describe("Versions API", func() {
 					BeforeEach(func() {
 						fakeResource = new(dbfakes.FakeResource)
 						fakeResource.IDReturns(1)
+						fakeResource.NameReturns("resource-name")
+						fakeResource.PipelineNameReturns("a-pipeline")
+						fakeResource.TypeReturns("git")
+						fakeResource.APIPinnedVersionReturns(atc.Version{"ref": "some-ref"})
+						fakeResource.ReloadReturns(true, nil)
 						fakePipeline.ResourceReturns(fakeResource, true, nil)
+						fakeResource.VersionEnabledReturns(true, true, nil)
+					})
+
+					It("looks up the resource config version for the resource", func() {
+						Expect(fakeResource.VersionEnabledArgsForCall(0)).To(Equal(42))
 					})
 
 					It("tries to pin the right resource config version", func() {
@@ -652,6 +680,41 @@ var _ = Describe("Versions API", func() {
 						It("returns 200", func() {
 							Expect(response.StatusCode).To(Equal(http.StatusOK))
 						})
+
+						It("returns Content-Type 'application/json'", func() {
+							Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+						})
+
+						It("returns the updated resource", func() {
+							Expect(fakeResource.ReloadCallCount()).To(Equal(1))
+
+							var resource atc.Resource
+							err := json.NewDecoder(response.Body).Decode(&resource)
+							Expect(err).NotTo(HaveOccurred())
+
+							Expect(resource.Name).To(Equal("resource-name"))
+							Expect(resource.PinnedVersion).To(Equal(atc.Version{"ref": "some-ref"}))
+						})
+
+						Context("when reloading the resource fails", func() {
+							BeforeEach(func() {
+								fakeResource.ReloadReturns(false, errors.New("welp"))
+							})
+
+							It("returns 500", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+							})
+						})
+
+						Context("when the resource is gone after pinning", func() {
+							BeforeEach(func() {
+								fakeResource.ReloadReturns(false, nil)
+							})
+
+							It("returns 404", func() {
+								Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+							})
+						})
 					})
 
 					Context("when pinning the resource fails", func() {
@@ -663,6 +726,40 @@ var _ = Describe("Versions API", func() {
 							Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
 						})
 					})
+
+					Context("when the version is disabled", func() {
+						BeforeEach(func() {
+							fakeResource.VersionEnabledReturns(false, true, nil)
+						})
+
+						It("returns 409", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusConflict))
+						})
+
+						It("does not pin the version", func() {
+							Expect(fakeResource.PinVersionCallCount()).To(Equal(0))
+						})
+					})
+
+					Context("when the version is not found", func() {
+						BeforeEach(func() {
+							fakeResource.VersionEnabledReturns(false, false, nil)
+						})
+
+						It("returns 404", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+						})
+					})
+
+					Context("when looking up the version fails", func() {
+						BeforeEach(func() {
+							fakeResource.VersionEnabledReturns(false, false, errors.New("welp"))
+						})
+
+						It("returns 500", func() {
+							Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+						})
+					})
 				})
 
 				Context("when it fails to find the resource", func() {
@@ -11,7 +11,9 @@ import (
 	_ "net/http/pprof"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -56,6 +58,7 @@ import (
 	"github.com/tedsuo/ifrit/grouper"
 	"github.com/tedsuo/ifrit/http_server"
 	"github.com/tedsuo/ifrit/sigmon"
+	"github.com/vito/twentythousandtonnesofcrudeoil"
 
 	// dynamically registered metric emitters
 	_ "github.com/concourse/concourse/atc/metric/emitter"
@@ -101,6 +104,8 @@ type RunCommand struct {
 
 	InterceptIdleTimeout time.Duration `long:"intercept-idle-timeout" default:"0m" description:"Length of time for a intercepted session to be idle before terminating."`
 
+	MaxSpooledArtifactSize int64 `long:"max-spooled-artifact-size" default:"1073741824" description:"Size in bytes up to which build artifacts are spooled to disk to serve range requests for them. Larger artifacts are always downloaded as a whole."`
+
 	EnableGlobalResources bool `long:"enable-global-resources" description:"Enable equivalent resources across pipelines and teams to share a single version history."`
 
 	GlobalResourceCheckTimeout   time.Duration `long:"global-resource-check-timeout" default:"1h" description:"Time limit on checking for new versions of resources."`
@@ -126,6 +131,9 @@ type RunCommand struct {
 		HostName            string            `long:"metrics-host-name" description:"Host string to attach to emitted metrics."`
 		Attributes          map[string]string `long:"metrics-attribute" description:"A key-value attribute to attach to emitted metrics. Can be specified multiple times." value-name:"NAME:VALUE"`
 		CaptureErrorMetrics bool              `long:"capture-error-metrics" description:"Enable capturing of error log metrics"`
+		ConfigFile          flag.File         `long:"metrics-config" description:"YAML file to configure metrics and emitters with. Flags override it. Re-read on SIGHUP."`
+
+		metric.Config
 	} `group:"Metrics & Diagnostics"`
 
 	Server struct {
@@ -353,6 +361,11 @@ func (cmd *RunCommand) Runner(positionalArguments []string) (ifrit.Runner, error
 		retryingDriverName,
 	)
 
+	err = cmd.loadMetricsConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	// Register the sink that collects error metrics
 	if cmd.Metrics.CaptureErrorMetrics {
 		errorSinkCollector := metric.NewErrorSinkCollector(logger)
@@ -404,6 +417,15 @@ func (cmd *RunCommand) Runner(positionalArguments []string) (ifrit.Runner, error
 		),
 	})
 
+	members = append(members, grouper.Member{
+		Name: "metrics-reloader",
+		Runner: metric.ReloadOnSignal(
+			logger.Session("metrics-reloader"),
+			cmd.reloadMetrics,
+			syscall.SIGHUP,
+		),
+	})
+
 	onReady := func() {
 		logData := lager.Data{
 			"http":  cmd.nonTLSBindAddr(),
@@ -418,6 +440,8 @@ func (cmd *RunCommand) Runner(positionalArguments []string) (ifrit.Runner, error
 	}
 
 	onExit := func() {
+		metric.Deinitialize(logger.Session("metrics"))
+
 		for _, closer := range []Closer{lockConn, apiConn, backendConn, storage} {
 			closer.Close()
 		}
@@ -572,6 +596,7 @@ func (cmd *RunCommand) constructAPIMembers(
 		gcContainerDestroyer,
 		dbBuildFactory,
 		dbResourceConfigFactory,
+		dbConn,
 		workerClient,
 		drain,
 		radarScannerFactory,
@@ -1107,7 +1132,69 @@ func (cmd *RunCommand) configureMetrics(logger lager.Logger) error {
 		host, _ = os.Hostname()
 	}
 
-	return metric.Initialize(logger.Session("metrics"), host, cmd.Metrics.Attributes)
+	return metric.Initialize(logger.Session("metrics"), host, cmd.Metrics.Attributes, cmd.Metrics.Config)
+}
+
+// loadMetricsConfig parses the metrics flags again with the metrics config
+// file as their defaults, if there is one.
+func (cmd *RunCommand) loadMetricsConfig() error {
+	if cmd.Metrics.ConfigFile == "" {
+		return nil
+	}
+
+	return parseMetricsFlags(&cmd.Metrics, cmd.Metrics.ConfigFile.Path(), metric.WireEmitters)
+}
+
+// reloadMetrics parses the metrics flags from the command line, the
+// environment and the metrics config file again and rebuilds the emitters
+// from them. The flags are parsed into fresh values, so that emitters are not
+// reconfigured while in use.
+func (cmd *RunCommand) reloadMetrics(logger lager.Logger) error {
+	metrics := cmd.Metrics
+	reflect.ValueOf(&metrics).Elem().Set(reflect.Zero(reflect.TypeOf(metrics)))
+
+	factories := metric.NewEmitterFactories()
+
+	err := parseMetricsFlags(&metrics, cmd.Metrics.ConfigFile.Path(), func(group *flags.Group) {
+		metric.WireEmitterFactories(group, factories)
+	})
+	if err != nil {
+		return err
+	}
+
+	host := metrics.HostName
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	return metric.Reload(logger, host, metrics.Attributes, metrics.Config, factories)
+}
+
+// parseMetricsFlags parses the metrics flags and the emitter flags wired by
+// wire from the command line and the environment into metrics, defaulting to
+// the values in the config file at configPath unless it is empty.
+func parseMetricsFlags(metrics interface{}, configPath string, wire func(*flags.Group)) error {
+	parser := flags.NewParser(&struct{}{}, flags.IgnoreUnknown)
+	parser.NamespaceDelimiter = "-"
+
+	group, err := parser.AddGroup("Metrics & Diagnostics", "", metrics)
+	if err != nil {
+		return err
+	}
+
+	wire(group)
+
+	if configPath != "" {
+		err = metric.LoadConfigFile(group, configPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	twentythousandtonnesofcrudeoil.TheEnvironmentIsPerfectlySafe(parser, "CONCOURSE_")
+
+	_, err = parser.ParseArgs(os.Args[1:])
+	return err
 }
 
 func (cmd *RunCommand) constructDBConn(
@@ -1269,6 +1356,7 @@ func (cmd *RunCommand) constructAPIHandler(
 	gcContainerDestroyer gc.Destroyer,
 	dbBuildFactory db.BuildFactory,
 	resourceConfigFactory db.ResourceConfigFactory,
+	dbConn db.Conn,
 	workerClient worker.Client,
 	drain <-chan struct{},
 	radarScannerFactory radar.ScannerFactory,
@@ -1309,6 +1397,7 @@ func (cmd *RunCommand) constructAPIHandler(
 		gcContainerDestroyer,
 		dbBuildFactory,
 		resourceConfigFactory,
+		dbConn,
 
 		buildserver.NewEventHandler,
 		drain,
@@ -1326,6 +1415,7 @@ func (cmd *RunCommand) constructAPIHandler(
 		variablesFactory,
 		credsManagers,
 		containerserver.NewInterceptTimeoutFactory(cmd.InterceptIdleTimeout),
+		cmd.MaxSpooledArtifactSize,
 	)
 }
 
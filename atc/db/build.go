@@ -42,7 +42,7 @@ const (
 	BuildStatusErrored   BuildStatus = "errored"
 )
 
-var buildsQuery = psql.Select("b.id, b.name, b.job_id, b.team_id, b.status, b.manually_triggered, b.scheduled, b.schema, b.private_plan, b.public_plan, b.start_time, b.end_time, b.reap_time, j.name, b.pipeline_id, p.name, t.name, b.nonce, b.drained").
+var buildsQuery = psql.Select("b.id, b.name, b.job_id, b.team_id, b.status, b.manually_triggered, b.scheduled, b.schema, b.private_plan, b.public_plan, b.start_time, b.end_time, b.reap_time, j.name, b.pipeline_id, p.name, t.name, b.nonce, b.drained, b.rerun_of").
 	From("builds b").
 	JoinClause("LEFT OUTER JOIN jobs j ON b.job_id = j.id").
 	JoinClause("LEFT OUTER JOIN pipelines p ON b.pipeline_id = p.id").
@@ -65,11 +65,13 @@ type Build interface {
 	Schema() string
 	PrivatePlan() string
 	PublicPlan() *json.RawMessage
+	HasPlan() bool
 	Status() BuildStatus
 	StartTime() time.Time
 	EndTime() time.Time
 	ReapTime() time.Time
 	IsManuallyTriggered() bool
+	RerunOf() int
 	IsScheduled() bool
 	IsRunning() bool
 
@@ -95,6 +97,7 @@ type Build interface {
 	SaveOutput(lager.Logger, string, atc.Source, creds.VersionedResourceTypes, atc.Version, ResourceConfigMetadataFields, string, string) error
 	UseInputs(inputs []BuildInput) error
 
+	Inputs() ([]BuildInput, error)
 	Resources() ([]BuildInput, []BuildOutput, error)
 	SaveImageResourceVersion(UsedResourceCache) error
 
@@ -124,6 +127,7 @@ type build struct {
 	jobName      string
 
 	isManuallyTriggered bool
+	rerunOf             int
 
 	schema      string
 	privatePlan string
@@ -160,6 +164,7 @@ func (b *build) PipelineName() string         { return b.pipelineName }
 func (b *build) TeamID() int                  { return b.teamID }
 func (b *build) TeamName() string             { return b.teamName }
 func (b *build) IsManuallyTriggered() bool    { return b.isManuallyTriggered }
+func (b *build) RerunOf() int                 { return b.rerunOf }
 func (b *build) Schema() string               { return b.schema }
 func (b *build) PrivatePlan() string          { return b.privatePlan }
 func (b *build) PublicPlan() *json.RawMessage { return b.publicPlan }
@@ -179,6 +184,13 @@ func (b *build) IsRunning() bool {
 	}
 }
 
+// HasPlan returns whether the build has been given a plan. Builds of jobs
+// only get one once they are started; until then their public plan is the
+// column's default of an empty object.
+func (b *build) HasPlan() bool {
+	return b.publicPlan != nil && string(*b.publicPlan) != "{}"
+}
+
 func (b *build) Reload() (bool, error) {
 	row := buildsQuery.Where(sq.Eq{"b.id": b.id}).
 		RunWith(b.conn).
@@ -953,30 +965,25 @@ func (b *build) UseInputs(inputs []BuildInput) error {
 	return tx.Commit()
 }
 
+// Inputs returns every input recorded for the build, including those which
+// were also outputs of it, unlike Resources.
+func (b *build) Inputs() ([]BuildInput, error) {
+	rows, err := b.inputsQuery().
+		RunWith(b.conn).
+		Query()
+	if err != nil {
+		return nil, err
+	}
+
+	defer Close(rows)
+
+	return scanBuildInputs(rows)
+}
+
 func (b *build) Resources() ([]BuildInput, []BuildOutput, error) {
-	inputs := []BuildInput{}
 	outputs := []BuildOutput{}
 
-	firstOccurrence := `
-		NOT EXISTS (
-			SELECT 1
-			FROM build_resource_config_version_inputs i, builds b
-			WHERE versions.version_md5 = i.version_md5
-			AND resources.resource_config_scope_id = versions.resource_config_scope_id
-			AND resources.id = i.resource_id
-			AND b.job_id = builds.job_id
-			AND i.build_id = b.id
-			AND i.build_id < builds.id
-		)`
-
-	rows, err := psql.Select("inputs.name", "resources.id", "versions.version", firstOccurrence).
-		From("resource_config_versions versions, build_resource_config_version_inputs inputs, builds, resources").
-		Where(sq.Eq{"builds.id": b.id}).
-		Where(sq.NotEq{"versions.check_order": 0}).
-		Where(sq.Expr("inputs.build_id = builds.id")).
-		Where(sq.Expr("inputs.version_md5 = versions.version_md5")).
-		Where(sq.Expr("resources.resource_config_scope_id = versions.resource_config_scope_id")).
-		Where(sq.Expr("resources.id = inputs.resource_id")).
+	rows, err := b.inputsQuery().
 		Where(sq.Expr(`NOT EXISTS (
 			SELECT 1
 			FROM build_resource_config_version_outputs outputs
@@ -993,31 +1000,9 @@ func (b *build) Resources() ([]BuildInput, []BuildOutput, error) {
 
 	defer Close(rows)
 
-	for rows.Next() {
-		var (
-			inputName       string
-			firstOccurrence bool
-			versionBlob     string
-			version         atc.Version
-			resourceID      int
-		)
-
-		err = rows.Scan(&inputName, &resourceID, &versionBlob, &firstOccurrence)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		err = json.Unmarshal([]byte(versionBlob), &version)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		inputs = append(inputs, BuildInput{
-			Name:            inputName,
-			Version:         version,
-			ResourceID:      resourceID,
-			FirstOccurrence: firstOccurrence,
-		})
+	inputs, err := scanBuildInputs(rows)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	rows, err = psql.Select("outputs.name", "versions.version").
@@ -1063,6 +1048,62 @@ func (b *build) Resources() ([]BuildInput, []BuildOutput, error) {
 	return inputs, outputs, nil
 }
 
+func (b *build) inputsQuery() sq.SelectBuilder {
+	firstOccurrence := `
+		NOT EXISTS (
+			SELECT 1
+			FROM build_resource_config_version_inputs i, builds b
+			WHERE versions.version_md5 = i.version_md5
+			AND resources.resource_config_scope_id = versions.resource_config_scope_id
+			AND resources.id = i.resource_id
+			AND b.job_id = builds.job_id
+			AND i.build_id = b.id
+			AND i.build_id < builds.id
+		)`
+
+	return psql.Select("inputs.name", "resources.id", "versions.version", firstOccurrence).
+		From("resource_config_versions versions, build_resource_config_version_inputs inputs, builds, resources").
+		Where(sq.Eq{"builds.id": b.id}).
+		Where(sq.NotEq{"versions.check_order": 0}).
+		Where(sq.Expr("inputs.build_id = builds.id")).
+		Where(sq.Expr("inputs.version_md5 = versions.version_md5")).
+		Where(sq.Expr("resources.resource_config_scope_id = versions.resource_config_scope_id")).
+		Where(sq.Expr("resources.id = inputs.resource_id"))
+}
+
+func scanBuildInputs(rows *sql.Rows) ([]BuildInput, error) {
+	inputs := []BuildInput{}
+
+	for rows.Next() {
+		var (
+			inputName       string
+			firstOccurrence bool
+			versionBlob     string
+			version         atc.Version
+			resourceID      int
+		)
+
+		err := rows.Scan(&inputName, &resourceID, &versionBlob, &firstOccurrence)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(versionBlob), &version)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, BuildInput{
+			Name:            inputName,
+			Version:         version,
+			ResourceID:      resourceID,
+			FirstOccurrence: firstOccurrence,
+		})
+	}
+
+	return inputs, nil
+}
+
 func (p *build) saveInputTx(tx Tx, buildID int, input BuildInput) error {
 	versionJSON, err := json.Marshal(input.Version)
 	if err != nil {
@@ -1092,7 +1133,7 @@ func buildEventSeq(buildid int) string {
 
 func scanBuild(b *build, row scannable, encryptionStrategy encryption.Strategy) error {
 	var (
-		jobID, pipelineID                                      sql.NullInt64
+		jobID, pipelineID, rerunOf                             sql.NullInt64
 		schema, privatePlan, jobName, pipelineName, publicPlan sql.NullString
 		startTime, endTime, reapTime                           pq.NullTime
 		nonce                                                  sql.NullString
@@ -1101,7 +1142,7 @@ func scanBuild(b *build, row scannable, encryptionStrategy encryption.Strategy)
 		status string
 	)
 
-	err := row.Scan(&b.id, &b.name, &jobID, &b.teamID, &status, &b.isManuallyTriggered, &b.scheduled, &schema, &privatePlan, &publicPlan, &startTime, &endTime, &reapTime, &jobName, &pipelineID, &pipelineName, &b.teamName, &nonce, &drained)
+	err := row.Scan(&b.id, &b.name, &jobID, &b.teamID, &status, &b.isManuallyTriggered, &b.scheduled, &schema, &privatePlan, &publicPlan, &startTime, &endTime, &reapTime, &jobName, &pipelineID, &pipelineName, &b.teamName, &nonce, &drained, &rerunOf)
 	if err != nil {
 		return err
 	}
@@ -1116,6 +1157,7 @@ func scanBuild(b *build, row scannable, encryptionStrategy encryption.Strategy)
 	b.endTime = endTime.Time
 	b.reapTime = reapTime.Time
 	b.drained = drained
+	b.rerunOf = int(rerunOf.Int64)
 
 	var (
 		noncense      *string
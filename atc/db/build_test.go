@@ -134,6 +134,37 @@ var _ = Describe("Build", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(found).To(BeTrue())
 			Expect(build.PublicPlan()).To(Equal(plan.Public()))
+			Expect(build.HasPlan()).To(BeTrue())
+		})
+	})
+
+	Describe("HasPlan", func() {
+		It("is false for a job build which has not been started", func() {
+			pipeline, _, err := team.SavePipeline("some-pipeline", atc.Config{
+				Jobs: atc.JobConfigs{
+					{
+						Name: "some-job",
+					},
+				},
+			}, db.ConfigVersion(1), db.PipelineUnpaused)
+			Expect(err).ToNot(HaveOccurred())
+
+			job, found, err := pipeline.Job("some-job")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			build, err := job.CreateBuild()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(build.HasPlan()).To(BeFalse())
+
+			found, err = build.Schedule()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			found, err = build.Reload()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(build.HasPlan()).To(BeFalse())
 		})
 	})
 
@@ -554,6 +585,34 @@ var _ = Describe("Build", func() {
 			}))
 		})
 
+		It("returns inputs which were also outputs only from Inputs", func() {
+			build, err := job.CreateBuild()
+			Expect(err).NotTo(HaveOccurred())
+
+			err = build.UseInputs([]db.BuildInput{
+				db.BuildInput{
+					Name:       "some-input",
+					Version:    atc.Version{"ver": "1"},
+					ResourceID: resource1.ID(),
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// put the same version that was fetched
+			err = build.SaveOutput(logger, "some-type", atc.Source{"some": "source-1"}, creds.VersionedResourceTypes{}, atc.Version{"ver": "1"}, nil, "some-output-name", "some-resource")
+			Expect(err).NotTo(HaveOccurred())
+
+			inputs, _, err := build.Resources()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inputs).To(BeEmpty())
+
+			inputs, err = build.Inputs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inputs).To(ConsistOf([]db.BuildInput{
+				{Name: "some-input", Version: atc.Version{"ver": "1"}, ResourceID: resource1.ID(), FirstOccurrence: true},
+			}))
+		})
+
 		It("can't get no satisfaction (resources from a one-off build)", func() {
 			oneOffBuild, err := team.CreateOneOffBuild()
 			Expect(err).NotTo(HaveOccurred())
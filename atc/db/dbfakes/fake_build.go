@@ -124,6 +124,16 @@ type FakeBuild struct {
 	finishWithErrorReturnsOnCall map[int]struct {
 		result1 error
 	}
+	HasPlanStub        func() bool
+	hasPlanMutex       sync.RWMutex
+	hasPlanArgsForCall []struct {
+	}
+	hasPlanReturns struct {
+		result1 bool
+	}
+	hasPlanReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	IDStub        func() int
 	iDMutex       sync.RWMutex
 	iDArgsForCall []struct {
@@ -134,6 +144,18 @@ type FakeBuild struct {
 	iDReturnsOnCall map[int]struct {
 		result1 int
 	}
+	InputsStub        func() ([]db.BuildInput, error)
+	inputsMutex       sync.RWMutex
+	inputsArgsForCall []struct {
+	}
+	inputsReturns struct {
+		result1 []db.BuildInput
+		result2 error
+	}
+	inputsReturnsOnCall map[int]struct {
+		result1 []db.BuildInput
+		result2 error
+	}
 	InterceptibleStub        func() (bool, error)
 	interceptibleMutex       sync.RWMutex
 	interceptibleArgsForCall []struct {
@@ -316,6 +338,16 @@ type FakeBuild struct {
 		result1 bool
 		result2 error
 	}
+	RerunOfStub        func() int
+	rerunOfMutex       sync.RWMutex
+	rerunOfArgsForCall []struct {
+	}
+	rerunOfReturns struct {
+		result1 int
+	}
+	rerunOfReturnsOnCall map[int]struct {
+		result1 int
+	}
 	ResourcesStub        func() ([]db.BuildInput, []db.BuildOutput, error)
 	resourcesMutex       sync.RWMutex
 	resourcesArgsForCall []struct {
@@ -1034,6 +1066,58 @@ func (fake *FakeBuild) FinishWithErrorReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeBuild) HasPlan() bool {
+	fake.hasPlanMutex.Lock()
+	ret, specificReturn := fake.hasPlanReturnsOnCall[len(fake.hasPlanArgsForCall)]
+	fake.hasPlanArgsForCall = append(fake.hasPlanArgsForCall, struct {
+	}{})
+	fake.recordInvocation("HasPlan", []interface{}{})
+	fake.hasPlanMutex.Unlock()
+	if fake.HasPlanStub != nil {
+		return fake.HasPlanStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.hasPlanReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBuild) HasPlanCallCount() int {
+	fake.hasPlanMutex.RLock()
+	defer fake.hasPlanMutex.RUnlock()
+	return len(fake.hasPlanArgsForCall)
+}
+
+func (fake *FakeBuild) HasPlanCalls(stub func() bool) {
+	fake.hasPlanMutex.Lock()
+	defer fake.hasPlanMutex.Unlock()
+	fake.HasPlanStub = stub
+}
+
+func (fake *FakeBuild) HasPlanReturns(result1 bool) {
+	fake.hasPlanMutex.Lock()
+	defer fake.hasPlanMutex.Unlock()
+	fake.HasPlanStub = nil
+	fake.hasPlanReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeBuild) HasPlanReturnsOnCall(i int, result1 bool) {
+	fake.hasPlanMutex.Lock()
+	defer fake.hasPlanMutex.Unlock()
+	fake.HasPlanStub = nil
+	if fake.hasPlanReturnsOnCall == nil {
+		fake.hasPlanReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.hasPlanReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeBuild) ID() int {
 	fake.iDMutex.Lock()
 	ret, specificReturn := fake.iDReturnsOnCall[len(fake.iDArgsForCall)]
@@ -1086,6 +1170,61 @@ func (fake *FakeBuild) IDReturnsOnCall(i int, result1 int) {
 	}{result1}
 }
 
+func (fake *FakeBuild) Inputs() ([]db.BuildInput, error) {
+	fake.inputsMutex.Lock()
+	ret, specificReturn := fake.inputsReturnsOnCall[len(fake.inputsArgsForCall)]
+	fake.inputsArgsForCall = append(fake.inputsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("Inputs", []interface{}{})
+	fake.inputsMutex.Unlock()
+	if fake.InputsStub != nil {
+		return fake.InputsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.inputsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBuild) InputsCallCount() int {
+	fake.inputsMutex.RLock()
+	defer fake.inputsMutex.RUnlock()
+	return len(fake.inputsArgsForCall)
+}
+
+func (fake *FakeBuild) InputsCalls(stub func() ([]db.BuildInput, error)) {
+	fake.inputsMutex.Lock()
+	defer fake.inputsMutex.Unlock()
+	fake.InputsStub = stub
+}
+
+func (fake *FakeBuild) InputsReturns(result1 []db.BuildInput, result2 error) {
+	fake.inputsMutex.Lock()
+	defer fake.inputsMutex.Unlock()
+	fake.InputsStub = nil
+	fake.inputsReturns = struct {
+		result1 []db.BuildInput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBuild) InputsReturnsOnCall(i int, result1 []db.BuildInput, result2 error) {
+	fake.inputsMutex.Lock()
+	defer fake.inputsMutex.Unlock()
+	fake.InputsStub = nil
+	if fake.inputsReturnsOnCall == nil {
+		fake.inputsReturnsOnCall = make(map[int]struct {
+			result1 []db.BuildInput
+			result2 error
+		})
+	}
+	fake.inputsReturnsOnCall[i] = struct {
+		result1 []db.BuildInput
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeBuild) Interceptible() (bool, error) {
 	fake.interceptibleMutex.Lock()
 	ret, specificReturn := fake.interceptibleReturnsOnCall[len(fake.interceptibleArgsForCall)]
@@ -1988,6 +2127,58 @@ func (fake *FakeBuild) ReloadReturnsOnCall(i int, result1 bool, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeBuild) RerunOf() int {
+	fake.rerunOfMutex.Lock()
+	ret, specificReturn := fake.rerunOfReturnsOnCall[len(fake.rerunOfArgsForCall)]
+	fake.rerunOfArgsForCall = append(fake.rerunOfArgsForCall, struct {
+	}{})
+	fake.recordInvocation("RerunOf", []interface{}{})
+	fake.rerunOfMutex.Unlock()
+	if fake.RerunOfStub != nil {
+		return fake.RerunOfStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.rerunOfReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeBuild) RerunOfCallCount() int {
+	fake.rerunOfMutex.RLock()
+	defer fake.rerunOfMutex.RUnlock()
+	return len(fake.rerunOfArgsForCall)
+}
+
+func (fake *FakeBuild) RerunOfCalls(stub func() int) {
+	fake.rerunOfMutex.Lock()
+	defer fake.rerunOfMutex.Unlock()
+	fake.RerunOfStub = stub
+}
+
+func (fake *FakeBuild) RerunOfReturns(result1 int) {
+	fake.rerunOfMutex.Lock()
+	defer fake.rerunOfMutex.Unlock()
+	fake.RerunOfStub = nil
+	fake.rerunOfReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeBuild) RerunOfReturnsOnCall(i int, result1 int) {
+	fake.rerunOfMutex.Lock()
+	defer fake.rerunOfMutex.Unlock()
+	fake.RerunOfStub = nil
+	if fake.rerunOfReturnsOnCall == nil {
+		fake.rerunOfReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.rerunOfReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
 func (fake *FakeBuild) Resources() ([]db.BuildInput, []db.BuildOutput, error) {
 	fake.resourcesMutex.Lock()
 	ret, specificReturn := fake.resourcesReturnsOnCall[len(fake.resourcesArgsForCall)]
@@ -2930,8 +3121,12 @@ func (fake *FakeBuild) Invocations() map[string][][]interface{} {
 	defer fake.finishMutex.RUnlock()
 	fake.finishWithErrorMutex.RLock()
 	defer fake.finishWithErrorMutex.RUnlock()
+	fake.hasPlanMutex.RLock()
+	defer fake.hasPlanMutex.RUnlock()
 	fake.iDMutex.RLock()
 	defer fake.iDMutex.RUnlock()
+	fake.inputsMutex.RLock()
+	defer fake.inputsMutex.RUnlock()
 	fake.interceptibleMutex.RLock()
 	defer fake.interceptibleMutex.RUnlock()
 	fake.isDrainedMutex.RLock()
@@ -2966,6 +3161,8 @@ func (fake *FakeBuild) Invocations() map[string][][]interface{} {
 	defer fake.reapTimeMutex.RUnlock()
 	fake.reloadMutex.RLock()
 	defer fake.reloadMutex.RUnlock()
+	fake.rerunOfMutex.RLock()
+	defer fake.rerunOfMutex.RUnlock()
 	fake.resourcesMutex.RLock()
 	defer fake.resourcesMutex.RUnlock()
 	fake.saveEventMutex.RLock()
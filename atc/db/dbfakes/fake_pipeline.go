@@ -259,6 +259,18 @@ type FakePipeline struct {
 		result1 db.Jobs
 		result2 error
 	}
+	LatestResourceVersionsStub        func() (map[string]atc.Version, error)
+	latestResourceVersionsMutex       sync.RWMutex
+	latestResourceVersionsArgsForCall []struct {
+	}
+	latestResourceVersionsReturns struct {
+		result1 map[string]atc.Version
+		result2 error
+	}
+	latestResourceVersionsReturnsOnCall map[int]struct {
+		result1 map[string]atc.Version
+		result2 error
+	}
 	LoadVersionsDBStub        func() (*algorithm.VersionsDB, error)
 	loadVersionsDBMutex       sync.RWMutex
 	loadVersionsDBArgsForCall []struct {
@@ -1608,6 +1620,61 @@ func (fake *FakePipeline) JobsReturnsOnCall(i int, result1 db.Jobs, result2 erro
 	}{result1, result2}
 }
 
+func (fake *FakePipeline) LatestResourceVersions() (map[string]atc.Version, error) {
+	fake.latestResourceVersionsMutex.Lock()
+	ret, specificReturn := fake.latestResourceVersionsReturnsOnCall[len(fake.latestResourceVersionsArgsForCall)]
+	fake.latestResourceVersionsArgsForCall = append(fake.latestResourceVersionsArgsForCall, struct {
+	}{})
+	fake.recordInvocation("LatestResourceVersions", []interface{}{})
+	fake.latestResourceVersionsMutex.Unlock()
+	if fake.LatestResourceVersionsStub != nil {
+		return fake.LatestResourceVersionsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	fakeReturns := fake.latestResourceVersionsReturns
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakePipeline) LatestResourceVersionsCallCount() int {
+	fake.latestResourceVersionsMutex.RLock()
+	defer fake.latestResourceVersionsMutex.RUnlock()
+	return len(fake.latestResourceVersionsArgsForCall)
+}
+
+func (fake *FakePipeline) LatestResourceVersionsCalls(stub func() (map[string]atc.Version, error)) {
+	fake.latestResourceVersionsMutex.Lock()
+	defer fake.latestResourceVersionsMutex.Unlock()
+	fake.LatestResourceVersionsStub = stub
+}
+
+func (fake *FakePipeline) LatestResourceVersionsReturns(result1 map[string]atc.Version, result2 error) {
+	fake.latestResourceVersionsMutex.Lock()
+	defer fake.latestResourceVersionsMutex.Unlock()
+	fake.LatestResourceVersionsStub = nil
+	fake.latestResourceVersionsReturns = struct {
+		result1 map[string]atc.Version
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePipeline) LatestResourceVersionsReturnsOnCall(i int, result1 map[string]atc.Version, result2 error) {
+	fake.latestResourceVersionsMutex.Lock()
+	defer fake.latestResourceVersionsMutex.Unlock()
+	fake.LatestResourceVersionsStub = nil
+	if fake.latestResourceVersionsReturnsOnCall == nil {
+		fake.latestResourceVersionsReturnsOnCall = make(map[int]struct {
+			result1 map[string]atc.Version
+			result2 error
+		})
+	}
+	fake.latestResourceVersionsReturnsOnCall[i] = struct {
+		result1 map[string]atc.Version
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakePipeline) LoadVersionsDB() (*algorithm.VersionsDB, error) {
 	fake.loadVersionsDBMutex.Lock()
 	ret, specificReturn := fake.loadVersionsDBReturnsOnCall[len(fake.loadVersionsDBArgsForCall)]
@@ -2493,6 +2560,8 @@ func (fake *FakePipeline) Invocations() map[string][][]interface{} {
 	defer fake.jobMutex.RUnlock()
 	fake.jobsMutex.RLock()
 	defer fake.jobsMutex.RUnlock()
+	fake.latestResourceVersionsMutex.RLock()
+	defer fake.latestResourceVersionsMutex.RUnlock()
 	fake.loadVersionsDBMutex.RLock()
 	defer fake.loadVersionsDBMutex.RUnlock()
 	fake.nameMutex.RLock()
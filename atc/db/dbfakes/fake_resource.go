@@ -335,6 +335,21 @@ type FakeResource struct {
 	unpinVersionReturnsOnCall map[int]struct {
 		result1 error
 	}
+	VersionEnabledStub        func(int) (bool, bool, error)
+	versionEnabledMutex       sync.RWMutex
+	versionEnabledArgsForCall []struct {
+		arg1 int
+	}
+	versionEnabledReturns struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}
+	versionEnabledReturnsOnCall map[int]struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}
 	VersionsStub        func(db.Page) ([]atc.ResourceVersion, db.Pagination, bool, error)
 	versionsMutex       sync.RWMutex
 	versionsArgsForCall []struct {
@@ -2010,6 +2025,72 @@ func (fake *FakeResource) UnpinVersionReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeResource) VersionEnabled(arg1 int) (bool, bool, error) {
+	fake.versionEnabledMutex.Lock()
+	ret, specificReturn := fake.versionEnabledReturnsOnCall[len(fake.versionEnabledArgsForCall)]
+	fake.versionEnabledArgsForCall = append(fake.versionEnabledArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	fake.recordInvocation("VersionEnabled", []interface{}{arg1})
+	fake.versionEnabledMutex.Unlock()
+	if fake.VersionEnabledStub != nil {
+		return fake.VersionEnabledStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	fakeReturns := fake.versionEnabledReturns
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeResource) VersionEnabledCallCount() int {
+	fake.versionEnabledMutex.RLock()
+	defer fake.versionEnabledMutex.RUnlock()
+	return len(fake.versionEnabledArgsForCall)
+}
+
+func (fake *FakeResource) VersionEnabledCalls(stub func(int) (bool, bool, error)) {
+	fake.versionEnabledMutex.Lock()
+	defer fake.versionEnabledMutex.Unlock()
+	fake.VersionEnabledStub = stub
+}
+
+func (fake *FakeResource) VersionEnabledArgsForCall(i int) int {
+	fake.versionEnabledMutex.RLock()
+	defer fake.versionEnabledMutex.RUnlock()
+	argsForCall := fake.versionEnabledArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeResource) VersionEnabledReturns(result1 bool, result2 bool, result3 error) {
+	fake.versionEnabledMutex.Lock()
+	defer fake.versionEnabledMutex.Unlock()
+	fake.VersionEnabledStub = nil
+	fake.versionEnabledReturns = struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeResource) VersionEnabledReturnsOnCall(i int, result1 bool, result2 bool, result3 error) {
+	fake.versionEnabledMutex.Lock()
+	defer fake.versionEnabledMutex.Unlock()
+	fake.VersionEnabledStub = nil
+	if fake.versionEnabledReturnsOnCall == nil {
+		fake.versionEnabledReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 bool
+			result3 error
+		})
+	}
+	fake.versionEnabledReturnsOnCall[i] = struct {
+		result1 bool
+		result2 bool
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeResource) Versions(arg1 db.Page) ([]atc.ResourceVersion, db.Pagination, bool, error) {
 	fake.versionsMutex.Lock()
 	ret, specificReturn := fake.versionsReturnsOnCall[len(fake.versionsArgsForCall)]
@@ -2194,6 +2275,8 @@ func (fake *FakeResource) Invocations() map[string][][]interface{} {
 	defer fake.typeMutex.RUnlock()
 	fake.unpinVersionMutex.RLock()
 	defer fake.unpinVersionMutex.RUnlock()
+	fake.versionEnabledMutex.RLock()
+	defer fake.versionEnabledMutex.RUnlock()
 	fake.versionsMutex.RLock()
 	defer fake.versionsMutex.RUnlock()
 	fake.webhookTokenMutex.RLock()
@@ -1286,6 +1286,77 @@ var _ = Describe("Job", func() {
 		})
 	})
 
+	Describe("RerunBuild", func() {
+		var (
+			resource     db.Resource
+			buildToRerun db.Build
+		)
+
+		BeforeEach(func() {
+			setupTx, err := dbConn.Begin()
+			Expect(err).ToNot(HaveOccurred())
+
+			brt := db.BaseResourceType{
+				Name: "some-type",
+			}
+
+			_, err = brt.FindOrCreate(setupTx, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(setupTx.Commit()).To(Succeed())
+
+			var found bool
+			resource, found, err = pipeline.Resource("some-resource")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			resourceConfigScope, err := resource.SetResourceConfig(logger, atc.Source{}, creds.VersionedResourceTypes{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = resourceConfigScope.SaveVersions([]atc.Version{
+				{"version": "v1"},
+				{"version": "v2"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			buildToRerun, err = job.CreateBuild()
+			Expect(err).NotTo(HaveOccurred())
+
+			err = buildToRerun.UseInputs([]db.BuildInput{
+				{
+					Name:       "some-input",
+					Version:    atc.Version{"version": "v1"},
+					ResourceID: resource.ID(),
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("creates a pending build which is a rerun of the build", func() {
+			rerunBuild, err := job.RerunBuild(buildToRerun)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(rerunBuild.ID()).NotTo(Equal(buildToRerun.ID()))
+			Expect(rerunBuild.Name()).To(Equal("2"))
+			Expect(rerunBuild.Status()).To(Equal(db.BuildStatusPending))
+			Expect(rerunBuild.RerunOf()).To(Equal(buildToRerun.ID()))
+
+			pendingBuilds, err := job.GetPendingBuilds()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pendingBuilds).To(HaveLen(2))
+		})
+
+		It("uses the inputs of the build", func() {
+			rerunBuild, err := job.RerunBuild(buildToRerun)
+			Expect(err).NotTo(HaveOccurred())
+
+			inputs, _, err := rerunBuild.Resources()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inputs).To(ConsistOf([]db.BuildInput{
+				{Name: "some-input", Version: atc.Version{"version": "v1"}, ResourceID: resource.ID(), FirstOccurrence: false},
+			}))
+		})
+	})
+
 	Describe("Clear worker task cache", func() {
 		Context("when worker task cache exists", func() {
 			var (
@@ -65,6 +65,7 @@ type Pipeline interface {
 
 	Resource(name string) (Resource, bool, error)
 	Resources() (Resources, error)
+	LatestResourceVersions() (map[string]atc.Version, error)
 
 	ResourceTypes() (ResourceTypes, error)
 	ResourceType(name string) (ResourceType, bool, error)
@@ -455,6 +456,42 @@ func (p *pipeline) Resources() (Resources, error) {
 	return resources(p.id, p.conn, p.lockFactory)
 }
 
+// LatestResourceVersions returns the latest version of each of the pipeline's
+// resources which has any, by resource name.
+func (p *pipeline) LatestResourceVersions() (map[string]atc.Version, error) {
+	rows, err := p.conn.Query(`
+		SELECT DISTINCT ON (r.id) r.name, v.version
+		FROM resources r
+		INNER JOIN resource_config_versions v ON v.resource_config_scope_id = r.resource_config_scope_id
+		WHERE r.pipeline_id = $1 AND r.active AND v.check_order != 0
+		ORDER BY r.id, v.check_order DESC
+	`, p.id)
+	if err != nil {
+		return nil, err
+	}
+
+	defer Close(rows)
+
+	versions := map[string]atc.Version{}
+	for rows.Next() {
+		var name, versionJSON string
+		err := rows.Scan(&name, &versionJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		var version atc.Version
+		err = json.Unmarshal([]byte(versionJSON), &version)
+		if err != nil {
+			return nil, err
+		}
+
+		versions[name] = version
+	}
+
+	return versions, nil
+}
+
 func (p *pipeline) ResourceTypes() (ResourceTypes, error) {
 	rows, err := resourceTypesQuery.
 		Where(sq.Eq{"r.pipeline_id": p.id}).
@@ -44,6 +44,7 @@ type Resource interface {
 	CurrentPinnedVersion() atc.Version
 
 	ResourceConfigVersionID(atc.Version) (int, bool, error)
+	VersionEnabled(rcvID int) (bool, bool, error)
 	Versions(page Page) ([]atc.ResourceVersion, Pagination, bool, error)
 	SaveUncheckedVersion(atc.Version, ResourceConfigMetadataFields, ResourceConfig, creds.VersionedResourceTypes) (bool, error)
 
@@ -313,6 +314,33 @@ func (r *resource) ResourceConfigVersionID(version atc.Version) (int, bool, erro
 	return id, true, nil
 }
 
+// VersionEnabled returns whether the given resource config version is
+// enabled for this resource, and whether the version belongs to the
+// resource at all.
+func (r *resource) VersionEnabled(rcvID int) (bool, bool, error) {
+	var enabled bool
+	err := r.conn.QueryRow(`
+		SELECT NOT EXISTS (
+			SELECT 1
+			FROM resource_disabled_versions d
+			WHERE d.resource_id = r.id
+			AND d.version_md5 = v.version_md5
+		)
+		FROM resource_config_versions v, resources r
+		WHERE r.id = $1
+		AND v.id = $2
+		AND r.resource_config_scope_id = v.resource_config_scope_id
+	`, r.id, rcvID).Scan(&enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	return enabled, true, nil
+}
+
 func (r *resource) SetPinComment(comment string) error {
 	_, err := psql.Update("resource_pins").
 		Set("comment_text", comment).
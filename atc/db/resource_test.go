@@ -1062,6 +1062,74 @@ var _ = Describe("Resource", func() {
 		})
 	})
 
+	Describe("VersionEnabled", func() {
+		var resource db.Resource
+		var enabledID, disabledID int
+
+		BeforeEach(func() {
+			var found bool
+			var err error
+			resource, found, err = pipeline.Resource("some-other-resource")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			setupTx, err := dbConn.Begin()
+			Expect(err).ToNot(HaveOccurred())
+
+			brt := db.BaseResourceType{
+				Name: "git",
+			}
+
+			_, err = brt.FindOrCreate(setupTx, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(setupTx.Commit()).To(Succeed())
+
+			resourceScope, err := resource.SetResourceConfig(logger, atc.Source{"some": "other-repository"}, creds.VersionedResourceTypes{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = resourceScope.SaveVersions([]atc.Version{
+				atc.Version{"version": "v1"},
+				atc.Version{"version": "v2"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			v1, found, err := resourceScope.FindVersion(atc.Version{"version": "v1"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			enabledID = v1.ID()
+
+			v2, found, err := resourceScope.FindVersion(atc.Version{"version": "v2"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			disabledID = v2.ID()
+
+			err = resource.DisableVersion(disabledID)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns whether the version is enabled for the resource", func() {
+			enabled, found, err := resource.VersionEnabled(enabledID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(enabled).To(BeTrue())
+
+			enabled, found, err = resource.VersionEnabled(disabledID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(enabled).To(BeFalse())
+		})
+
+		It("does not find versions of other resources", func() {
+			otherResource, found, err := pipeline.Resource("some-resource")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			_, found, err = otherResource.VersionEnabled(enabledID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+
 	Describe("PinVersion/UnpinVersion", func() {
 		var resource db.Resource
 		var resID int
@@ -0,0 +1,130 @@
+package metric
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// AggregatingEmitter cuts down on the events of metrics which are emitted more
+// often than the backend can make use of. Within each window, gauges with the
+// same name, host and attributes collapse to the latest value and counters
+// are summed. Other events, e.g. timers whose distribution matters, are
+// passed on as they come.
+type AggregatingEmitter struct {
+	*windowedEmitter
+}
+
+// NewAggregatingEmitter wraps an emitter, flushing the aggregated events to it
+// every interval.
+func NewAggregatingEmitter(emitter Emitter, interval time.Duration) *AggregatingEmitter {
+	return &AggregatingEmitter{
+		windowedEmitter: newWindowedEmitter(emitter, aggregateBuffer{}, interval),
+	}
+}
+
+type aggregateBuffer struct{}
+
+func (aggregateBuffer) holds(event Event) bool {
+	return event.Type == EventTypeGauge || event.Type == EventTypeCounter
+}
+
+func (aggregateBuffer) merge(entry interface{}, event Event) interface{} {
+	existing, found := entry.(Event)
+	if !found {
+		return event
+	}
+
+	if event.Type == EventTypeCounter && existing.Type == EventTypeCounter {
+		sum, ok := sumValues(existing.Value, event.Value)
+		if ok {
+			event.Value = sum
+		}
+	}
+
+	return event
+}
+
+func (aggregateBuffer) flush(entries []interface{}) []Event {
+	events := make([]Event, len(entries))
+	for i, entry := range entries {
+		events[i] = entry.(Event)
+	}
+
+	return events
+}
+
+// aggregationKey identifies the series the event belongs to.
+func aggregationKey(event Event) string {
+	attributes := make([]string, 0, len(event.Attributes))
+	for k, v := range event.Attributes {
+		attributes = append(attributes, k+"="+v)
+	}
+
+	sort.Strings(attributes)
+
+	return strings.Join(append([]string{event.Name, event.Host}, attributes...), "\x00")
+}
+
+// sumValues adds two event values, keeping them integers if both are.
+func sumValues(a interface{}, b interface{}) (interface{}, bool) {
+	ai, aIsInt := intValue(a)
+	bi, bIsInt := intValue(b)
+	if aIsInt && bIsInt {
+		return ai + bi, true
+	}
+
+	af, ok := floatValue(a)
+	if !ok {
+		return nil, false
+	}
+
+	bf, ok := floatValue(b)
+	if !ok {
+		return nil, false
+	}
+
+	return af + bf, true
+}
+
+func intValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func floatValue(value interface{}) (float64, bool) {
+	if i, ok := intValue(value); ok {
+		return float64(i), true
+	}
+
+	switch v := value.(type) {
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
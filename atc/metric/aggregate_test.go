@@ -0,0 +1,98 @@
+package metric_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AggregatingEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		logger      *lagertest.TestLogger
+		aggregating *metric.AggregatingEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+		aggregating = metric.NewAggregatingEmitter(fakeEmitter, time.Hour)
+	})
+
+	emitted := func() []metric.Event {
+		events := []metric.Event{}
+		for i := 0; i < fakeEmitter.EmitCallCount(); i++ {
+			_, event := fakeEmitter.EmitArgsForCall(i)
+			events = append(events, event)
+		}
+
+		return events
+	}
+
+	It("emits the latest value of each gauge once the window ends", func() {
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 1, Attributes: map[string]string{"worker": "a"}})
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 5, Attributes: map[string]string{"worker": "b"}})
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 3, Attributes: map[string]string{"worker": "a"}})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(0))
+
+		Expect(aggregating.Close()).To(Succeed())
+
+		Expect(emitted()).To(Equal([]metric.Event{
+			{Name: "containers", Type: metric.EventTypeGauge, Value: 3, Attributes: map[string]string{"worker": "a"}},
+			{Name: "containers", Type: metric.EventTypeGauge, Value: 5, Attributes: map[string]string{"worker": "b"}},
+		}))
+		Expect(fakeEmitter.CloseCallCount()).To(Equal(1))
+	})
+
+	It("can be closed more than once", func() {
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 1})
+
+		Expect(aggregating.Close()).To(Succeed())
+		Expect(aggregating.Close()).To(Succeed())
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(1))
+	})
+
+	It("sums counters", func() {
+		aggregating.EmitBatch(logger, []metric.Event{
+			{Name: "builds started", Type: metric.EventTypeCounter, Value: 1},
+			{Name: "builds started", Type: metric.EventTypeCounter, Value: 2},
+			{Name: "checks", Type: metric.EventTypeCounter, Value: 1},
+			{Name: "checks", Type: metric.EventTypeCounter, Value: 0.5},
+		})
+
+		Expect(aggregating.Close()).To(Succeed())
+
+		Expect(emitted()).To(Equal([]metric.Event{
+			{Name: "builds started", Type: metric.EventTypeCounter, Value: int64(3)},
+			{Name: "checks", Type: metric.EventTypeCounter, Value: 1.5},
+		}))
+	})
+
+	It("passes on timers as they come", func() {
+		aggregating.Emit(logger, metric.Event{Name: "build duration", Type: metric.EventTypeTimer, Value: 10})
+		aggregating.Emit(logger, metric.Event{Name: "build duration", Type: metric.EventTypeTimer, Value: 20})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(2))
+
+		Expect(aggregating.Close()).To(Succeed())
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(2))
+	})
+
+	It("flushes every interval", func() {
+		aggregating = metric.NewAggregatingEmitter(fakeEmitter, 20*time.Millisecond)
+		defer aggregating.Close()
+
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 1})
+		Eventually(fakeEmitter.EmitCallCount).Should(Equal(1))
+
+		aggregating.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 2})
+		Eventually(fakeEmitter.EmitCallCount).Should(Equal(2))
+	})
+})
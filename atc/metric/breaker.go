@@ -0,0 +1,196 @@
+package metric
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+)
+
+// ErrCircuitOpen is returned instead of attempting to send an event while a
+// circuit breaker is open. It is transient, so that the event can be spooled.
+var ErrCircuitOpen = TransientError{Err: errors.New("circuit breaker is open")}
+
+// CircuitState is the state of a CircuitBreakerEmitter, emitted as the value
+// of the "emitter circuit breaker state" gauge.
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = 0
+	CircuitHalfOpen CircuitState = 1
+	CircuitOpen     CircuitState = 2
+)
+
+func (state CircuitState) String() string {
+	switch state {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerEmitter stops sending events to a backend which is down.
+// After a number of consecutive transient failures it opens, failing events
+// with ErrCircuitOpen without trying to send them. Once the cooldown has
+// passed it lets a single event through to probe the backend, closing again
+// if it was sent.
+type CircuitBreakerEmitter struct {
+	FallibleEmitter
+
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	lock     sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func NewCircuitBreakerEmitter(emitter FallibleEmitter, name string, threshold int, cooldown time.Duration) *CircuitBreakerEmitter {
+	return &CircuitBreakerEmitter{
+		FallibleEmitter: emitter,
+
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+func (emitter *CircuitBreakerEmitter) Emit(logger lager.Logger, event Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil && err != ErrCircuitOpen {
+		logger.Error("failed-to-send-metric", errors.Wrap(ErrFailedToEmit, err.Error()), lager.Data{
+			"metric-name": event.Name,
+		})
+	}
+}
+
+func (emitter *CircuitBreakerEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	if !canTryBatch(emitter.FallibleEmitter) {
+		for _, event := range events {
+			emitter.Emit(logger, event)
+		}
+
+		return
+	}
+
+	err := emitter.TryEmitBatch(logger, events)
+	if err != nil && err != ErrCircuitOpen {
+		logger.Error("failed-to-send-metrics", errors.Wrap(ErrFailedToEmit, err.Error()), lager.Data{
+			"metrics": len(events),
+		})
+	}
+}
+
+func (emitter *CircuitBreakerEmitter) TryEmit(logger lager.Logger, event Event) error {
+	if !emitter.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := emitter.FallibleEmitter.TryEmit(logger, event)
+	emitter.record(logger, err)
+
+	return err
+}
+
+// TryEmitBatch counts a batch which failed to be sent as a whole as a single
+// failure. Batches are only sent as a whole if the wrapped emitter does so;
+// otherwise each event passes the circuit breaker on its own.
+func (emitter *CircuitBreakerEmitter) TryEmitBatch(logger lager.Logger, events []Event) error {
+	if !canTryBatch(emitter.FallibleEmitter) {
+		return tryEmitBatch(logger, emitter, events)
+	}
+
+	if !emitter.allow() {
+		return ErrCircuitOpen
+	}
+
+	err := tryEmitBatch(logger, emitter.FallibleEmitter, events)
+	emitter.record(logger, err)
+
+	return err
+}
+
+// State returns the current state of the circuit breaker.
+func (emitter *CircuitBreakerEmitter) State() CircuitState {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+
+	return emitter.state
+}
+
+// record opens the circuit breaker once sending has failed for the threshold
+// of times in a row, or the probe failed, and closes it once sending succeeds.
+func (emitter *CircuitBreakerEmitter) record(logger lager.Logger, err error) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+
+	if err != nil && IsTransient(err) {
+		emitter.failures++
+
+		if emitter.state == CircuitHalfOpen || emitter.failures >= emitter.threshold {
+			if emitter.state != CircuitOpen {
+				logger.Info("circuit-breaker-opened", lager.Data{
+					"emitter":  emitter.name,
+					"failures": emitter.failures,
+					"cooldown": emitter.cooldown.String(),
+				})
+			}
+
+			emitter.state = CircuitOpen
+			emitter.openedAt = time.Now()
+		}
+
+		return
+	}
+
+	if emitter.state != CircuitClosed {
+		logger.Info("circuit-breaker-closed", lager.Data{
+			"emitter": emitter.name,
+		})
+	}
+
+	emitter.state = CircuitClosed
+	emitter.failures = 0
+}
+
+// allow returns whether an event may be sent, half-opening the circuit
+// breaker if it has been open for the cooldown. While half-open, only the
+// probing event is sent.
+func (emitter *CircuitBreakerEmitter) allow() bool {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+
+	switch emitter.state {
+	case CircuitOpen:
+		if time.Since(emitter.openedAt) < emitter.cooldown {
+			return false
+		}
+
+		emitter.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// circuitBreakerStates returns the state of the circuit breaker of each
+// configured emitter which has one.
+func circuitBreakerStates() map[string]CircuitState {
+	emissionsLock.RLock()
+	defer emissionsLock.RUnlock()
+
+	states := map[string]CircuitState{}
+	for _, breaker := range circuitBreakers {
+		states[breaker.name] = breaker.State()
+	}
+
+	return states
+}
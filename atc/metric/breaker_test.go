@@ -0,0 +1,107 @@
+package metric_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CircuitBreakerEmitter", func() {
+	var (
+		fallible *fallibleEmitter
+		logger   *lagertest.TestLogger
+		breaker  *metric.CircuitBreakerEmitter
+	)
+
+	BeforeEach(func() {
+		fallible = &fallibleEmitter{}
+		logger = lagertest.NewTestLogger("test")
+		breaker = metric.NewCircuitBreakerEmitter(fallible, "datadog", 2, 50*time.Millisecond)
+	})
+
+	unavailable := metric.TransientError{Err: errors.New("unavailable")}
+
+	It("opens after consecutive transient failures", func() {
+		fallible.errs = []error{unavailable, unavailable}
+
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Equal(unavailable))
+		Expect(breaker.State()).To(Equal(metric.CircuitClosed))
+
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Equal(unavailable))
+		Expect(breaker.State()).To(Equal(metric.CircuitOpen))
+		Expect(logger.LogMessages()).To(ContainElement("test.circuit-breaker-opened"))
+
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Equal(metric.ErrCircuitOpen))
+		Expect(fallible.attempts).To(Equal(2))
+	})
+
+	It("does not count other errors", func() {
+		fallible.errs = []error{unavailable, errors.New("bad request"), unavailable}
+
+		for i := 0; i < 3; i++ {
+			breaker.TryEmit(logger, metric.Event{Name: "build started"})
+		}
+
+		Expect(breaker.State()).To(Equal(metric.CircuitClosed))
+	})
+
+	It("closes once a probe succeeds after the cooldown", func() {
+		fallible.errs = []error{unavailable, unavailable}
+
+		breaker.TryEmit(logger, metric.Event{Name: "build started"})
+		breaker.TryEmit(logger, metric.Event{Name: "build started"})
+		Expect(breaker.State()).To(Equal(metric.CircuitOpen))
+
+		time.Sleep(60 * time.Millisecond)
+
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Succeed())
+		Expect(breaker.State()).To(Equal(metric.CircuitClosed))
+		Expect(logger.LogMessages()).To(ContainElement("test.circuit-breaker-closed"))
+	})
+
+	It("opens again when a probe fails", func() {
+		fallible.errs = []error{unavailable, unavailable, unavailable}
+
+		breaker.TryEmit(logger, metric.Event{Name: "build started"})
+		breaker.TryEmit(logger, metric.Event{Name: "build started"})
+
+		time.Sleep(60 * time.Millisecond)
+
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Equal(unavailable))
+		Expect(breaker.State()).To(Equal(metric.CircuitOpen))
+		Expect(breaker.TryEmit(logger, metric.Event{Name: "build started"})).To(Equal(metric.ErrCircuitOpen))
+	})
+
+	It("does not log events skipped while open", func() {
+		fallible.errs = []error{unavailable, unavailable}
+
+		breaker.Emit(logger, metric.Event{Name: "build started"})
+		breaker.Emit(logger, metric.Event{Name: "build started"})
+
+		logger = lagertest.NewTestLogger("test")
+		breaker.Emit(logger, metric.Event{Name: "build started"})
+
+		Expect(logger.LogMessages()).To(BeEmpty())
+	})
+
+	It("counts a batch which fails as a whole as a single failure", func() {
+		batching := &fallibleBatchEmitter{}
+		batching.errs = []error{unavailable}
+
+		breaker = metric.NewCircuitBreakerEmitter(batching, "influxdb", 2, 50*time.Millisecond)
+
+		breaker.EmitBatch(logger, []metric.Event{
+			{Name: "build started"},
+			{Name: "build finished"},
+		})
+
+		Expect(batching.attempts).To(Equal(1))
+		Expect(breaker.State()).To(Equal(metric.CircuitClosed))
+		Expect(logger.LogMessages()).To(ContainElement("test.failed-to-send-metrics"))
+	})
+})
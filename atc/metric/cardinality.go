@@ -0,0 +1,115 @@
+package metric
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// CardinalityOverflow replaces the values of attributes whose key has seen
+// too many distinct values.
+const CardinalityOverflow = "overflow"
+
+// CardinalityGuardEmitter protects backends which charge per series from
+// attributes with unbounded values, e.g. IDs. It tracks the distinct values
+// seen for each attribute key, and once a key has more than the maximum,
+// replaces all of its values with CardinalityOverflow. The values seen are
+// forgotten every reset interval, so that a key which only briefly had too
+// many values recovers.
+type CardinalityGuardEmitter struct {
+	Emitter
+
+	max   int
+	reset time.Duration
+
+	seen       map[string]map[string]struct{}
+	overflowed map[string]bool
+	resetAt    time.Time
+	seenL      sync.Mutex
+}
+
+func NewCardinalityGuardEmitter(emitter Emitter, max int, reset time.Duration) *CardinalityGuardEmitter {
+	return &CardinalityGuardEmitter{
+		Emitter: emitter,
+
+		max:   max,
+		reset: reset,
+
+		seen:       map[string]map[string]struct{}{},
+		overflowed: map[string]bool{},
+		resetAt:    time.Now().Add(reset),
+	}
+}
+
+func (emitter *CardinalityGuardEmitter) Emit(logger lager.Logger, event Event) {
+	event.Attributes = emitter.attributes(logger, event)
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *CardinalityGuardEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	guarded := make([]Event, len(events))
+	for i, event := range events {
+		event.Attributes = emitter.attributes(logger, event)
+		guarded[i] = event
+	}
+
+	EmitBatch(logger, emitter.Emitter, guarded)
+}
+
+func (emitter *CardinalityGuardEmitter) attributes(logger lager.Logger, event Event) map[string]string {
+	if len(event.Attributes) == 0 {
+		return event.Attributes
+	}
+
+	emitter.seenL.Lock()
+	defer emitter.seenL.Unlock()
+
+	if emitter.reset > 0 && !time.Now().Before(emitter.resetAt) {
+		emitter.seen = map[string]map[string]struct{}{}
+		emitter.overflowed = map[string]bool{}
+		emitter.resetAt = time.Now().Add(emitter.reset)
+	}
+
+	var result map[string]string
+	for key, value := range event.Attributes {
+		if !emitter.overflowed[key] {
+			values, found := emitter.seen[key]
+			if !found {
+				values = map[string]struct{}{}
+				emitter.seen[key] = values
+			}
+
+			values[value] = struct{}{}
+
+			if len(values) <= emitter.max {
+				continue
+			}
+
+			logger.Info("metric-tag-cardinality-exceeded", lager.Data{
+				"tag":             key,
+				"metric-name":     event.Name,
+				"max-cardinality": emitter.max,
+			})
+
+			emitter.overflowed[key] = true
+			delete(emitter.seen, key)
+		}
+
+		// copy the attributes rather than modify those of the caller
+		if result == nil {
+			result = make(map[string]string, len(event.Attributes))
+			for k, v := range event.Attributes {
+				result[k] = v
+			}
+		}
+
+		result[key] = CardinalityOverflow
+	}
+
+	if result == nil {
+		return event.Attributes
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+package metric_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CardinalityGuardEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		logger      *lagertest.TestLogger
+		guard       *metric.CardinalityGuardEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+		guard = metric.NewCardinalityGuardEmitter(fakeEmitter, 2, time.Hour)
+	})
+
+	emittedBuild := func(i int) string {
+		_, event := fakeEmitter.EmitArgsForCall(i)
+		return event.Attributes["build"]
+	}
+
+	It("replaces the values of a key once it has too many", func() {
+		for _, build := range []string{"a", "b", "a", "c", "a"} {
+			guard.Emit(logger, metric.Event{
+				Name:       "build started",
+				Attributes: map[string]string{"build": build, "team": "main"},
+			})
+		}
+
+		Expect([]string{emittedBuild(0), emittedBuild(1), emittedBuild(2), emittedBuild(3), emittedBuild(4)}).To(Equal([]string{
+			"a", "b", "a", metric.CardinalityOverflow, metric.CardinalityOverflow,
+		}))
+
+		_, event := fakeEmitter.EmitArgsForCall(4)
+		Expect(event.Attributes["team"]).To(Equal("main"))
+
+		Expect(logger.LogMessages()).To(Equal([]string{"test.metric-tag-cardinality-exceeded"}))
+	})
+
+	It("does not modify the attributes of the event", func() {
+		attributes := map[string]string{"build": "a"}
+
+		guard.EmitBatch(logger, []metric.Event{
+			{Name: "build started", Attributes: map[string]string{"build": "b"}},
+			{Name: "build started", Attributes: map[string]string{"build": "c"}},
+			{Name: "build started", Attributes: attributes},
+		})
+
+		Expect(emittedBuild(2)).To(Equal(metric.CardinalityOverflow))
+		Expect(attributes).To(Equal(map[string]string{"build": "a"}))
+	})
+
+	It("forgets the values seen every reset interval", func() {
+		guard = metric.NewCardinalityGuardEmitter(fakeEmitter, 1, 20*time.Millisecond)
+
+		guard.Emit(logger, metric.Event{Name: "build started", Attributes: map[string]string{"build": "a"}})
+		guard.Emit(logger, metric.Event{Name: "build started", Attributes: map[string]string{"build": "b"}})
+		Expect(emittedBuild(1)).To(Equal(metric.CardinalityOverflow))
+
+		time.Sleep(30 * time.Millisecond)
+
+		guard.Emit(logger, metric.Event{Name: "build started", Attributes: map[string]string{"build": "b"}})
+		Expect(emittedBuild(2)).To(Equal("b"))
+	})
+})
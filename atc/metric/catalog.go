@@ -0,0 +1,54 @@
+package metric
+
+// the metrics Concourse emits, registered here so that each is described even
+// before it is first emitted
+func init() {
+	Register("scheduling: full duration (ms)", "Time taken to schedule all the jobs of a pipeline.", UnitMilliseconds, EventTypeTimer)
+	Register("scheduling: loading versions duration (ms)", "Time taken to load the resource versions of a pipeline from the database for scheduling.", UnitMilliseconds, EventTypeTimer)
+	Register("scheduling: job duration (ms)", "Time taken to schedule a job.", UnitMilliseconds, EventTypeTimer)
+
+	Register("worker containers", "Number of containers on a worker.", UnitCount, EventTypeGauge)
+	Register("worker volumes", "Number of volumes on a worker.", UnitCount, EventTypeGauge)
+	Register("worker state", "Number of workers in a state, as seen by the database.", UnitCount, EventTypeGauge)
+
+	Register("orphaned volumes to be garbage collected", "Number of orphaned volumes found for deletion.", UnitCount, EventTypeGauge)
+	Register("creating containers to be garbage collected", "Number of containers in the creating state found for deletion.", UnitCount, EventTypeGauge)
+	Register("created containers to be garbage collected", "Number of containers in the created state found for deletion.", UnitCount, EventTypeGauge)
+	Register("destroying containers to be garbage collected", "Number of containers in the destroying state found for deletion.", UnitCount, EventTypeGauge)
+	Register("failed containers to be garbage collected", "Number of containers in the failed state found for deletion.", UnitCount, EventTypeGauge)
+	Register("created volumes to be garbage collected", "Number of volumes in the created state found for deletion.", UnitCount, EventTypeGauge)
+	Register("destroying volumes to be garbage collected", "Number of volumes in the destroying state found for deletion.", UnitCount, EventTypeGauge)
+	Register("failed volumes to be garbage collected", "Number of volumes in the failed state found for deletion.", UnitCount, EventTypeGauge)
+	Register("GC container collector job dropped", "Number of times destroying the containers of a worker was skipped because it was already in progress.", UnitCount, EventTypeCounter)
+
+	Register("build started", "ID of a build which started.", "", EventTypeGauge)
+	Register("builds started", "Number of builds started.", UnitCount, EventTypeCounter)
+	Register("build finished", "Duration of a finished build.", UnitMilliseconds, EventTypeTimer)
+	Register("error log", "Number of errors logged.", UnitCount, EventTypeCounter)
+	Register("http response time", "Time taken to respond to an API request.", UnitMilliseconds, EventTypeTimer)
+	Register("resource checked", "Number of resource checks performed.", UnitCount, EventTypeCounter)
+	Register("lock held", "Whether a database lock of a type is held, 1 if it is and 0 otherwise.", "", EventTypeGauge)
+
+	Register("database queries", "Number of database queries made.", UnitCount, EventTypeCounter)
+	Register("database connections", "Number of open connections to the database.", UnitCount, EventTypeGauge)
+	Register("database connectivity", "Whether the database can be reached.", "", EventTypeServiceCheck)
+
+	Register("containers created", "Number of containers created.", UnitCount, EventTypeCounter)
+	Register("containers deleted", "Number of containers deleted.", UnitCount, EventTypeCounter)
+	Register("failed containers", "Number of containers which failed to be created.", UnitCount, EventTypeCounter)
+	Register("volumes created", "Number of volumes created.", UnitCount, EventTypeCounter)
+	Register("volumes deleted", "Number of volumes deleted.", UnitCount, EventTypeCounter)
+	Register("failed volumes", "Number of volumes which failed to be created.", UnitCount, EventTypeCounter)
+
+	Register("dropped events", "Number of metric events dropped because the emission queue was full.", UnitCount, EventTypeCounter)
+	Register("emitted events", "Number of metric events passed on to the emitters.", UnitCount, EventTypeCounter)
+	Register("emission queue depth", "Number of metric events waiting to be emitted.", UnitCount, EventTypeGauge)
+	Register("emit errors", "Number of metric events an emitter failed to emit.", UnitCount, EventTypeCounter)
+	Register("rate limited events", "Number of metric events an emitter dropped because of its rate limit.", UnitCount, EventTypeCounter)
+	Register("emitter circuit breaker state", "State of the circuit breaker of an emitter: 0 when closed, 1 when half-open and 2 when open.", "", EventTypeGauge)
+
+	Register("gc pause total duration", "Total time spent in Go garbage collection pauses.", UnitNanoseconds, EventTypeGauge)
+	Register("mallocs", "Total number of heap objects allocated.", UnitCount, EventTypeGauge)
+	Register("frees", "Total number of heap objects freed.", UnitCount, EventTypeGauge)
+	Register("goroutines", "Number of goroutines.", UnitCount, EventTypeGauge)
+}
@@ -0,0 +1,150 @@
+package metric
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	flags "github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v2"
+)
+
+// coreConfigSection is the section of a metrics config file which configures
+// the flags of the group itself rather than those of an emitter.
+const coreConfigSection = "metrics"
+
+// LoadConfigFile uses the YAML file at path as the defaults of the flags in
+// the group and of the emitters wired into it, so that flags given on the
+// command line or in the environment override it. It must be called before
+// parsing.
+//
+// The file has a "metrics" section for the flags of the group and a section
+// for each emitter, named like its sanitized description, e.g. "datadog" or
+// "google_pubsub". Keys name flags, with or without the section name as a
+// prefix:
+//
+//   metrics:
+//     metric-allow: [build_*]
+//   datadog:
+//     agent-host: 127.0.0.1
+//     datadog-agent-port: 8125
+func LoadConfigFile(group *flags.Group, path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var file map[string]interface{}
+	err = yaml.Unmarshal(contents, &file)
+	if err != nil {
+		return fmt.Errorf("failed to parse metrics config '%s': %s", path, err)
+	}
+
+	sections := map[string][]*flags.Option{
+		coreConfigSection: group.Options(),
+	}
+
+	for _, emitterGroup := range group.Groups() {
+		if !strings.HasPrefix(emitterGroup.ShortDescription, emitterGroupPrefix) {
+			continue
+		}
+
+		description := strings.TrimSuffix(strings.TrimPrefix(emitterGroup.ShortDescription, emitterGroupPrefix), ")")
+		sections[sanitizeName(description)] = groupOptions(emitterGroup)
+	}
+
+	for section, values := range file {
+		err := loadConfigSection(section, sections, values)
+		if err != nil {
+			return fmt.Errorf("failed to load section '%s' of metrics config '%s': %s", section, path, err)
+		}
+	}
+
+	return nil
+}
+
+func loadConfigSection(section string, sections map[string][]*flags.Option, values interface{}) error {
+	options, found := sections[section]
+	if !found {
+		return fmt.Errorf("unknown section")
+	}
+
+	keys, ok := values.(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("must be a mapping of flag names to values")
+	}
+
+	for key, value := range keys {
+		name := fmt.Sprint(key)
+
+		option := findOption(options, name, section+"-"+name)
+		if option == nil {
+			return fmt.Errorf("unknown flag '%s'", name)
+		}
+
+		defaults, err := configValues(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for '%s': %s", name, err)
+		}
+
+		option.Default = defaults
+	}
+
+	return nil
+}
+
+// groupOptions returns the options of the group and its subgroups, e.g. an
+// emitter's TLS flags.
+func groupOptions(group *flags.Group) []*flags.Option {
+	options := group.Options()
+	for _, subgroup := range group.Groups() {
+		options = append(options, groupOptions(subgroup)...)
+	}
+
+	return options
+}
+
+func findOption(options []*flags.Option, names ...string) *flags.Option {
+	for _, name := range names {
+		for _, option := range options {
+			if option.LongNameWithNamespace() == name {
+				return option
+			}
+		}
+	}
+
+	return nil
+}
+
+// configValues turns a value from the config file into the values of a flag.
+// Lists set the flag once per item and mappings once per pair, as NAME:VALUE.
+func configValues(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			switch item.(type) {
+			case []interface{}, map[interface{}]interface{}:
+				return nil, fmt.Errorf("lists must not be nested")
+			}
+
+			values = append(values, fmt.Sprint(item))
+		}
+
+		return values, nil
+	case map[interface{}]interface{}:
+		values := []string{}
+		for key, item := range v {
+			values = append(values, fmt.Sprintf("%v:%v", key, item))
+		}
+
+		sort.Strings(values)
+
+		return values, nil
+	default:
+		return []string{fmt.Sprint(v)}, nil
+	}
+}
@@ -0,0 +1,121 @@
+package metric_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/concourse/atc/metric"
+	flags "github.com/jessevdk/go-flags"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testEmitterConfig struct {
+	Host string   `long:"test-host" default:"localhost"`
+	Port int      `long:"test-port" default:"8125"`
+	Tags []string `long:"test-tag"`
+
+	TLS struct {
+		CACert string `long:"ca-cert"`
+	} `group:"Test TLS" namespace:"test"`
+}
+
+func (config *testEmitterConfig) Description() string { return "Test" }
+func (config *testEmitterConfig) FlagPrefix() string  { return "test" }
+func (config *testEmitterConfig) IsConfigured() bool  { return false }
+
+func (config *testEmitterConfig) NewEmitter() (metric.Emitter, error) {
+	return nil, nil
+}
+
+var _ = Describe("Loading a metrics config file", func() {
+	var (
+		dir    string
+		path   string
+		parser *flags.Parser
+		group  *flags.Group
+
+		core    struct{ metric.Config }
+		emitter *testEmitterConfig
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "metrics-config")
+		Expect(err).ToNot(HaveOccurred())
+
+		path = filepath.Join(dir, "metrics.yml")
+
+		core = struct{ metric.Config }{}
+		emitter = &testEmitterConfig{}
+
+		parser = flags.NewParser(&struct{}{}, flags.None)
+		parser.NamespaceDelimiter = "-"
+
+		group, err = parser.AddGroup("Metrics & Diagnostics", "", &core)
+		Expect(err).ToNot(HaveOccurred())
+
+		metric.WireEmitterFactories(group, []metric.EmitterFactory{emitter})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	writeConfig := func(contents string) {
+		Expect(ioutil.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+	}
+
+	It("uses the file as defaults which flags override", func() {
+		writeConfig(`
+metrics:
+  metric-allow: [build_*, worker_*]
+  metric-buffer-size: 50
+test:
+  host: statsd.example.com
+  test-port: 9125
+  tag: [env:prod]
+  ca-cert: /etc/ca.pem
+`)
+
+		Expect(metric.LoadConfigFile(group, path)).To(Succeed())
+
+		_, err := parser.ParseArgs([]string{"--test-port", "10125"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(core.Allow).To(Equal([]string{"build_*", "worker_*"}))
+		Expect(core.BufferSize).To(Equal(uint32(50)))
+		Expect(emitter.Host).To(Equal("statsd.example.com"))
+		Expect(emitter.Port).To(Equal(10125))
+		Expect(emitter.Tags).To(Equal([]string{"env:prod"}))
+		Expect(emitter.TLS.CACert).To(Equal("/etc/ca.pem"))
+	})
+
+	It("reports the section which failed to load", func() {
+		writeConfig(`
+test:
+  bogus: true
+`)
+
+		err := metric.LoadConfigFile(group, path)
+		Expect(err).To(MatchError(ContainSubstring("section 'test'")))
+		Expect(err).To(MatchError(ContainSubstring("unknown flag 'bogus'")))
+	})
+
+	It("rejects unknown sections", func() {
+		writeConfig(`
+nope:
+  host: foo
+`)
+
+		Expect(metric.LoadConfigFile(group, path)).To(MatchError(ContainSubstring("section 'nope'")))
+	})
+
+	It("rejects files which are not YAML", func() {
+		writeConfig("metrics: [")
+
+		Expect(metric.LoadConfigFile(group, path)).To(MatchError(ContainSubstring("failed to parse metrics config")))
+	})
+})
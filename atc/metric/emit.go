@@ -2,23 +2,125 @@ package metric
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"code.cloudfoundry.org/lager"
 
 	flags "github.com/jessevdk/go-flags"
 )
 
+// Event is a single data point. Its value is numeric: any int, uint or float
+// type, or a time.Duration, which is emitted as a timer in milliseconds.
+// Booleans are emitted as 1.0 for true and 0.0 for false, and events with a
+// nil value are dropped.
 type Event struct {
 	Name       string
 	Value      interface{}
 	State      EventState
+	Type       EventType
+	Unit       string
 	Attributes map[string]string
 	Host       string
 	Time       time.Time
 }
 
+// Timestamp returns the time at which the event occurred, treating a zero
+// time as now for events which were not emitted through the metric package.
+func (event Event) Timestamp() time.Time {
+	if event.Time.IsZero() {
+		return time.Now()
+	}
+
+	return event.Time
+}
+
+// InvalidEventError describes what is wrong with an event which cannot be
+// emitted.
+type InvalidEventError struct {
+	Name   string
+	Reason string
+}
+
+func (err InvalidEventError) Error() string {
+	return fmt.Sprintf("invalid event '%s': %s", err.Name, err.Reason)
+}
+
+// Validate returns an InvalidEventError if the event has no name or value, or
+// if any of its attribute keys are empty or contain whitespace.
+func (event Event) Validate() error {
+	if event.Name == "" {
+		return InvalidEventError{Name: event.Name, Reason: "name must not be empty"}
+	}
+
+	if event.Value == nil {
+		return InvalidEventError{Name: event.Name, Reason: "value must not be nil"}
+	}
+
+	for key := range event.Attributes {
+		if key == "" || strings.IndexFunc(key, unicode.IsSpace) != -1 {
+			return InvalidEventError{
+				Name:   event.Name,
+				Reason: fmt.Sprintf("attribute key '%s' must not be empty or contain whitespace", key),
+			}
+		}
+	}
+
+	return nil
+}
+
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case int:
+		return v == 0
+	case int8:
+		return v == 0
+	case int16:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case uint:
+		return v == 0
+	case uint8:
+		return v == 0
+	case uint16:
+		return v == 0
+	case uint32:
+		return v == 0
+	case uint64:
+		return v == 0
+	case float32:
+		return v == 0
+	case float64:
+		return v == 0
+	case time.Duration:
+		return v == 0
+	default:
+		return false
+	}
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+// Units of event values. Emitters which have no notion of units ignore them.
+const (
+	UnitMilliseconds = "ms"
+	UnitNanoseconds  = "ns"
+	UnitBytes        = "bytes"
+	UnitCount        = "count"
+)
+
 type EventState string
 
 const (
@@ -27,33 +129,175 @@ const (
 	EventStateCritical EventState = "critical"
 )
 
+// EventType tells emitters how to aggregate an event's value. Events emitted
+// without a type are gauges.
+type EventType string
+
+const (
+	// EventTypeGauge is a value sampled at a point in time.
+	EventTypeGauge EventType = "gauge"
+
+	// EventTypeCounter is an amount to accumulate, e.g. one per occurrence or
+	// the delta since the last emission.
+	EventTypeCounter EventType = "counter"
+
+	// EventTypeTimer is a duration in milliseconds. Events with a
+	// time.Duration value are always timers.
+	EventTypeTimer EventType = "timer"
+
+	// EventTypeServiceCheck reports the health of a component through its
+	// state. The value is 1 when healthy and 0 otherwise.
+	EventTypeServiceCheck EventType = "service check"
+)
+
 //go:generate counterfeiter . Emitter
 type Emitter interface {
 	Emit(lager.Logger, Event)
+
+	// Close releases any resources held by the emitter, flushing events which
+	// have yet to be sent.
+	Close() error
 }
 
+// BatchEmitter can be implemented by emitters which send several events at
+// once more efficiently than one at a time.
+type BatchEmitter interface {
+	Emitter
+
+	EmitBatch(lager.Logger, []Event)
+}
+
+// EmitBatch emits the events through the emitter's EmitBatch if it has one and
+// through Emit otherwise.
+func EmitBatch(logger lager.Logger, emitter Emitter, events []Event) {
+	if batchEmitter, ok := emitter.(BatchEmitter); ok {
+		batchEmitter.EmitBatch(logger, events)
+		return
+	}
+
+	for _, event := range events {
+		emitter.Emit(logger, event)
+	}
+}
+
+// NopCloser can be embedded by emitters which hold no resources to implement
+// Close.
+type NopCloser struct{}
+
+func (NopCloser) Close() error { return nil }
+
+// emitterCloseTimeout bounds how long shutdown waits for an emitter to flush.
+const emitterCloseTimeout = 10 * time.Second
+
+// maxEmitBatchSize bounds how many queued events are passed to a BatchEmitter
+// at once.
+const maxEmitBatchSize = 100
+
 //go:generate counterfeiter . EmitterFactory
 type EmitterFactory interface {
 	Description() string
+
+	// FlagPrefix is the prefix of the emitter's flags, e.g. "datadog" for
+	// --datadog-agent-host, under which its shared flags are wired too.
+	FlagPrefix() string
+
 	IsConfigured() bool
 	NewEmitter() (Emitter, error)
 }
 
+// EmitterActivator is implemented by factories whose configuration also takes
+// effect outside of the emitter they build, e.g. on the API. Activate is only
+// called once that emitter has been swapped in, so that a failed reload leaves
+// the current configuration in effect.
+type EmitterActivator interface {
+	EmitterFactory
+
+	Activate()
+}
+
+func activate(factories []EmitterFactory) {
+	for _, factory := range factories {
+		if activator, ok := factory.(EmitterActivator); ok && factory.IsConfigured() {
+			activator.Activate()
+		}
+	}
+}
+
 var emitterFactories []EmitterFactory
 
 func RegisterEmitter(factory EmitterFactory) {
 	emitterFactories = append(emitterFactories, factory)
 }
 
+// emitterGroupPrefix starts the description of the group of flags of each
+// emitter, e.g. "Metric Emitter (Datadog)".
+const emitterGroupPrefix = "Metric Emitter ("
+
 func WireEmitters(group *flags.Group) {
-	for _, factory := range emitterFactories {
-		_, err := group.AddGroup(fmt.Sprintf("Metric Emitter (%s)", factory.Description()), "", factory)
+	WireEmitterFactories(group, emitterFactories)
+}
+
+// WireEmitterFactories adds a group of flags for each of the factories, like
+// WireEmitters does for the registered ones.
+func WireEmitterFactories(group *flags.Group, factories []EmitterFactory) {
+	for _, factory := range factories {
+		emitterGroup, err := group.AddGroup(emitterGroupPrefix+factory.Description()+")", "", factory)
 		if err != nil {
 			panic(err)
 		}
+
+		limits := &rateLimitFlags{}
+
+		limitGroup, err := emitterGroup.AddGroup(factory.Description()+" Rate Limit", "", limits)
+		if err != nil {
+			panic(err)
+		}
+
+		limitGroup.Namespace = factory.FlagPrefix()
+
+		emitterRateLimits[factory] = limits
 	}
 }
 
+// Config configures how events are processed before being emitted.
+type Config struct {
+	Allow []string `long:"metric-allow" description:"Only emit metrics whose name matches the glob, e.g. 'build_*'. Names are lowercased with spaces replaced by underscores. Can be specified multiple times." value-name:"GLOB"`
+	Deny  []string `long:"metric-deny" description:"Do not emit metrics whose name matches the glob. Takes precedence over --metric-allow. Can be specified multiple times." value-name:"GLOB"`
+
+	Renames []string `long:"metric-rename" description:"Rename a metric before it is emitted, e.g. 'build finished=ci build duration'. When both names end in '*', the prefix of any matching name is replaced instead. Can be specified multiple times." value-name:"OLD=NEW"`
+
+	TagRenames   []string `long:"metric-tag-rename" description:"Rename a metric attribute before it is emitted, e.g. 'job=service'. Can be specified multiple times." value-name:"OLD=NEW"`
+	TagNormalize bool     `long:"metric-tag-normalize" description:"Lowercase metric attribute names and drop attributes with empty values."`
+	TagsFromEnv  []string `long:"metric-tag-from-env" description:"Attach the value of an environment variable, read at startup, as an attribute to all metrics, e.g. 'cluster=CLUSTER_NAME'. Attributes given with --metrics-attribute take precedence. Can be specified multiple times." value-name:"NAME=ENV_VAR"`
+
+	MaxCardinality           int           `long:"metric-max-cardinality" description:"Number of distinct values of a metric attribute after which its values are replaced with 'overflow'. 0 disables the limit."`
+	CardinalityResetInterval time.Duration `long:"metric-cardinality-reset-interval" default:"1h" description:"How often to forget the values seen for each metric attribute, so that attributes which exceeded --metric-max-cardinality are emitted again."`
+
+	DropZeros bool `long:"metric-drop-zeros" description:"Do not emit events whose value is zero. Counters are still emitted, as a count of zero matters for rates."`
+
+	AggregateInterval time.Duration `long:"metric-aggregate-interval" description:"Window within which to collapse gauges with the same name and attributes to their latest value and to sum counters. Timers are emitted unchanged. 0 disables aggregation."`
+
+	Percentiles      []float64     `long:"metric-percentiles" default:"50" default:"90" default:"95" default:"99" description:"Percentile of timers to emit as a gauge, along with their maximum, when --metric-percentile-window is set. Can be specified multiple times." value-name:"PERCENTILE"`
+	PercentileWindow time.Duration `long:"metric-percentile-window" description:"Window over which to compute the percentiles of each timer, for emitters without histograms. These get gauges of the percentiles instead of the timers, while emitters with histograms, e.g. Prometheus and Datadog, get the timers as they are. 0 disables percentiles."`
+
+	BufferSize uint32 `long:"metric-buffer-size" default:"1000" description:"Number of events to queue for the emitter. Events are dropped while the queue is full."`
+
+	SampleRate float64  `long:"metric-sample-rate" default:"1" description:"Share of the events of each metric to emit, greater than 0 and at most 1. Counters are scaled up to make up for the dropped events."`
+	Samples    []string `long:"metric-sample" description:"Sample rate for a metric, overriding --metric-sample-rate. Can be specified multiple times." value-name:"NAME=RATE"`
+
+	RetryMax       int           `long:"metric-retry-max" default:"3" description:"Number of attempts at emitting an event which failed with a transient error, for emitters which report errors. 1 disables retries."`
+	RetryBaseDelay time.Duration `long:"metric-retry-base-delay" default:"100ms" description:"Delay before the first retry of an event. Later retries back off exponentially, with jitter."`
+
+	BreakerThreshold int           `long:"metric-breaker-threshold" description:"Number of consecutive transient failures after which an emitter which reports errors stops trying to send events. 0 disables the circuit breaker."`
+	BreakerCooldown  time.Duration `long:"metric-breaker-cooldown" default:"30s" description:"How long to skip an emitter for once its circuit breaker opened, before probing whether its backend recovered."`
+
+	SpoolDir   string `long:"metric-spool-dir" description:"Directory to spool events to while an emitter which reports errors cannot reach its backend. The events are emitted once it recovers, including after a restart."`
+	SpoolMaxMB int    `long:"metric-spool-max-mb" default:"100" description:"Size in megabytes of each emitter's spool. The oldest events are dropped once full."`
+}
+
+// defaultBufferSize is used when no buffer size is configured.
+const defaultBufferSize = 1000
+
 type eventEmission struct {
 	event  Event
 	logger lager.Logger
@@ -64,58 +308,326 @@ var (
 	eventHost       string
 	eventAttributes map[string]string
 	emissions       chan eventEmission
+	emitLoopDone    chan struct{}
+	toggledEmitters []*toggledEmitter
+	circuitBreakers []*CircuitBreakerEmitter
+	dropZeros       bool
+
+	// guards against emitting while the emitter is being deinitialized
+	emissionsLock sync.RWMutex
 )
 
-func Initialize(logger lager.Logger, host string, attributes map[string]string) error {
+func Initialize(logger lager.Logger, host string, attributes map[string]string, config Config) error {
+	attributes, err := attributesFromEnv(logger, attributes, config.TagsFromEnv)
+	if err != nil {
+		return err
+	}
+
+	configured, err := buildEmitter(logger, emitterFactories, config)
+	if err != nil {
+		return err
+	}
+
+	if configured.emitter == nil {
+		return nil
+	}
+
+	emissionsLock.Lock()
+	defer emissionsLock.Unlock()
+
+	startEmitting(configured, host, attributes, config)
+	activate(emitterFactories)
+
+	return nil
+}
+
+// configuredEmitter is the emitter built from the configuration, along with
+// the parts of it which can be inspected and controlled at runtime.
+type configuredEmitter struct {
+	emitter  Emitter
+	toggled  []*toggledEmitter
+	breakers []*CircuitBreakerEmitter
+}
+
+// buildEmitter builds an emitter from each configured factory and wraps them
+// as configured. The emitter is nil if no factory is configured.
+func buildEmitter(logger lager.Logger, factories []EmitterFactory, config Config) (configuredEmitter, error) {
 	var (
 		emitterDescriptions []string
-		err                 error
+		emitters            []Emitter
+		configured          configuredEmitter
 	)
 
-	for _, factory := range emitterFactories {
+	for _, factory := range factories {
 		if factory.IsConfigured() {
+			child, err := factory.NewEmitter()
+			if err != nil {
+				NewMultiEmitter(emitters...).Close()
+				return configuredEmitter{}, err
+			}
+
+			raw := child
+
+			if fallible, ok := child.(FallibleEmitter); ok {
+				if config.RetryMax > 1 {
+					fallible = NewRetryingEmitter(fallible, config.RetryMax, config.RetryBaseDelay)
+				}
+
+				if config.BreakerThreshold > 0 {
+					breaker := NewCircuitBreakerEmitter(fallible, factory.FlagPrefix(), config.BreakerThreshold, config.BreakerCooldown)
+					configured.breakers = append(configured.breakers, breaker)
+					fallible = breaker
+				}
+
+				child = fallible
+
+				if config.SpoolDir != "" {
+					child, err = NewSpoolingEmitter(
+						logger.Session("spool"),
+						fallible,
+						filepath.Join(config.SpoolDir, sanitizeName(factory.Description())+".spool"),
+						int64(config.SpoolMaxMB)*1024*1024,
+					)
+					if err != nil {
+						NewMultiEmitter(append(emitters, fallible)...).Close()
+						return configuredEmitter{}, err
+					}
+				}
+			}
+
+			if limits, found := emitterRateLimits[factory]; found && limits.MaxPerSecond > 0 {
+				child = NewRateLimitedEmitter(child, factory.FlagPrefix(), limits.MaxPerSecond)
+			}
+
+			// emitters which keep the distribution of timers themselves get the
+			// timers as they are
+			if config.PercentileWindow > 0 && !emitsHistograms(raw) {
+				percentiles, err := NewPercentileEmitter(child, config.Percentiles, config.PercentileWindow)
+				if err != nil {
+					NewMultiEmitter(append(emitters, child)...).Close()
+					return configuredEmitter{}, err
+				}
+
+				child = percentiles
+
+				logger.Info("computing-metric-percentiles", lager.Data{
+					"emitter":     factory.Description(),
+					"percentiles": config.Percentiles,
+					"window":      config.PercentileWindow.String(),
+				})
+			}
+
+			toggle := newToggledEmitter(child, factory.Description())
+
 			emitterDescriptions = append(emitterDescriptions, factory.Description())
+			emitters = append(emitters, toggle)
+			configured.toggled = append(configured.toggled, toggle)
 		}
 	}
-	if len(emitterDescriptions) > 1 {
-		return fmt.Errorf("Multiple emitters configured: %s", strings.Join(emitterDescriptions, ", "))
+
+	switch len(emitters) {
+	case 0:
+		return configuredEmitter{}, nil
+	case 1:
+		configured.emitter = emitters[0]
+	default:
+		configured.emitter = NewMultiEmitter(emitters...)
+
+		logger.Info("emitting-to-multiple-emitters", lager.Data{
+			"emitters": emitterDescriptions,
+		})
 	}
 
-	for _, factory := range emitterFactories {
-		if factory.IsConfigured() {
-			emitter, err = factory.NewEmitter()
-			if err != nil {
-				return err
-			}
+	err := configured.decorate(logger, config)
+	if err != nil {
+		configured.emitter.Close()
+		return configuredEmitter{}, err
+	}
+
+	return configured, nil
+}
+
+func (configured *configuredEmitter) decorate(logger lager.Logger, config Config) error {
+	var err error
+
+	// aggregation comes last, along with the percentiles computed for each
+	// emitter, so that events are grouped by the names and attributes they are
+	// emitted with
+	if config.AggregateInterval > 0 {
+		configured.emitter = NewAggregatingEmitter(configured.emitter, config.AggregateInterval)
+
+		logger.Info("aggregating-metrics", lager.Data{
+			"interval": config.AggregateInterval.String(),
+		})
+	}
+
+	// the cardinality of attributes is tracked under the keys they are emitted
+	// with
+	if config.MaxCardinality > 0 {
+		configured.emitter = NewCardinalityGuardEmitter(configured.emitter, config.MaxCardinality, config.CardinalityResetInterval)
+
+		logger.Info("guarding-metric-tag-cardinality", lager.Data{
+			"max-cardinality": config.MaxCardinality,
+			"reset-interval":  config.CardinalityResetInterval.String(),
+		})
+	}
+
+	if len(config.TagRenames) > 0 || config.TagNormalize {
+		configured.emitter, err = NewTagEmitter(configured.emitter, config.TagRenames, config.TagNormalize)
+		if err != nil {
+			return err
 		}
+
+		logger.Info("remapping-metric-tags", lager.Data{
+			"renames":   config.TagRenames,
+			"normalize": config.TagNormalize,
+		})
 	}
 
-	if emitter == nil {
-		return nil
+	// renaming comes after filtering and sampling, whose rules refer to the
+	// names Concourse emits
+	if len(config.Renames) > 0 {
+		configured.emitter, err = NewRenamingEmitter(configured.emitter, config.Renames)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("renaming-metrics", lager.Data{
+			"renames": config.Renames,
+		})
 	}
 
-	emitter = emitter
-	eventHost = host
-	eventAttributes = attributes
-	emissions = make(chan eventEmission, 1000)
+	if (config.SampleRate != 0 && config.SampleRate != 1) || len(config.Samples) > 0 {
+		sampleRate := config.SampleRate
+		if sampleRate == 0 {
+			sampleRate = 1
+		}
+
+		configured.emitter, err = NewSamplingEmitter(configured.emitter, sampleRate, config.Samples)
+		if err != nil {
+			return err
+		}
 
-	go emitLoop()
+		logger.Info("sampling-metrics", lager.Data{
+			"sample-rate": sampleRate,
+			"samples":     config.Samples,
+		})
+	}
+
+	if len(config.Allow) > 0 || len(config.Deny) > 0 {
+		configured.emitter, err = NewFilterEmitter(configured.emitter, config.Allow, config.Deny)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("filtering-metrics", lager.Data{
+			"allow": config.Allow,
+			"deny":  config.Deny,
+		})
+	}
 
 	return nil
 }
 
+// startEmitting makes the emitter the current one and starts emitting the
+// events queued for it. It must be called with emissionsLock held.
+func startEmitting(configured configuredEmitter, host string, attributes map[string]string, config Config) {
+	bufferSize := config.BufferSize
+	if bufferSize == 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	emitter = configured.emitter
+	toggledEmitters = configured.toggled
+	circuitBreakers = configured.breakers
+	dropZeros = config.DropZeros
+	eventHost = host
+	eventAttributes = attributes
+	emissions = make(chan eventEmission, bufferSize)
+	emitLoopDone = make(chan struct{})
+
+	go emitLoop(emitter, emissions, emitLoopDone)
+}
+
+// Deinitialize stops accepting events, waits for the queued ones to be emitted
+// and closes the emitter.
 func Deinitialize(logger lager.Logger) {
-	close(emissions)
+	emissionsLock.Lock()
+	closingEmitter := emitter
+	if emitter != nil {
+		close(emissions)
+		emitter = nil
+		toggledEmitters = nil
+		circuitBreakers = nil
+		dropZeros = false
+	}
+	emissionsLock.Unlock()
+
 	emitterFactories = nil
+
+	if closingEmitter == nil {
+		return
+	}
+
+	<-emitLoopDone
+
+	closeEmitter(logger, closingEmitter)
+}
+
+// closeEmitter closes the emitter, giving up on it after emitterCloseTimeout.
+func closeEmitter(logger lager.Logger, emitter Emitter) {
+	closed := make(chan error, 1)
+	go func() {
+		closed <- emitter.Close()
+	}()
+
+	select {
+	case err := <-closed:
+		if err != nil {
+			logger.Error("failed-to-close-emitter", err)
+		}
+	case <-time.After(emitterCloseTimeout):
+		logger.Info("timed-out-closing-emitter")
+	}
 }
 
 func emit(logger lager.Logger, event Event) {
+	emissionsLock.RLock()
+	defer emissionsLock.RUnlock()
+
 	if emitter == nil {
 		return
 	}
 
 	event.Host = eventHost
-	event.Time = time.Now()
+
+	// keep the time of events which were created before being emitted
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	switch value := event.Value.(type) {
+	case nil:
+		logger.Debug("dropping-event-without-value", lager.Data{
+			"metric-name": event.Name,
+		})
+		return
+	case bool:
+		event.Value = boolValue(value)
+	case time.Duration:
+		event.Type = EventTypeTimer
+		event.Unit = UnitMilliseconds
+	}
+
+	if event.Type == "" {
+		event.Type = EventTypeGauge
+	}
+
+	// a count of zero still matters for rates, so counters are always emitted
+	if dropZeros && event.Type != EventTypeCounter && isZero(event.Value) {
+		SuppressedZeros.Inc()
+		return
+	}
 
 	mergedAttributes := map[string]string{}
 	for k, v := range eventAttributes {
@@ -130,15 +642,62 @@ func emit(logger lager.Logger, event Event) {
 
 	event.Attributes = mergedAttributes
 
+	err := event.Validate()
+	if err != nil {
+		logger.Error("invalid-event", err)
+		return
+	}
+
 	select {
 	case emissions <- eventEmission{logger: logger, event: event}:
 	default:
+		DroppedEvents.Inc()
 		logger.Error("queue-full", nil)
 	}
 }
 
-func emitLoop() {
+// queueDepth returns the number of events waiting to be emitted.
+func queueDepth() int {
+	emissionsLock.RLock()
+	defer emissionsLock.RUnlock()
+
+	if emitter == nil {
+		return 0
+	}
+
+	return len(emissions)
+}
+
+func emitLoop(emitter Emitter, emissions chan eventEmission, done chan struct{}) {
+	defer close(done)
+
+	batchEmitter, canBatch := emitter.(BatchEmitter)
+
 	for emission := range emissions {
-		emitter.Emit(emission.logger.Session("emit"), emission.event)
+		if !canBatch {
+			emitter.Emit(emission.logger.Session("emit"), emission.event)
+			EmittedEvents.Inc()
+			continue
+		}
+
+		// pass along whatever else has been queued in the meantime
+		events := []Event{emission.event}
+
+	dequeue:
+		for len(events) < maxEmitBatchSize {
+			select {
+			case next, ok := <-emissions:
+				if !ok {
+					break dequeue
+				}
+
+				events = append(events, next.event)
+			default:
+				break dequeue
+			}
+		}
+
+		batchEmitter.EmitBatch(emission.logger.Session("emit"), events)
+		EmittedEvents.IncDelta(len(events))
 	}
 }
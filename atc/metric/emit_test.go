@@ -0,0 +1,426 @@
+package metric_test
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Event", func() {
+	Describe("Timestamp", func() {
+		It("returns the time of the event", func() {
+			at := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+			Expect(metric.Event{Time: at}.Timestamp()).To(Equal(at))
+		})
+
+		It("treats a zero time as now", func() {
+			Expect(metric.Event{}.Timestamp()).To(BeTemporally("~", time.Now(), time.Second))
+		})
+	})
+	Describe("Validate", func() {
+		It("accepts well-formed events", func() {
+			Expect(metric.Event{
+				Name:       "build finished",
+				Value:      1,
+				Attributes: map[string]string{"team_name": "main"},
+			}.Validate()).To(Succeed())
+		})
+
+		It("rejects events without a name", func() {
+			Expect(metric.Event{Value: 1}.Validate()).To(MatchError(metric.InvalidEventError{
+				Reason: "name must not be empty",
+			}))
+		})
+
+		It("rejects events without a value", func() {
+			Expect(metric.Event{Name: "build finished"}.Validate()).To(MatchError(metric.InvalidEventError{
+				Name:   "build finished",
+				Reason: "value must not be nil",
+			}))
+		})
+
+		It("rejects attribute keys which are empty or contain whitespace", func() {
+			for _, key := range []string{"", "team name"} {
+				err := metric.Event{
+					Name:       "build finished",
+					Value:      1,
+					Attributes: map[string]string{key: "main"},
+				}.Validate()
+				Expect(err).To(BeAssignableToTypeOf(metric.InvalidEventError{}))
+			}
+		})
+	})
+})
+
+var _ = Describe("Emitting events", func() {
+	var (
+		emitter *metricfakes.FakeEmitter
+		unblock chan struct{}
+	)
+
+	BeforeEach(func() {
+		unblock = make(chan struct{})
+
+		emitter = &metricfakes.FakeEmitter{}
+		emitter.EmitStub = func(lager.Logger, metric.Event) {
+			<-unblock
+		}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		metric.DroppedEvents.Delta()
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{
+			BufferSize: 1,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		close(unblock)
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	Context("when the emitter cannot keep up", func() {
+		It("drops events once the queue is full", func() {
+			logger := lagertest.NewTestLogger("test")
+
+			metric.ErrorLog{Message: "first", Value: 1}.Emit(logger)
+			Eventually(emitter.EmitCallCount).Should(Equal(1))
+
+			metric.ErrorLog{Message: "queued", Value: 1}.Emit(logger)
+			metric.ErrorLog{Message: "dropped", Value: 1}.Emit(logger)
+
+			Expect(metric.DroppedEvents.Delta()).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Emitting durations", func() {
+	var emitter *metricfakes.FakeEmitter
+
+	BeforeEach(func() {
+		emitter = &metricfakes.FakeEmitter{}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("emits them as timers in milliseconds", func() {
+		metric.HTTPResponseTime{
+			Route:      "GetBuild",
+			Path:       "/api/v1/builds/1",
+			Method:     "GET",
+			StatusCode: 200,
+			Duration:   1500 * time.Millisecond,
+		}.Emit(lagertest.NewTestLogger("test"))
+
+		Eventually(emitter.EmitCallCount).Should(Equal(1))
+
+		_, event := emitter.EmitArgsForCall(0)
+		Expect(event.Value).To(Equal(1500 * time.Millisecond))
+		Expect(event.Type).To(Equal(metric.EventTypeTimer))
+		Expect(event.Unit).To(Equal(metric.UnitMilliseconds))
+	})
+})
+
+var _ = Describe("Emitting started builds", func() {
+	var emitter *metricfakes.FakeEmitter
+
+	BeforeEach(func() {
+		emitter = &metricfakes.FakeEmitter{}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("emits the build ID along with a counter", func() {
+		metric.BuildStarted{
+			PipelineName: "some-pipeline",
+			JobName:      "some-job",
+			BuildName:    "42",
+			BuildID:      123,
+			TeamName:     "main",
+		}.Emit(lagertest.NewTestLogger("test"))
+
+		Eventually(emitter.EmitCallCount).Should(Equal(2))
+
+		_, started := emitter.EmitArgsForCall(0)
+		Expect(started.Name).To(Equal("build started"))
+		Expect(started.Value).To(Equal(123))
+		Expect(started.Type).To(Equal(metric.EventTypeGauge))
+
+		_, counted := emitter.EmitArgsForCall(1)
+		Expect(counted.Name).To(Equal("builds started"))
+		Expect(counted.Value).To(Equal(1))
+		Expect(counted.Type).To(Equal(metric.EventTypeCounter))
+		Expect(counted.Attributes).ToNot(HaveKey("build_id"))
+	})
+})
+
+type histogramEmitter struct {
+	metricfakes.FakeEmitter
+}
+
+func (emitter *histogramEmitter) EmitsHistograms() bool { return true }
+
+var _ = Describe("Computing percentiles", func() {
+	var (
+		plain      *metricfakes.FakeEmitter
+		histograms *histogramEmitter
+	)
+
+	BeforeEach(func() {
+		plain = &metricfakes.FakeEmitter{}
+		histograms = &histogramEmitter{}
+
+		for _, emitter := range []metric.Emitter{plain, histograms} {
+			emitterFactory := &metricfakes.FakeEmitterFactory{}
+			emitterFactory.IsConfiguredReturns(true)
+			emitterFactory.NewEmitterReturns(emitter, nil)
+			metric.RegisterEmitter(emitterFactory)
+		}
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{
+			Percentiles:      []float64{50},
+			PercentileWindow: time.Hour,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("only replaces the timers of emitters without histograms", func() {
+		metric.HTTPResponseTime{
+			Route:      "GetBuild",
+			Path:       "/api/v1/builds/1",
+			Method:     "GET",
+			StatusCode: 200,
+			Duration:   1500 * time.Millisecond,
+		}.Emit(lagertest.NewTestLogger("test"))
+
+		Eventually(histograms.EmitCallCount).Should(Equal(1))
+
+		_, event := histograms.EmitArgsForCall(0)
+		Expect(event.Type).To(Equal(metric.EventTypeTimer))
+		Expect(plain.EmitCallCount()).To(BeZero())
+
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+
+		names := []string{}
+		for i := 0; i < plain.EmitCallCount(); i++ {
+			_, event := plain.EmitArgsForCall(i)
+			Expect(event.Type).To(Equal(metric.EventTypeGauge))
+			names = append(names, event.Name)
+		}
+
+		Expect(names).To(Equal([]string{"http response time p50", "http response time max"}))
+	})
+})
+
+var _ = Describe("Attaching attributes from the environment", func() {
+	var (
+		emitter *metricfakes.FakeEmitter
+		logger  *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		emitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		os.Setenv("METRIC_TEST_CLUSTER", "prod")
+		os.Unsetenv("METRIC_TEST_MISSING")
+
+		err := metric.Initialize(logger, "test", map[string]string{"region": "eu"}, metric.Config{
+			TagsFromEnv: []string{"cluster=METRIC_TEST_CLUSTER", "region=METRIC_TEST_CLUSTER", "zone=METRIC_TEST_MISSING"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+		os.Unsetenv("METRIC_TEST_CLUSTER")
+	})
+
+	It("attaches them to every event, unless set explicitly", func() {
+		metric.ErrorLog{Message: "oops", Value: 1}.Emit(lagertest.NewTestLogger("test"))
+
+		Eventually(emitter.EmitCallCount).Should(Equal(1))
+
+		_, event := emitter.EmitArgsForCall(0)
+		Expect(event.Attributes).To(HaveKeyWithValue("cluster", "prod"))
+		Expect(event.Attributes).To(HaveKeyWithValue("region", "eu"))
+		Expect(event.Attributes).ToNot(HaveKey("zone"))
+	})
+
+	It("warns about missing environment variables", func() {
+		Expect(logger.LogMessages()).To(ContainElement("test.missing-metric-tag-env-var"))
+	})
+})
+
+var _ = Describe("Dropping zeros", func() {
+	var emitter *metricfakes.FakeEmitter
+
+	BeforeEach(func() {
+		emitter = &metricfakes.FakeEmitter{}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{
+			DropZeros: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("suppresses events with a value of zero, except for counters", func() {
+		logger := lagertest.NewTestLogger("test")
+
+		metric.HTTPResponseTime{Route: "GetBuild", Duration: 0}.Emit(logger)
+		metric.ErrorLog{Message: "oops", Value: 0}.Emit(logger)
+		metric.HTTPResponseTime{Route: "GetBuild", Duration: time.Second}.Emit(logger)
+
+		metric.Deinitialize(logger)
+
+		Expect(emitter.EmitCallCount()).To(Equal(2))
+
+		_, event := emitter.EmitArgsForCall(0)
+		Expect(event.Name).To(Equal("error log"))
+
+		_, event = emitter.EmitArgsForCall(1)
+		Expect(event.Value).To(Equal(time.Second))
+	})
+})
+
+var _ = Describe("Emitting invalid events", func() {
+	var emitter *metricfakes.FakeEmitter
+
+	BeforeEach(func() {
+		emitter = &metricfakes.FakeEmitter{}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{"bad key": "value"}, metric.Config{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("logs and drops them", func() {
+		logger := lagertest.NewTestLogger("test")
+		metric.ErrorLog{Message: "oops", Value: 1}.Emit(logger)
+
+		Expect(logger.LogMessages()).To(ContainElement("test.error-log.invalid-event"))
+
+		metric.Deinitialize(logger)
+		Expect(emitter.EmitCallCount()).To(Equal(0))
+	})
+})
+
+type batchEmitter struct {
+	metricfakes.FakeEmitter
+
+	unblock chan struct{}
+
+	batches [][]metric.Event
+	lock    sync.Mutex
+}
+
+func (emitter *batchEmitter) EmitBatch(logger lager.Logger, events []metric.Event) {
+	<-emitter.unblock
+
+	emitter.lock.Lock()
+	emitter.batches = append(emitter.batches, events)
+	emitter.lock.Unlock()
+}
+
+func (emitter *batchEmitter) Batches() [][]metric.Event {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+
+	return emitter.batches
+}
+
+var _ = Describe("Emitting to a BatchEmitter", func() {
+	var emitter *batchEmitter
+
+	BeforeEach(func() {
+		emitter = &batchEmitter{unblock: make(chan struct{}, 2)}
+
+		emitterFactory := &metricfakes.FakeEmitterFactory{}
+		emitterFactory.IsConfiguredReturns(true)
+		emitterFactory.NewEmitterReturns(emitter, nil)
+		metric.RegisterEmitter(emitterFactory)
+
+		err := metric.Initialize(lagertest.NewTestLogger("test"), "test", map[string]string{}, metric.Config{})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("emits the events queued in the meantime as one batch", func() {
+		logger := lagertest.NewTestLogger("test")
+
+		metric.ErrorLog{Message: "first", Value: 1}.Emit(logger)
+		metric.ErrorLog{Message: "second", Value: 1}.Emit(logger)
+		metric.ErrorLog{Message: "third", Value: 1}.Emit(logger)
+
+		emitter.unblock <- struct{}{}
+		emitter.unblock <- struct{}{}
+
+		Eventually(func() int {
+			total := 0
+			for _, batch := range emitter.Batches() {
+				total += len(batch)
+			}
+			return total
+		}).Should(Equal(3))
+
+		Expect(emitter.EmitCallCount()).To(Equal(0))
+	})
+})
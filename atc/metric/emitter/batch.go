@@ -0,0 +1,173 @@
+package emitter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+// batcher buffers items and hands them to the flush func once the batch reaches
+// its maximum size or the flush interval elapses, whichever comes first.
+//
+// A batch which fails to be flushed with a transient error is held on to and
+// flushed again on the next interval. Until it is flushed, no more items are
+// accepted, so that the emitter reports the backend as failing.
+type batcher struct {
+	size     int
+	interval time.Duration
+	flush    func(lager.Logger, []interface{}) error
+
+	items  []interface{}
+	logger lager.Logger
+	mu     sync.Mutex
+
+	failed    []interface{}
+	failedErr error
+
+	done chan struct{}
+	once sync.Once
+}
+
+// checkBatching validates the batch size and flush interval configured for an
+// emitter, naming its flags by the given prefix.
+func checkBatching(prefix string, size int, interval time.Duration) error {
+	err := checkSize(prefix+"-batch-size", size)
+	if err != nil {
+		return err
+	}
+
+	return checkInterval(prefix+"-flush-interval", interval)
+}
+
+// checkSize validates a batch or buffer size given with the named flag.
+func checkSize(flag string, size int) error {
+	if size < 1 {
+		return fmt.Errorf("invalid --%s %d: must be at least 1", flag, size)
+	}
+
+	return nil
+}
+
+// checkInterval validates a flush interval given with the named flag.
+func checkInterval(flag string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid --%s %s: must be positive", flag, interval)
+	}
+
+	return nil
+}
+
+func newBatcher(size int, interval time.Duration, flush func(lager.Logger, []interface{}) error) *batcher {
+	batcher := &batcher{
+		size:     size,
+		interval: interval,
+		flush:    flush,
+
+		done: make(chan struct{}),
+	}
+
+	go batcher.periodicallyFlush()
+
+	return batcher
+}
+
+// Add adds the item to the batch, flushing it if it is full. It returns the
+// error of the batch being held on to instead if there is one, in which case
+// the item is not added.
+func (batcher *batcher) Add(logger lager.Logger, item interface{}) error {
+	batcher.mu.Lock()
+
+	if batcher.failed != nil {
+		err := batcher.failedErr
+		batcher.mu.Unlock()
+		return err
+	}
+
+	batcher.items = append(batcher.items, item)
+	batcher.logger = logger
+
+	if len(batcher.items) < batcher.size {
+		batcher.mu.Unlock()
+		return nil
+	}
+
+	items := batcher.items
+	batcher.items = nil
+	batcher.mu.Unlock()
+
+	batcher.send(logger, items)
+
+	return nil
+}
+
+// Flush flushes the batch being held on to, if any, and then the pending
+// items unless it failed again.
+func (batcher *batcher) Flush() {
+	batcher.mu.Lock()
+	failed, logger := batcher.failed, batcher.logger
+	batcher.mu.Unlock()
+
+	if failed != nil {
+		if !batcher.send(logger, failed) {
+			return
+		}
+
+		batcher.mu.Lock()
+		batcher.failed = nil
+		batcher.failedErr = nil
+		batcher.mu.Unlock()
+	}
+
+	batcher.mu.Lock()
+	items := batcher.items
+	batcher.items = nil
+	batcher.mu.Unlock()
+
+	if len(items) == 0 {
+		return
+	}
+
+	batcher.send(logger, items)
+}
+
+// send flushes the items, holding on to them if they failed to be flushed with
+// a transient error. It returns whether they were flushed or dropped.
+func (batcher *batcher) send(logger lager.Logger, items []interface{}) bool {
+	err := batcher.flush(logger, items)
+	if err == nil || !metric.IsTransient(err) {
+		return true
+	}
+
+	batcher.mu.Lock()
+	if batcher.failed == nil {
+		batcher.failed = items
+	}
+	batcher.failedErr = err
+	batcher.mu.Unlock()
+
+	return false
+}
+
+// Close stops flushing periodically and flushes the remaining items, giving
+// the batch being held on to one last try.
+func (batcher *batcher) Close() {
+	batcher.once.Do(func() { close(batcher.done) })
+	batcher.Flush()
+}
+
+func (batcher *batcher) periodicallyFlush() {
+	ticker := time.NewTicker(batcher.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			batcher.Flush()
+		case <-batcher.done:
+			return
+		}
+	}
+}
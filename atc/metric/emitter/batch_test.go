@@ -0,0 +1,138 @@
+package emitter_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Batching configuration", func() {
+	DescribeTable("rejecting sizes and intervals which are not positive",
+		func(factory metric.EmitterFactory, flag string) {
+			_, err := factory.NewEmitter()
+			Expect(err).To(MatchError(ContainSubstring("invalid --" + flag)))
+		},
+		Entry("honeycomb batch size", &emitter.HoneycombConfig{FlushInterval: time.Second}, "honeycomb-batch-size"),
+		Entry("honeycomb flush interval", &emitter.HoneycombConfig{BatchSize: 1}, "honeycomb-flush-interval"),
+		Entry("splunk batch size", &emitter.SplunkConfig{FlushInterval: time.Second}, "splunk-batch-size"),
+		Entry("splunk flush interval", &emitter.SplunkConfig{BatchSize: 1}, "splunk-flush-interval"),
+		Entry("webhook batch size", &emitter.WebhookConfig{FlushInterval: time.Second}, "webhook-batch-size"),
+		Entry("webhook flush interval", &emitter.WebhookConfig{BatchSize: 1}, "webhook-flush-interval"),
+		Entry("signalfx batch size", &emitter.SignalFxConfig{FlushInterval: time.Second}, "signalfx-batch-size"),
+		Entry("signalfx flush interval", &emitter.SignalFxConfig{BatchSize: 1}, "signalfx-flush-interval"),
+		Entry("victoriametrics batch size", &emitter.VMConfig{FlushInterval: time.Second}, "vm-batch-size"),
+		Entry("victoriametrics flush interval", &emitter.VMConfig{BatchSize: 1}, "vm-flush-interval"),
+		Entry("opentsdb batch size", &emitter.OpenTSDBConfig{FlushInterval: time.Second}, "opentsdb-batch-size"),
+		Entry("opentsdb flush interval", &emitter.OpenTSDBConfig{BatchSize: 1}, "opentsdb-flush-interval"),
+		Entry("otlp export interval", &emitter.OTLPConfig{}, "otlp-export-interval"),
+		Entry("cloudwatch flush interval", &emitter.CloudWatchConfig{}, "cloudwatch-flush-interval"),
+		Entry("kinesis flush interval", &emitter.KinesisConfig{}, "kinesis-flush-interval"),
+		Entry("newrelic flush interval", &emitter.NewRelicConfig{}, "newrelic-flush-interval"),
+		Entry("stackdriver flush interval", &emitter.StackdriverConfig{}, "stackdriver-flush-interval"),
+		Entry("datadog buffer size", &emitter.DogstatsDBConfig{SampleRate: 1, FlushInterval: time.Second}, "datadog-buffer-size"),
+		Entry("datadog flush interval", &emitter.DogstatsDBConfig{SampleRate: 1, BufferSize: 1}, "datadog-flush-interval"),
+		Entry("file flush interval", &emitter.FileConfig{}, "metrics-file-flush-interval"),
+		Entry("prometheus push interval", &emitter.PrometheusPushConfig{}, "prometheus-push-interval"),
+		Entry("kafka buffer size", &emitter.KafkaConfig{}, "kafka-buffer-size"),
+		Entry("amqp buffer size", &emitter.AMQPConfig{}, "amqp-buffer-size"),
+	)
+})
+
+var _ = Describe("Batching emitters", func() {
+	var (
+		status   int32
+		received chan string
+		server   *httptest.Server
+		fallible metric.FallibleEmitter
+		logger   *lagertest.TestLogger
+	)
+
+	event := func(name string) metric.Event {
+		return metric.Event{
+			Name:  name,
+			Value: 1,
+			Host:  "some-host",
+		}
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		received = make(chan string, 10)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			code := int(atomic.LoadInt32(&status))
+			if code == http.StatusOK {
+				received <- string(body)
+			}
+
+			w.WriteHeader(code)
+		}))
+
+		config := &emitter.WebhookConfig{
+			URL:           server.URL,
+			BatchSize:     1,
+			FlushInterval: 100 * time.Millisecond,
+		}
+
+		e, err := config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(e).To(BeAssignableToTypeOf(&emitter.WebhookEmitter{}))
+		fallible = e.(metric.FallibleEmitter)
+	})
+
+	AfterEach(func() {
+		fallible.Close()
+		server.Close()
+	})
+
+	Context("when a batch fails to be sent with a transient error", func() {
+		BeforeEach(func() {
+			atomic.StoreInt32(&status, http.StatusServiceUnavailable)
+
+			Expect(fallible.TryEmit(logger, event("first"))).To(Succeed())
+		})
+
+		It("rejects events with the error until the batch is sent", func() {
+			err := fallible.TryEmit(logger, event("second"))
+			Expect(err).To(HaveOccurred())
+			Expect(metric.IsTransient(err)).To(BeTrue())
+
+			atomic.StoreInt32(&status, http.StatusOK)
+
+			var body string
+			Eventually(received, 10*time.Second).Should(Receive(&body))
+			Expect(body).To(ContainSubstring(`"first"`))
+
+			Eventually(func() error {
+				return fallible.TryEmit(logger, event("third"))
+			}).Should(Succeed())
+		})
+	})
+
+	Context("when a batch fails to be sent with a permanent error", func() {
+		BeforeEach(func() {
+			atomic.StoreInt32(&status, http.StatusBadRequest)
+
+			Expect(fallible.TryEmit(logger, event("first"))).To(Succeed())
+		})
+
+		It("drops the batch and keeps accepting events", func() {
+			Expect(fallible.TryEmit(logger, event("second"))).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,58 @@
+package emitter_test
+
+import (
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Circuit breaking an HTTP emitter", func() {
+	var (
+		breaker *metric.CircuitBreakerEmitter
+		logger  *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		// nothing listens on the address once the listener is closed
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		deadURL := "http://" + listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		config := &emitter.WebhookConfig{
+			URL:           deadURL,
+			Timeout:       time.Second,
+			BatchSize:     1,
+			FlushInterval: time.Hour,
+		}
+
+		webhook, err := config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+
+		breaker = metric.NewCircuitBreakerEmitter(webhook.(metric.FallibleEmitter), "webhook", 2, time.Hour)
+	})
+
+	AfterEach(func() {
+		breaker.Close()
+	})
+
+	It("opens once the endpoint keeps refusing connections", func() {
+		event := metric.Event{Name: "some metric", Value: 1, Host: "some-host"}
+
+		Eventually(func() metric.CircuitState {
+			breaker.Emit(logger, event)
+			return breaker.State()
+		}, 10*time.Second).Should(Equal(metric.CircuitOpen))
+
+		Expect(breaker.TryEmit(logger, event)).To(Equal(metric.ErrCircuitOpen))
+	})
+})
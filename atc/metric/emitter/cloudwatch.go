@@ -0,0 +1,215 @@
+package emitter
+
+import (
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const (
+	// cloudWatchMaxDatums is the maximum number of datums accepted by a single
+	// PutMetricData call
+	cloudWatchMaxDatums = 20
+
+	// cloudWatchMaxDimensions is the maximum number of dimensions a single datum
+	// may have
+	cloudWatchMaxDimensions = 10
+)
+
+type CloudWatchEmitter struct {
+	client    cloudwatchiface.CloudWatchAPI
+	namespace string
+	batcher   *batcher
+}
+
+type CloudWatchConfig struct {
+	Namespace     string        `long:"cloudwatch-namespace"                    description:"CloudWatch namespace to emit metrics to."`
+	Region        string        `long:"cloudwatch-region"                       description:"AWS region to emit metrics to. Defaults to the region configured for the AWS SDK."`
+	FlushInterval time.Duration `long:"cloudwatch-flush-interval" default:"60s" description:"Interval on which to flush batched metrics to CloudWatch."`
+}
+
+func init() {
+	metric.RegisterEmitter(&CloudWatchConfig{})
+}
+
+func (config *CloudWatchConfig) Description() string { return "CloudWatch" }
+func (config *CloudWatchConfig) FlagPrefix() string  { return "cloudwatch" }
+func (config *CloudWatchConfig) IsConfigured() bool  { return config.Namespace != "" }
+
+func (config *CloudWatchConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("cloudwatch-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &CloudWatchEmitter{}, err
+	}
+
+	awsConfig := aws.NewConfig()
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+
+	// rely on the default credential chain, i.e. env vars, shared credentials
+	// or instance/task roles
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return &CloudWatchEmitter{}, err
+	}
+
+	emitter := &CloudWatchEmitter{
+		client:    cloudwatch.New(sess),
+		namespace: config.Namespace,
+	}
+
+	emitter.batcher = newBatcher(cloudWatchMaxDatums, config.FlushInterval, emitter.putMetricData)
+
+	return emitter, nil
+}
+
+func (emitter *CloudWatchEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *CloudWatchEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-cloudwatch", nil, lager.Data{
+			"metric-name": event.Name,
+		})
+		return nil
+	}
+
+	unit := cloudwatch.StandardUnitNone
+	switch event.Unit {
+	case metric.UnitMilliseconds:
+		unit = cloudwatch.StandardUnitMilliseconds
+	case metric.UnitNanoseconds:
+		// cloudwatch has no unit finer than microseconds
+		unit = cloudwatch.StandardUnitMicroseconds
+		value = value / 1000
+	case metric.UnitBytes:
+		unit = cloudwatch.StandardUnitBytes
+	case metric.UnitCount:
+		unit = cloudwatch.StandardUnitCount
+	}
+
+	return emitter.batcher.Add(logger, &cloudwatch.MetricDatum{
+		MetricName: aws.String(event.Name),
+		Dimensions: emitter.dimensions(logger, event),
+		Timestamp:  aws.Time(event.Timestamp()),
+		Value:      aws.Float64(value),
+		Unit:       aws.String(unit),
+	})
+}
+
+// Close flushes any batched metrics.
+func (emitter *CloudWatchEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+// dimensions builds the dimensions for an event, prioritizing host and state
+// over the attributes, which are considered in order of their names. Any
+// dimensions beyond CloudWatch's limit are dropped.
+func (emitter *CloudWatchEmitter) dimensions(logger lager.Logger, event metric.Event) []*cloudwatch.Dimension {
+	names := []string{}
+	for name := range event.Attributes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	values := map[string]string{}
+	for _, name := range names {
+		values[name] = event.Attributes[name]
+	}
+
+	values["host"] = event.Host
+	values["state"] = string(event.State)
+	names = append([]string{"host", "state"}, names...)
+
+	dimensions := []*cloudwatch.Dimension{}
+	dropped := []string{}
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		// cloudwatch rejects empty dimension values
+		if seen[name] || values[name] == "" {
+			continue
+		}
+
+		seen[name] = true
+
+		if len(dimensions) == cloudWatchMaxDimensions {
+			dropped = append(dropped, name)
+			continue
+		}
+
+		dimensions = append(dimensions, &cloudwatch.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(values[name]),
+		})
+	}
+
+	if len(dropped) > 0 {
+		logger.Info("dropped-dimensions", lager.Data{
+			"metric-name": event.Name,
+			"dimensions":  dropped,
+		})
+	}
+
+	return dimensions
+}
+
+func (emitter *CloudWatchEmitter) putMetricData(logger lager.Logger, items []interface{}) error {
+	datums := make([]*cloudwatch.MetricDatum, len(items))
+	for i, item := range items {
+		datums[i] = item.(*cloudwatch.MetricDatum)
+	}
+
+	_, err := emitter.client.PutMetricData(&cloudwatch.PutMetricDataInput{
+		Namespace:  aws.String(emitter.namespace),
+		MetricData: datums,
+	})
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return awsError(err)
+	}
+
+	return nil
+}
+
+// awsError marks errors of AWS requests which may not happen again as
+// metric.TransientErrors: requests which could not be made at all, throttling
+// and server errors.
+func awsError(err error) error {
+	if failure, ok := err.(awserr.RequestFailure); ok && failure.StatusCode() >= 500 {
+		return metric.TransientError{Err: err}
+	}
+
+	// the SDK reports requests which could not be sent as "RequestError"
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == "RequestError" {
+		return metric.TransientError{Err: err}
+	}
+
+	if request.IsErrorRetryable(err) || request.IsErrorThrottle(err) {
+		return metric.TransientError{Err: err}
+	}
+
+	return err
+}
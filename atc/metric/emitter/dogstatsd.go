@@ -1,25 +1,104 @@
 package emitter
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/DataDog/datadog-go/statsd"
+	"github.com/cenkalti/backoff"
+	"github.com/concourse/concourse/atc/db"
 	"github.com/concourse/concourse/atc/metric"
 	"github.com/pkg/errors"
 )
 
+const (
+	// dogstatsdLowSampleRate is the sample rate below which metrics are likely
+	// too sparse to be useful, which is warned about
+	dogstatsdLowSampleRate = 0.1
+
+	// dogstatsdCommandsPerDatagram is the number of metrics the client packs
+	// into a single datagram
+	dogstatsdCommandsPerDatagram = 64
+
+	// dogstatsdReconnectThreshold is the number of consecutive failed sends
+	// after which the client is rebuilt, e.g. because the agent restarted and
+	// its socket went away
+	dogstatsdReconnectThreshold = 10
+)
+
 type DogstatsdEmitter struct {
-	client *statsd.Client
+	client       *statsd.Client
+	sampleRate   float64
+	maxTagLength int
+
+	useDistributions          bool
+	sendBuildEvents           bool
+	sendSuccessfulBuildEvents bool
+
+	metrics chan dogstatsdMetric
+	dropped uint64
+	stop    chan struct{}
+	done    chan struct{}
+
+	// only accessed by the flushing goroutine
+	newClient     func() (*statsd.Client, error)
+	failures      int
+	reconnect     *backoff.ExponentialBackOff
+	nextReconnect time.Time
+	logger        lager.Logger
+
+	// the error of the last flush which sent any metrics, returned by TryEmit
+	// until a flush succeeds again; one event is let through per flush to
+	// probe the agent in the meantime
+	flushErr  error
+	probing   bool
+	flushLock sync.Mutex
+
+	warnOnce  sync.Once
+	closeOnce sync.Once
+}
+
+type dogstatsdMetric struct {
+	logger    lager.Logger
+	name      string
+	value     float64
+	tags      []string
+	eventType metric.EventType
+	host      string
+	state     metric.EventState
+
+	// set for events sent to the event stream rather than as metrics
+	event *statsd.Event
 }
 
 type DogstatsDBConfig struct {
 	Host   string `long:"datadog-agent-host" description:"Datadog agent host to expose dogstatsd metrics"`
 	Port   string `long:"datadog-agent-port" description:"Datadog agent port to expose dogstatsd metrics"`
-	Prefix string `long:"datadog-prefix" description:"Prefix for all metrics to easily find them in Datadog"`
+	Socket string `long:"datadog-agent-socket" description:"Path to the Datadog agent's Unix domain socket to expose dogstatsd metrics. Takes precedence over the host and port"`
+	Prefix string `long:"datadog-prefix" description:"Prefix for all metrics to easily find them in Datadog. ${ENV_VAR} placeholders are replaced with the values of environment variables at startup"`
+
+	Tags []string `long:"datadog-tag" description:"Tag to add to all metrics, in the form 'key:value'. Can be specified multiple times"`
+
+	MaxTagLength int `long:"datadog-max-tag-length" default:"200" description:"Length to truncate attribute tag values to"`
+
+	UseDistributions bool `long:"datadog-use-distributions" description:"Send timers as distributions, which are aggregated server-side across all ATCs rather than on each agent"`
+
+	SendBuildEvents           bool `long:"datadog-send-build-events" description:"Send a Datadog event for every failed or errored build"`
+	SendSuccessfulBuildEvents bool `long:"datadog-send-successful-build-events" description:"Also send Datadog events for builds which did not fail, as info. Requires --datadog-send-build-events"`
+
+	SampleRate float64 `long:"datadog-sample-rate" default:"1.0" description:"Rate at which to sample metrics sent to Datadog, between 0 (exclusive) and 1"`
+
+	BufferSize    int           `long:"datadog-buffer-size" default:"10000" description:"Number of metrics to buffer between flushes. The oldest metrics are dropped once full"`
+	FlushInterval time.Duration `long:"datadog-flush-interval" default:"1s" description:"Interval on which to flush buffered metrics to the Datadog agent"`
 }
 
 func getFloatHelper(value interface{}) (f float64, err error) {
@@ -48,46 +127,205 @@ func getFloatHelper(value interface{}) (f float64, err error) {
 		f = float64(value.(float32))
 	case float64:
 		f = value.(float64)
+	case time.Duration:
+		f = float64(value.(time.Duration)) / float64(time.Millisecond)
+	case bool:
+		if value.(bool) {
+			f = 1
+		}
 	default:
 		err = errors.New("type not supported")
 	}
 	return f, err
 }
 
+// eventValue converts durations to milliseconds and booleans to 1 or 0 for
+// emitters which pass values on as they are, and returns any other value
+// unchanged.
+func eventValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return float64(v) / float64(time.Millisecond)
+	case bool:
+		if v {
+			return 1.0
+		}
+
+		return 0.0
+	default:
+		return value
+	}
+}
+
+const dogstatsdDescription = "Datadog"
+
 func init() {
 	metric.RegisterEmitter(&DogstatsDBConfig{})
 }
 
-func (config *DogstatsDBConfig) Description() string { return "Datadog" }
+func (config *DogstatsDBConfig) Description() string { return dogstatsdDescription }
+func (config *DogstatsDBConfig) FlagPrefix() string  { return "datadog" }
+
+func (config *DogstatsDBConfig) IsConfigured() bool {
+	return config.Socket != "" || (config.Host != "" && config.Port != "")
+}
+
+// address returns the agent address in the form expected by statsd.New.
+func (config *DogstatsDBConfig) address() string {
+	if config.Socket != "" {
+		return statsd.UnixAddressPrefix + strings.TrimPrefix(config.Socket, statsd.UnixAddressPrefix)
+	}
 
-func (config *DogstatsDBConfig) IsConfigured() bool { return config.Host != "" && config.Port != "" }
+	return fmt.Sprintf("%s:%s", config.Host, config.Port)
+}
 
 func (config *DogstatsDBConfig) NewEmitter() (metric.Emitter, error) {
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		return &DogstatsdEmitter{}, fmt.Errorf("invalid datadog sample rate %v: must be greater than 0 and at most 1", config.SampleRate)
+	}
 
-	client, err := statsd.New(fmt.Sprintf("%s:%s", config.Host, config.Port))
+	err := checkSize("datadog-buffer-size", config.BufferSize)
+	if err != nil {
+		return &DogstatsdEmitter{}, err
+	}
+
+	err = checkInterval("datadog-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &DogstatsdEmitter{}, err
+	}
+
+	_, err = expandPlaceholders(config.Prefix)
+	if err != nil {
+		return &DogstatsdEmitter{}, err
+	}
+
+	client, err := config.newClient()
 	if err != nil {
 		log.Fatal(err)
 		return &DogstatsdEmitter{}, err
 	}
 
-	if config.Prefix != "" {
-		if strings.HasSuffix(config.Prefix, ".") {
-			client.Namespace = config.Prefix
-		} else {
-			client.Namespace = fmt.Sprintf("%s.", config.Prefix)
+	reconnect := backoff.NewExponentialBackOff()
+	reconnect.MaxElapsedTime = 0
+
+	emitter := &DogstatsdEmitter{
+		client:       client,
+		sampleRate:   config.SampleRate,
+		maxTagLength: config.MaxTagLength,
+
+		useDistributions:          config.UseDistributions,
+		sendBuildEvents:           config.SendBuildEvents,
+		sendSuccessfulBuildEvents: config.SendSuccessfulBuildEvents,
+
+		metrics: make(chan dogstatsdMetric, config.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+
+		newClient: config.newClient,
+		reconnect: reconnect,
+	}
+
+	go emitter.periodicallyFlush(config.FlushInterval)
+
+	return emitter, nil
+}
+
+func (config *DogstatsDBConfig) newClient() (*statsd.Client, error) {
+	client, err := statsd.NewBuffered(config.address(), dogstatsdCommandsPerDatagram)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := expandPlaceholders(config.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Namespace = namespace(prefix)
+
+	// sent in addition to the host, state and attribute tags of each metric
+	client.Tags = config.Tags
+
+	return client, nil
+}
+
+// dogstatsdValue additionally accepts numbers which were serialized, e.g. as
+// numeric strings or json.Number.
+func dogstatsdValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return v.Float64()
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return getFloatHelper(value)
+	}
+}
+
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandPlaceholders replaces ${ENV_VAR} placeholders with the values of the
+// environment variables, which must be set.
+func expandPlaceholders(s string) (string, error) {
+	var missing []string
+
+	expanded := placeholder.ReplaceAllStringFunc(s, func(match string) string {
+		name := placeholder.FindStringSubmatch(match)[1]
+
+		value, found := os.LookupEnv(name)
+		if !found {
+			missing = append(missing, name)
 		}
+
+		return value
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variables referenced in '%s' are not set: %s", s, strings.Join(missing, ", "))
 	}
 
-	return &DogstatsdEmitter{
-		client: client,
-	}, nil
+	return expanded, nil
+}
+
+// namespace ensures a non-empty prefix ends with a dot so that it can be
+// prepended to metric names.
+func namespace(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, ".") {
+		return prefix
+	}
+
+	return fmt.Sprintf("%s.", prefix)
 }
 
 var specialChars = regexp.MustCompile("[^a-zA-Z0-9_]+")
 
+// normalizeName lowercases the name, replaces spaces with underscores and strips
+// any other special characters, so that metric names are consistent across
+// emitters.
+func normalizeName(name string) string {
+	return specialChars.ReplaceAllString(strings.Replace(strings.ToLower(name), " ", "_", -1), "")
+}
+
+// pathComponent normalizes a value for use as a single component of a dotted
+// metric path, for backends which have no notion of tags.
+func pathComponent(component string) string {
+	return normalizeName(strings.Replace(component, ".", "_", -1))
+}
+
+// EmitsHistograms returns true, as the agent computes the percentiles of
+// timers.
+func (emitter *DogstatsdEmitter) EmitsHistograms() bool { return true }
+
 func (emitter *DogstatsdEmitter) Emit(logger lager.Logger, event metric.Event) {
+	emitter.warnOnce.Do(func() {
+		if emitter.sampleRate < dogstatsdLowSampleRate {
+			logger.Info("low-sample-rate", lager.Data{
+				"sample-rate": emitter.sampleRate,
+			})
+		}
+	})
 
-	name := specialChars.ReplaceAllString(strings.Replace(strings.ToLower(event.Name), " ", "_", -1), "")
+	name := normalizeName(event.Name)
 
 	tags := []string{
 		fmt.Sprintf("host:%s", event.Host),
@@ -95,21 +333,337 @@ func (emitter *DogstatsdEmitter) Emit(logger lager.Logger, event metric.Event) {
 	}
 
 	for k, v := range event.Attributes {
-		tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+		key, value := emitter.sanitizeTag(k, v)
+		if key != k || value != v {
+			logger.Debug("sanitized-tag", lager.Data{
+				"metric-name": name,
+				"key":         k,
+				"value":       v,
+				"tag":         fmt.Sprintf("%s:%s", key, value),
+			})
+		}
+
+		tags = append(tags, fmt.Sprintf("%s:%s", key, value))
+	}
+
+	eventType := event.Type
+
+	var value float64
+	var err error
+
+	if duration, ok := event.Value.(time.Duration); ok {
+		eventType = metric.EventTypeTimer
+		value = float64(duration) / float64(time.Millisecond)
+	} else {
+		value, err = dogstatsdValue(event.Value)
+		if err != nil {
+			logger.Error("failed-to-convert-metric-for-dogstatsd", nil, lager.Data{
+				"metric-name": name,
+			})
+			return
+		}
+	}
+
+	emitter.enqueue(dogstatsdMetric{
+		logger:    logger,
+		name:      name,
+		value:     value,
+		tags:      tags,
+		eventType: eventType,
+		host:      event.Host,
+		state:     event.State,
+	})
+
+	if event.Name == "build finished" && emitter.sendBuildEvents {
+		emitter.emitBuildEvent(logger, event, tags)
+	}
+}
+
+// TryEmit enqueues the event unless the agent failed to receive the last
+// metrics sent to it.
+func (emitter *DogstatsdEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	emitter.flushLock.Lock()
+	if emitter.flushErr != nil {
+		if emitter.probing {
+			err := emitter.flushErr
+			emitter.flushLock.Unlock()
+			return metric.TransientError{Err: err}
+		}
+
+		emitter.probing = true
+	}
+	emitter.flushLock.Unlock()
+
+	emitter.Emit(logger, event)
+
+	return nil
+}
+
+func (emitter *DogstatsdEmitter) emitBuildEvent(logger lager.Logger, event metric.Event, tags []string) {
+	status := event.Attributes["build_status"]
+
+	alertType := statsd.Info
+	if status == string(db.BuildStatusFailed) || status == string(db.BuildStatusErrored) {
+		alertType = statsd.Error
+	} else if !emitter.sendSuccessfulBuildEvents {
+		return
+	}
+
+	emitter.enqueue(dogstatsdMetric{
+		logger: logger,
+		event: &statsd.Event{
+			Title: fmt.Sprintf("Build %s/%s #%s %s",
+				event.Attributes["pipeline"],
+				event.Attributes["job"],
+				event.Attributes["build_name"],
+				status,
+			),
+			Text: fmt.Sprintf("team: %s\npipeline: %s\njob: %s\nbuild: %s (id %s)",
+				event.Attributes["team_name"],
+				event.Attributes["pipeline"],
+				event.Attributes["job"],
+				event.Attributes["build_name"],
+				event.Attributes["build_id"],
+			),
+			Timestamp:      event.Timestamp(),
+			Hostname:       event.Host,
+			AggregationKey: fmt.Sprintf("%s/%s", event.Attributes["pipeline"], event.Attributes["job"]),
+			SourceTypeName: "concourse",
+			AlertType:      alertType,
+			Tags:           tags,
+		},
+	})
+}
+
+// enqueue buffers the metric for the next flush. Metrics emitted once the
+// emitter is closed are discarded; the buffer is never closed, so that this
+// does not race with Close.
+func (emitter *DogstatsdEmitter) enqueue(m dogstatsdMetric) {
+	select {
+	case <-emitter.stop:
+		return
+	default:
+	}
+
+	for {
+		select {
+		case emitter.metrics <- m:
+			return
+		default:
+		}
+
+		// the buffer is full; make room by dropping the oldest metric
+		select {
+		case <-emitter.metrics:
+			atomic.AddUint64(&emitter.dropped, 1)
+		default:
+		}
 	}
+}
+
+// Close flushes any buffered metrics right away and closes the client. It is
+// safe to call more than once, and on an emitter which failed to be created.
+func (emitter *DogstatsdEmitter) Close() error {
+	var err error
+
+	emitter.closeOnce.Do(func() {
+		if emitter.client == nil {
+			return
+		}
+
+		close(emitter.stop)
+		<-emitter.done
+
+		err = emitter.client.Close()
+	})
+
+	return err
+}
+
+func (emitter *DogstatsdEmitter) periodicallyFlush(interval time.Duration) {
+	defer close(emitter.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emitter.flush()
+		case <-emitter.stop:
+			emitter.flush()
+			return
+		}
+	}
+}
+
+// flush sends the metrics buffered so far.
+func (emitter *DogstatsdEmitter) flush() {
+	var (
+		sent    int
+		sendErr error
+	)
+
+	defer func() {
+		err := emitter.client.Flush()
+		if err != nil {
+			metric.EmitFailed(dogstatsdDescription)
+
+			if emitter.logger != nil {
+				emitter.logger.Error("failed-to-send-metric",
+					errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+			}
+		}
+
+		emitter.recordResult(err)
+
+		if err == nil {
+			err = sendErr
+		}
 
-	value, err := getFloatHelper(event.Value)
+		emitter.flushLock.Lock()
+		if sent > 0 {
+			emitter.flushErr = err
+		}
+		emitter.probing = false
+		emitter.flushLock.Unlock()
+	}()
+
+	for {
+		select {
+		case m := <-emitter.metrics:
+			sent++
+
+			err := emitter.send(m)
+			if err != nil && sendErr == nil {
+				sendErr = err
+			}
+		default:
+			dropped := atomic.SwapUint64(&emitter.dropped, 0)
+			if dropped > 0 {
+				emitter.client.Count("dropped_metrics", int64(dropped), nil, 1)
+			}
+
+			return
+		}
+	}
+}
+
+// sanitizeTag makes an attribute safe to use as a tag, as Datadog silently
+// rejects or truncates tags that contain special characters or don't start
+// with a letter.
+func (emitter *DogstatsdEmitter) sanitizeTag(key string, value string) (string, string) {
+	key = strings.ToLower(specialChars.ReplaceAllString(key, "_"))
+	if key != "" && key[0] >= '0' && key[0] <= '9' {
+		key = "tag_" + key
+	}
+
+	value = truncate(specialChars.ReplaceAllString(value, "_"), emitter.maxTagLength)
+
+	return key, value
+}
+
+func (emitter *DogstatsdEmitter) send(m dogstatsdMetric) error {
+	var err error
+
+	if m.event != nil {
+		err = emitter.client.Event(m.event)
+	} else {
+		err = emitter.sendMetric(m)
+	}
+
+	emitter.logger = m.logger
+
+	// the client only writes once its buffer is full, so a successful send
+	// isn't proof of a healthy connection; only a successful flush is
 	if err != nil {
-		logger.Error("failed-to-convert-metric-for-dogstatsd", nil, lager.Data{
-			"metric-name": name,
+		metric.EmitFailed(dogstatsdDescription)
+		m.logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		emitter.recordResult(err)
+		return err
+	}
+
+	return nil
+}
+
+func (emitter *DogstatsdEmitter) sendMetric(m dogstatsdMetric) error {
+	var err error
+
+	// timers are aggregated into percentiles by the agent, rather than only
+	// keeping the last value as with gauges
+	switch m.eventType {
+	case metric.EventTypeCounter:
+		err = emitter.client.Count(m.name, int64(m.value), m.tags, emitter.sampleRate)
+	case metric.EventTypeTimer:
+		// percentiles of histograms and timers are computed per agent, which
+		// understates them across many ATCs; distributions are global
+		if emitter.useDistributions {
+			err = emitter.client.Distribution(m.name, m.value, m.tags, emitter.sampleRate)
+		} else {
+			err = emitter.client.TimeInMilliseconds(m.name, m.value, m.tags, emitter.sampleRate)
+		}
+	case metric.EventTypeServiceCheck:
+		// unlike metrics, the client doesn't namespace service checks
+		err = emitter.client.ServiceCheck(&statsd.ServiceCheck{
+			Name:     emitter.client.Namespace + m.name,
+			Status:   dogstatsdServiceCheckStatus(m.state),
+			Hostname: m.host,
+			Tags:     m.tags,
 		})
+	default:
+		err = emitter.client.Gauge(m.name, m.value, m.tags, emitter.sampleRate)
+	}
+
+	return err
+}
+
+func dogstatsdServiceCheckStatus(state metric.EventState) statsd.ServiceCheckStatus {
+	switch state {
+	case metric.EventStateOK:
+		return statsd.Ok
+	case metric.EventStateWarning:
+		return statsd.Warn
+	case metric.EventStateCritical:
+		return statsd.Critical
+	default:
+		return statsd.Unknown
+	}
+}
+
+// recordResult keeps track of consecutive failed sends, rebuilding the client
+// with backoff once they pass the threshold.
+func (emitter *DogstatsdEmitter) recordResult(err error) {
+	if err == nil {
+		emitter.failures = 0
+		emitter.reconnect.Reset()
 		return
 	}
 
-	err = emitter.client.Gauge(name, value, tags, 1)
+	emitter.failures++
+
+	if emitter.failures < dogstatsdReconnectThreshold || time.Now().Before(emitter.nextReconnect) {
+		return
+	}
+
+	emitter.nextReconnect = time.Now().Add(emitter.reconnect.NextBackOff())
+
+	client, err := emitter.newClient()
 	if err != nil {
-		logger.Error("failed-to-send-metric",
-			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		if emitter.logger != nil {
+			emitter.logger.Error("failed-to-reconnect", err)
+		}
 		return
 	}
+
+	emitter.client.Close()
+	emitter.client = client
+	emitter.failures = 0
+
+	if emitter.logger != nil {
+		emitter.logger.Info("reconnected")
+	}
+
+	// so that operators can alert on a flaky agent
+	emitter.client.Count("reconnects", 1, nil, 1)
 }
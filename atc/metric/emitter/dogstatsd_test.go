@@ -0,0 +1,75 @@
+package emitter_test
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DogstatsdEmitter", func() {
+	var (
+		agent  *net.UDPConn
+		logger *lagertest.TestLogger
+		e      metric.Emitter
+	)
+
+	BeforeEach(func() {
+		var err error
+		agent, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).NotTo(HaveOccurred())
+
+		logger = lagertest.NewTestLogger("test")
+
+		config := &emitter.DogstatsDBConfig{
+			Host:          "127.0.0.1",
+			Port:          strconv.Itoa(agent.LocalAddr().(*net.UDPAddr).Port),
+			MaxTagLength:  200,
+			SampleRate:    1,
+			BufferSize:    100,
+			FlushInterval: time.Hour,
+		}
+
+		e, err = config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		agent.Close()
+	})
+
+	event := metric.Event{
+		Name:  "worker containers",
+		Value: 5,
+		Type:  metric.EventTypeGauge,
+		Host:  "some-host",
+	}
+
+	It("flushes the buffered metrics right away when closed", func() {
+		e.Emit(logger, event)
+
+		closed := make(chan error)
+		go func() { closed <- e.Close() }()
+
+		Eventually(closed, time.Second).Should(Receive(BeNil()))
+
+		buf := make([]byte, 1024)
+		Expect(agent.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		n, err := agent.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(buf[:n])).To(ContainSubstring("worker_containers:5.000000|g"))
+	})
+
+	It("discards metrics emitted once closed", func() {
+		Expect(e.Close()).To(Succeed())
+
+		Expect(func() { e.Emit(logger, event) }).NotTo(Panic())
+		Expect(e.Close()).To(Succeed())
+	})
+})
@@ -0,0 +1,280 @@
+package emitter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const (
+	// elasticsearchDatePlaceholder is replaced with the date of the event in the
+	// configured index name, allowing for daily indices
+	elasticsearchDatePlaceholder = "{date}"
+
+	elasticsearchMaxAttempts = 3
+)
+
+type ElasticsearchEmitter struct {
+	client     *http.Client
+	url        string
+	index      string
+	username   string
+	password   string
+	headers    *requestHeaders
+	compressor *compressor
+	proxy      *proxyCheck
+}
+
+type ElasticsearchConfig struct {
+	URL   string `long:"elasticsearch-url" description:"Elasticsearch server address to index metrics in."`
+	Index string `long:"elasticsearch-index" default:"concourse-metrics-{date}" description:"Index to write metrics to. Any {date} placeholder is replaced with the date of the metric, e.g. 2006.01.02."`
+
+	Username string `long:"elasticsearch-username" description:"Elasticsearch basic auth username."`
+	Password string `long:"elasticsearch-password" description:"Elasticsearch basic auth password."`
+
+	Headers HeaderConfig `group:"Elasticsearch Headers" namespace:"elasticsearch"`
+	TLS     TLSConfig    `group:"Elasticsearch TLS" namespace:"elasticsearch"`
+	Proxy   ProxyConfig  `group:"Elasticsearch Proxy" namespace:"elasticsearch"`
+
+	Compress bool `long:"elasticsearch-compress" description:"Gzip-compress request bodies larger than 1KB. Falls back to uncompressed requests if Elasticsearch does not accept them."`
+}
+
+type elasticsearchDocument struct {
+	Index string
+	Body  []byte
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+func init() {
+	metric.RegisterEmitter(&ElasticsearchConfig{})
+}
+
+func (config *ElasticsearchConfig) Description() string { return "Elasticsearch" }
+func (config *ElasticsearchConfig) FlagPrefix() string  { return "elasticsearch" }
+func (config *ElasticsearchConfig) IsConfigured() bool  { return config.URL != "" }
+
+func (config *ElasticsearchConfig) NewEmitter() (metric.Emitter, error) {
+	headers, err := config.Headers.requestHeaders("elasticsearch")
+	if err != nil {
+		return &ElasticsearchEmitter{}, err
+	}
+
+	tlsConfig, err := config.TLS.TLSClientConfig()
+	if err != nil {
+		return &ElasticsearchEmitter{}, err
+	}
+
+	bulkURL := strings.TrimSuffix(config.URL, "/") + "/_bulk"
+
+	transport, proxy, err := config.Proxy.transport("elasticsearch", bulkURL, tlsConfig)
+	if err != nil {
+		return &ElasticsearchEmitter{}, err
+	}
+
+	return &ElasticsearchEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:      bulkURL,
+		index:    config.Index,
+		username: config.Username,
+		password: config.Password,
+		headers:  headers,
+
+		compressor: newCompressor(config.Compress),
+		proxy:      proxy,
+	}, nil
+}
+
+func (emitter *ElasticsearchEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *ElasticsearchEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	return emitter.TryEmitBatch(logger, []metric.Event{event})
+}
+
+func (emitter *ElasticsearchEmitter) EmitBatch(logger lager.Logger, events []metric.Event) {
+	err := emitter.TryEmitBatch(logger, events)
+	if err != nil {
+		logger.Error("failed-to-send-metrics",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()), lager.Data{
+				"metrics": len(events),
+			})
+	}
+}
+
+// TryEmitBatch indexes the events in a single bulk request, retrying the
+// documents which were rejected.
+func (emitter *ElasticsearchEmitter) TryEmitBatch(logger lager.Logger, events []metric.Event) error {
+	documents := []elasticsearchDocument{}
+	for _, event := range events {
+		body, err := json.Marshal(map[string]interface{}{
+			"@timestamp": event.Timestamp(),
+			"name":       event.Name,
+			"value":      eventValue(event.Value),
+			"host":       event.Host,
+			"state":      string(event.State),
+			"attributes": event.Attributes,
+		})
+		if err != nil {
+			logger.Error("failed-to-serialize-document", err)
+			continue
+		}
+
+		documents = append(documents, elasticsearchDocument{
+			Index: strings.Replace(emitter.index, elasticsearchDatePlaceholder, event.Timestamp().UTC().Format("2006.01.02"), -1),
+			Body:  body,
+		})
+	}
+
+	return emitter.bulk(logger, documents)
+}
+
+func (emitter *ElasticsearchEmitter) Close() error {
+	return nil
+}
+
+func (emitter *ElasticsearchEmitter) bulk(logger lager.Logger, documents []elasticsearchDocument) error {
+	for attempt := 1; len(documents) > 0; attempt++ {
+		failed, err := emitter.send(logger, documents)
+		if err != nil {
+			logger.Error("failed-to-send-documents",
+				errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+
+			// some of the documents were indexed already, so the batch must
+			// not be sent again as a whole
+			if attempt > 1 {
+				return nil
+			}
+
+			return err
+		}
+
+		retryable := []elasticsearchDocument{}
+		for _, failure := range failed {
+			// rejections due to back-pressure or node failures are worth retrying,
+			// anything else (e.g. mapping conflicts) will fail again
+			if failure.status == http.StatusTooManyRequests || failure.status >= 500 {
+				retryable = append(retryable, failure.document)
+				continue
+			}
+
+			logger.Error("failed-to-index-document", errors.Wrap(metric.ErrFailedToEmit, failure.reason), lager.Data{
+				"index":    failure.document.Index,
+				"document": string(failure.document.Body),
+			})
+		}
+
+		if len(retryable) > 0 && attempt == elasticsearchMaxAttempts {
+			for _, document := range retryable {
+				logger.Error("failed-to-index-document", errors.Wrap(metric.ErrFailedToEmit, "retries exhausted"), lager.Data{
+					"index":    document.Index,
+					"document": string(document.Body),
+				})
+			}
+
+			return nil
+		}
+
+		documents = retryable
+	}
+
+	return nil
+}
+
+type elasticsearchFailure struct {
+	document elasticsearchDocument
+	status   int
+	reason   string
+}
+
+func (emitter *ElasticsearchEmitter) send(logger lager.Logger, documents []elasticsearchDocument) ([]elasticsearchFailure, error) {
+	body := &bytes.Buffer{}
+	for _, document := range documents {
+		action, err := json.Marshal(map[string]interface{}{
+			"index": map[string]string{
+				"_index": document.Index,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		body.Write(action)
+		body.WriteString("\n")
+		body.Write(document.Body)
+		body.WriteString("\n")
+	}
+
+	header := http.Header{
+		"Content-Type": {"application/x-ndjson"},
+	}
+
+	if emitter.username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(emitter.username + ":" + emitter.password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+
+	header, err := emitter.headers.with(logger, header)
+	if err != nil {
+		return nil, err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	respBody, err := emitter.compressor.post(logger, emitter.client, emitter.url, header, body.Bytes(), 3, retryServerErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	var response elasticsearchBulkResponse
+	err = json.Unmarshal(respBody, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if !response.Errors {
+		return nil, nil
+	}
+
+	failures := []elasticsearchFailure{}
+	for i, item := range response.Items {
+		if i >= len(documents) {
+			break
+		}
+
+		for _, result := range item {
+			if result.Status >= 200 && result.Status < 300 {
+				continue
+			}
+
+			failures = append(failures, elasticsearchFailure{
+				document: documents[i],
+				status:   result.Status,
+				reason:   string(result.Error),
+			})
+		}
+	}
+
+	return failures, nil
+}
@@ -0,0 +1,136 @@
+package emitter
+
+import (
+	"bufio"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type FileEmitter struct {
+	file   *lumberjack.Logger
+	writer *bufio.Writer
+
+	logger lager.Logger
+	mu     sync.Mutex
+}
+
+type FileConfig struct {
+	Path          string        `long:"metrics-file" description:"Path of a file to write metrics to, one JSON object per line."`
+	MaxSizeMB     int           `long:"metrics-file-max-size-mb" default:"100" description:"Size in megabytes at which the metrics file is rotated."`
+	MaxBackups    int           `long:"metrics-file-max-backups" default:"5" description:"Number of rotated metrics files to keep. 0 keeps all of them."`
+	FlushInterval time.Duration `long:"metrics-file-flush-interval" default:"10s" description:"Interval on which to flush buffered metrics to the file."`
+}
+
+type fileEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+func init() {
+	metric.RegisterEmitter(&FileConfig{})
+}
+
+func (config *FileConfig) Description() string { return "File" }
+func (config *FileConfig) FlagPrefix() string  { return "metrics-file" }
+func (config *FileConfig) IsConfigured() bool  { return config.Path != "" }
+
+func (config *FileConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("metrics-file-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &FileEmitter{}, err
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   config.Path,
+		MaxSize:    config.MaxSizeMB,
+		MaxBackups: config.MaxBackups,
+	}
+
+	emitter := &FileEmitter{
+		file:   file,
+		writer: bufio.NewWriter(file),
+	}
+
+	go emitter.periodicallyFlush(config.FlushInterval)
+
+	return emitter, nil
+}
+
+func (emitter *FileEmitter) Emit(logger lager.Logger, event metric.Event) {
+	payload, err := json.Marshal(fileEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+	if err != nil {
+		logger.Error("failed-to-serialize-event", err)
+		return
+	}
+
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	emitter.logger = logger
+
+	_, err = emitter.writer.Write(append(payload, '\n'))
+	if err != nil {
+		emitter.dropBuffered(logger, err)
+	}
+}
+
+// Close flushes any buffered metrics and closes the file.
+func (emitter *FileEmitter) Close() error {
+	emitter.Flush()
+
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	return emitter.file.Close()
+}
+
+func (emitter *FileEmitter) Flush() {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	if emitter.writer.Buffered() == 0 {
+		return
+	}
+
+	err := emitter.writer.Flush()
+	if err != nil && emitter.logger != nil {
+		emitter.dropBuffered(emitter.logger, err)
+	}
+}
+
+// dropBuffered discards whatever could not be written, e.g. because the disk
+// is full. A bufio.Writer stays failed after an error, so it is replaced with
+// a fresh one to try again on the next write.
+func (emitter *FileEmitter) dropBuffered(logger lager.Logger, err error) {
+	logger.Error("failed-to-write-metrics", errors.Wrap(metric.ErrFailedToEmit, err.Error()), lager.Data{
+		"dropped-bytes": emitter.writer.Buffered(),
+	})
+
+	emitter.writer = bufio.NewWriter(emitter.file)
+}
+
+func (emitter *FileEmitter) periodicallyFlush(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		emitter.Flush()
+	}
+}
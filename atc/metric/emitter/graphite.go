@@ -0,0 +1,75 @@
+package emitter
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+type GraphiteEmitter struct {
+	writer *tcpWriter
+	prefix string
+}
+
+type GraphiteConfig struct {
+	Host   string `long:"graphite-host"                      description:"Graphite server address to emit metrics to."`
+	Port   uint16 `long:"graphite-port"   default:"2003"      description:"Port of the Graphite server's plaintext listener."`
+	Prefix string `long:"graphite-prefix" default:"concourse" description:"Prefix for all metrics to easily find them in Graphite."`
+
+	MaxConns int `long:"graphite-max-conns" default:"1" description:"Number of connections to the Graphite server to write metrics over in turn."`
+}
+
+func init() {
+	metric.RegisterEmitter(&GraphiteConfig{})
+}
+
+func (config *GraphiteConfig) Description() string { return "Graphite" }
+func (config *GraphiteConfig) FlagPrefix() string  { return "graphite" }
+func (config *GraphiteConfig) IsConfigured() bool  { return config.Host != "" }
+
+func (config *GraphiteConfig) NewEmitter() (metric.Emitter, error) {
+	return &GraphiteEmitter{
+		writer: newTCPWriter(net.JoinHostPort(config.Host, fmt.Sprintf("%d", config.Port)), config.MaxConns),
+		prefix: strings.TrimSuffix(config.Prefix, "."),
+	}, nil
+}
+
+func (emitter *GraphiteEmitter) Emit(logger lager.Logger, event metric.Event) {
+	name := normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-graphite", nil, lager.Data{
+			"metric-name": name,
+		})
+		return
+	}
+
+	// graphite has no notion of tags, so the host and state become part of the
+	// metric path
+	path := []string{
+		pathComponent(event.Host),
+		pathComponent(string(event.State)),
+		name,
+	}
+
+	if emitter.prefix != "" {
+		path = append([]string{emitter.prefix}, path...)
+	}
+
+	emitter.writer.Write(logger, fmt.Sprintf(
+		"%s %s %d\n",
+		strings.Join(path, "."),
+		strconv.FormatFloat(value, 'f', -1, 64),
+		event.Timestamp().Unix(),
+	))
+}
+
+// Close closes the connection to Graphite.
+func (emitter *GraphiteEmitter) Close() error {
+	return emitter.writer.Close()
+}
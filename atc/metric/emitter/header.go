@@ -0,0 +1,101 @@
+package emitter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// redactedHeaderValue replaces the values of headers when logging them, as
+// they may carry credentials.
+const redactedHeaderValue = "<redacted>"
+
+// HeaderConfig configures the headers sent with each request by emitters
+// which send metrics over HTTP, e.g. to authenticate with a proxy. Each
+// emitter namespaces the flags, e.g. --webhook-header.
+type HeaderConfig struct {
+	Headers   []string `long:"header"     description:"Header to send with each request, in the form 'Key: Value'. Can be specified multiple times." value-name:"KEY:VALUE"`
+	TokenFile string   `long:"token-file" description:"Path to a file containing a bearer token to send in the Authorization header. The file is read before each request, so that rotated tokens are picked up without a restart."`
+}
+
+// requestHeaders adds the configured headers to those of each request.
+type requestHeaders struct {
+	header    http.Header
+	tokenFile string
+}
+
+// requestHeaders parses the headers, failing if any of them is malformed or
+// if the token file cannot be read.
+func (config HeaderConfig) requestHeaders(prefix string) (*requestHeaders, error) {
+	headers := &requestHeaders{
+		header:    http.Header{},
+		tokenFile: config.TokenFile,
+	}
+
+	for _, h := range config.Headers {
+		segs := strings.SplitN(h, ":", 2)
+		if len(segs) != 2 || strings.TrimSpace(segs[0]) == "" {
+			return nil, fmt.Errorf("invalid %s header '%s': must be in the form 'Key: Value'", prefix, h)
+		}
+
+		headers.header.Add(strings.TrimSpace(segs[0]), strings.TrimSpace(segs[1]))
+	}
+
+	if config.TokenFile != "" {
+		_, err := headers.token()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return headers, nil
+}
+
+// with returns the header merged with the configured headers, reading the
+// token file again if there is one. The headers are logged at debug level,
+// with their values redacted.
+func (headers *requestHeaders) with(logger lager.Logger, header http.Header) (http.Header, error) {
+	merged := http.Header{}
+	for k, vs := range header {
+		merged[k] = vs
+	}
+
+	for k, vs := range headers.header {
+		merged[k] = vs
+	}
+
+	if headers.tokenFile != "" {
+		token, err := headers.token()
+		if err != nil {
+			return nil, err
+		}
+
+		merged.Set("Authorization", "Bearer "+token)
+	}
+
+	redacted := lager.Data{}
+	for k := range merged {
+		redacted[k] = redactedHeaderValue
+	}
+
+	logger.Debug("request-headers", redacted)
+
+	return merged, nil
+}
+
+func (headers *requestHeaders) token() (string, error) {
+	contents, err := ioutil.ReadFile(headers.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %s", err)
+	}
+
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return "", fmt.Errorf("token file '%s' is empty", headers.tokenFile)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,137 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type HoneycombEmitter struct {
+	client  *http.Client
+	url     string
+	apiKey  string
+	proxy   *proxyCheck
+	batcher *batcher
+}
+
+type HoneycombConfig struct {
+	APIKey  string `long:"honeycomb-api-key" description:"Honeycomb API key to authenticate with."`
+	Dataset string `long:"honeycomb-dataset" description:"Honeycomb dataset to send events to."`
+	APIURL  string `long:"honeycomb-api-url" default:"https://api.honeycomb.io" description:"Honeycomb API URL to send events to."`
+
+	Proxy ProxyConfig `group:"Honeycomb Proxy" namespace:"honeycomb"`
+
+	BatchSize     int           `long:"honeycomb-batch-size"     default:"100" description:"Number of events to send to Honeycomb in a single request."`
+	FlushInterval time.Duration `long:"honeycomb-flush-interval" default:"10s" description:"Interval on which to flush batched events to Honeycomb, regardless of the batch size."`
+}
+
+type honeycombEvent struct {
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data"`
+}
+
+func init() {
+	metric.RegisterEmitter(&HoneycombConfig{})
+}
+
+func (config *HoneycombConfig) Description() string { return "Honeycomb" }
+func (config *HoneycombConfig) FlagPrefix() string  { return "honeycomb" }
+func (config *HoneycombConfig) IsConfigured() bool {
+	return config.APIKey != "" && config.Dataset != ""
+}
+
+func (config *HoneycombConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("honeycomb", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &HoneycombEmitter{}, err
+	}
+
+	batchURL := fmt.Sprintf("%s/1/batch/%s", strings.TrimSuffix(config.APIURL, "/"), url.PathEscape(config.Dataset))
+
+	transport, proxy, err := config.Proxy.transport("honeycomb", batchURL, nil)
+	if err != nil {
+		return &HoneycombEmitter{}, err
+	}
+
+	emitter := &HoneycombEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:    batchURL,
+		apiKey: config.APIKey,
+		proxy:  proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.send)
+
+	return emitter, nil
+}
+
+func (emitter *HoneycombEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *HoneycombEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	data := map[string]interface{}{}
+	for k, v := range event.Attributes {
+		data[k] = v
+	}
+
+	data["name"] = event.Name
+	data["host"] = event.Host
+	data["state"] = string(event.State)
+
+	// honeycomb handles arbitrary columns, so values which aren't numeric are
+	// kept as strings rather than dropped
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		data["value"] = fmt.Sprintf("%v", event.Value)
+	} else {
+		data["value"] = value
+	}
+
+	return emitter.batcher.Add(logger, honeycombEvent{
+		Time: event.Timestamp(),
+		Data: data,
+	})
+}
+
+// Close flushes any batched events.
+func (emitter *HoneycombEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+func (emitter *HoneycombEmitter) send(logger lager.Logger, events []interface{}) error {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		logger.Error("failed-to-serialize-payload", err)
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = post(emitter.client, emitter.url, http.Header{
+		"Content-Type":     {"application/json"},
+		"X-Honeycomb-Team": {emitter.apiKey},
+	}, payload, 3, retryServerErrors)
+	if err != nil {
+		logger.Error("failed-to-send-events",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
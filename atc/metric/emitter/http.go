@@ -0,0 +1,140 @@
+package emitter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cenkalti/backoff"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+// compressMinBytes is the size from which request bodies are compressed, as
+// compressing smaller ones costs more than it saves.
+const compressMinBytes = 1024
+
+// httpStatusError is returned when a backend responds with a non-2xx status.
+type httpStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (err httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected response status %d: %s", err.StatusCode, err.Body)
+}
+
+// retryServerErrors retries on any 5xx response.
+func retryServerErrors(statusCode int) bool {
+	return statusCode >= 500
+}
+
+// post sends the body to the given URL, retrying up to maxRetries times with
+// exponential backoff on network errors and on any response status for which
+// shouldRetry returns true. The body of the successful response is returned.
+//
+// Errors which were retried are returned as metric.TransientErrors, as they
+// may not happen again.
+func post(client *http.Client, url string, header http.Header, body []byte, maxRetries uint64, shouldRetry func(int) bool) ([]byte, error) {
+	var respBody []byte
+
+	err := backoff.Retry(func() error {
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+
+		for k, vs := range header {
+			req.Header[k] = vs
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return metric.TransientError{Err: err}
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			respBody, err = ioutil.ReadAll(resp.Body)
+			return err
+		}
+
+		errBody, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+
+		statusErr := httpStatusError{
+			StatusCode: resp.StatusCode,
+			Body:       string(errBody),
+		}
+
+		if shouldRetry(resp.StatusCode) {
+			return metric.TransientError{Err: statusErr}
+		}
+
+		return backoff.Permanent(statusErr)
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), maxRetries))
+
+	return respBody, err
+}
+
+// compressor gzip-compresses request bodies while enabled. It disables itself
+// for good once the backend responds with 415 Unsupported Media Type.
+type compressor struct {
+	enabled int32
+}
+
+func newCompressor(enabled bool) *compressor {
+	c := &compressor{}
+	if enabled {
+		c.enabled = 1
+	}
+
+	return c
+}
+
+// post sends the body like the post function, but compresses bodies of at
+// least compressMinBytes, sending them again uncompressed if compression is
+// rejected.
+func (c *compressor) post(logger lager.Logger, client *http.Client, url string, header http.Header, body []byte, maxRetries uint64, shouldRetry func(int) bool) ([]byte, error) {
+	if atomic.LoadInt32(&c.enabled) == 0 || len(body) < compressMinBytes {
+		return post(client, url, header, body, maxRetries, shouldRetry)
+	}
+
+	compressed := bytes.Buffer{}
+
+	writer := gzip.NewWriter(&compressed)
+
+	_, err := writer.Write(body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	gzipHeader := http.Header{}
+	for k, vs := range header {
+		gzipHeader[k] = vs
+	}
+
+	gzipHeader.Set("Content-Encoding", "gzip")
+
+	respBody, err := post(client, url, gzipHeader, compressed.Bytes(), maxRetries, shouldRetry)
+	if statusErr, ok := err.(httpStatusError); ok && statusErr.StatusCode == http.StatusUnsupportedMediaType {
+		atomic.StoreInt32(&c.enabled, 0)
+
+		logger.Info("disabled-compression", lager.Data{
+			"reason": statusErr.Error(),
+		})
+
+		return post(client, url, header, body, maxRetries, shouldRetry)
+	}
+
+	return respBody, err
+}
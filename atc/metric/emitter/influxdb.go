@@ -1,6 +1,7 @@
 package emitter
 
 import (
+	"net/url"
 	"time"
 
 	"code.cloudfoundry.org/lager"
@@ -23,7 +24,9 @@ type InfluxDBConfig struct {
 	Username string `long:"influxdb-username" description:"InfluxDB server username."`
 	Password string `long:"influxdb-password" description:"InfluxDB server password."`
 
-	InsecureSkipVerify bool `long:"influxdb-insecure-skip-verify" description:"Skip SSL verification when emitting to InfluxDB."`
+	InsecureSkipVerify bool `long:"influxdb-insecure-skip-verify" description:"Skip SSL verification when emitting to InfluxDB. Deprecated in favour of --influxdb-skip-verify."`
+
+	TLS TLSConfig `group:"InfluxDB TLS" namespace:"influxdb"`
 }
 
 func init() {
@@ -31,15 +34,23 @@ func init() {
 }
 
 func (config *InfluxDBConfig) Description() string { return "InfluxDB" }
+func (config *InfluxDBConfig) FlagPrefix() string  { return "influxdb" }
 func (config *InfluxDBConfig) IsConfigured() bool  { return config.URL != "" }
 
 func (config *InfluxDBConfig) NewEmitter() (metric.Emitter, error) {
+	tlsConfig, err := config.TLS.TLSClientConfig()
+	if err != nil {
+		return &InfluxDBEmitter{}, err
+	}
+
+	tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || config.InsecureSkipVerify
+
 	client, err := influxclient.NewHTTPClient(influxclient.HTTPConfig{
-		Addr:               config.URL,
-		Username:           config.Username,
-		Password:           config.Password,
-		InsecureSkipVerify: config.InsecureSkipVerify,
-		Timeout:            time.Minute,
+		Addr:      config.URL,
+		Username:  config.Username,
+		Password:  config.Password,
+		TLSConfig: tlsConfig,
+		Timeout:   time.Minute,
 	})
 	if err != nil {
 		return &InfluxDBEmitter{}, err
@@ -52,12 +63,78 @@ func (config *InfluxDBConfig) NewEmitter() (metric.Emitter, error) {
 }
 
 func (emitter *InfluxDBEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *InfluxDBEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	return emitter.TryEmitBatch(logger, []metric.Event{event})
+}
+
+func (emitter *InfluxDBEmitter) EmitBatch(logger lager.Logger, events []metric.Event) {
+	err := emitter.TryEmitBatch(logger, events)
+	if err != nil {
+		logger.Error("failed-to-send-metrics",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()), lager.Data{
+				"metrics": len(events),
+			})
+	}
+}
+
+// TryEmitBatch writes the points of all of the events in a single request.
+func (emitter *InfluxDBEmitter) TryEmitBatch(logger lager.Logger, events []metric.Event) error {
 	bp, err := influxclient.NewBatchPoints(influxclient.BatchPointsConfig{
 		Database: emitter.database,
 	})
 	if err != nil {
 		logger.Error("failed-to-construct-batch-points", err)
-		return
+		return err
+	}
+
+	for _, event := range events {
+		point, ok := emitter.point(logger, event)
+		if ok {
+			bp.AddPoint(point)
+		}
+	}
+
+	if len(bp.Points()) == 0 {
+		return nil
+	}
+
+	err = emitter.client.Write(bp)
+	if err != nil {
+		logger.Error("failed-to-send-points",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+
+		// the client does not expose the response status, so only requests
+		// which could not be made at all are known to be worth retrying
+		if _, ok := err.(*url.Error); ok {
+			return metric.TransientError{Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (emitter *InfluxDBEmitter) Close() error {
+	return emitter.client.Close()
+}
+
+func (emitter *InfluxDBEmitter) point(logger lager.Logger, event metric.Event) (*influxclient.Point, bool) {
+	// the value is only checked to be numeric rather than converted, so that
+	// existing measurements keep their field types
+	_, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-influxdb", nil, lager.Data{
+			"metric-name": event.Name,
+		})
+		return nil, false
 	}
 
 	tags := map[string]string{
@@ -72,22 +149,15 @@ func (emitter *InfluxDBEmitter) Emit(logger lager.Logger, event metric.Event) {
 		event.Name,
 		tags,
 		map[string]interface{}{
-			"value": event.Value,
+			"value": eventValue(event.Value),
 			"state": string(event.State),
 		},
-		event.Time,
+		event.Timestamp(),
 	)
 	if err != nil {
 		logger.Error("failed-to-construct-point", err)
-		return
+		return nil, false
 	}
 
-	bp.AddPoint(point)
-
-	err = emitter.client.Write(bp)
-	if err != nil {
-		logger.Error("failed-to-send-points",
-			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
-		return
-	}
+	return point, true
 }
@@ -0,0 +1,228 @@
+package emitter
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/Shopify/sarama"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+// kafkaDroppedInterval is how often the number of metrics dropped since the
+// last time is logged, so that a full buffer does not log once per metric.
+const kafkaDroppedInterval = time.Minute
+
+type KafkaEmitter struct {
+	producer     sarama.AsyncProducer
+	topic        string
+	partitionKey string
+
+	messages chan *sarama.ProducerMessage
+	dropped  uint64
+	done     chan struct{}
+
+	stop        chan struct{}
+	droppedDone chan struct{}
+
+	logger lager.Logger
+	mu     sync.Mutex
+}
+
+type KafkaConfig struct {
+	Brokers      string `long:"kafka-brokers" description:"Comma-separated list of Kafka brokers to produce metrics to."`
+	Topic        string `long:"kafka-topic" default:"concourse-metrics" description:"Kafka topic to produce metrics to."`
+	PartitionKey string `long:"kafka-partition-key" description:"Metric attribute to use as the message key. Defaults to the host, so that all metrics of an ATC land on the same partition."`
+	BufferSize   int    `long:"kafka-buffer-size" default:"10000" description:"Number of metrics to buffer while the brokers are unavailable. The oldest metrics are dropped once full."`
+}
+
+type kafkaEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+func init() {
+	metric.RegisterEmitter(&KafkaConfig{})
+}
+
+func (config *KafkaConfig) Description() string { return "Kafka" }
+func (config *KafkaConfig) FlagPrefix() string  { return "kafka" }
+func (config *KafkaConfig) IsConfigured() bool  { return config.Brokers != "" }
+
+func (config *KafkaConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkSize("kafka-buffer-size", config.BufferSize)
+	if err != nil {
+		return &KafkaEmitter{}, err
+	}
+
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Producer.Return.Errors = true
+
+	producer, err := sarama.NewAsyncProducer(strings.Split(config.Brokers, ","), saramaConfig)
+	if err != nil {
+		return &KafkaEmitter{}, err
+	}
+
+	emitter := &KafkaEmitter{
+		producer:     producer,
+		topic:        config.Topic,
+		partitionKey: config.PartitionKey,
+
+		messages: make(chan *sarama.ProducerMessage, config.BufferSize),
+		done:     make(chan struct{}),
+
+		stop:        make(chan struct{}),
+		droppedDone: make(chan struct{}),
+	}
+
+	go emitter.produce()
+	go emitter.logErrors()
+	go emitter.periodicallyLogDropped()
+
+	return emitter, nil
+}
+
+func (emitter *KafkaEmitter) Emit(logger lager.Logger, event metric.Event) {
+	emitter.EmitBatch(logger, []metric.Event{event})
+}
+
+// EmitBatch buffers a message for each of the events. The producer batches
+// the messages by partition by itself.
+func (emitter *KafkaEmitter) EmitBatch(logger lager.Logger, events []metric.Event) {
+	emitter.mu.Lock()
+	emitter.logger = logger
+	emitter.mu.Unlock()
+
+	for _, event := range events {
+		message, err := emitter.message(event)
+		if err != nil {
+			logger.Error("failed-to-serialize-event", err)
+			continue
+		}
+
+		emitter.buffer(message)
+	}
+}
+
+// Close flushes any buffered metrics and closes the producer.
+func (emitter *KafkaEmitter) Close() error {
+	close(emitter.messages)
+	<-emitter.done
+
+	close(emitter.stop)
+	<-emitter.droppedDone
+
+	return emitter.producer.Close()
+}
+
+func (emitter *KafkaEmitter) produce() {
+	defer close(emitter.done)
+
+	for message := range emitter.messages {
+		emitter.producer.Input() <- message
+	}
+}
+
+func (emitter *KafkaEmitter) logErrors() {
+	for err := range emitter.producer.Errors() {
+		logger := emitter.currentLogger()
+		if logger == nil {
+			continue
+		}
+
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *KafkaEmitter) periodicallyLogDropped() {
+	defer close(emitter.droppedDone)
+
+	ticker := time.NewTicker(kafkaDroppedInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emitter.logDropped()
+		case <-emitter.stop:
+			emitter.logDropped()
+			return
+		}
+	}
+}
+
+// logDropped logs the number of metrics dropped since it was last called.
+func (emitter *KafkaEmitter) logDropped() {
+	logger := emitter.currentLogger()
+	if logger == nil {
+		return
+	}
+
+	dropped := atomic.SwapUint64(&emitter.dropped, 0)
+	if dropped > 0 {
+		logger.Info("dropped-oldest-metrics", lager.Data{
+			"dropped": dropped,
+		})
+	}
+}
+
+// currentLogger returns the logger the emitter was last given, which is nil
+// until it is first given events.
+func (emitter *KafkaEmitter) currentLogger() lager.Logger {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	return emitter.logger
+}
+
+func (emitter *KafkaEmitter) message(event metric.Event) (*sarama.ProducerMessage, error) {
+	payload, err := json.Marshal(kafkaEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key := event.Host
+	if emitter.partitionKey != "" {
+		key = event.Attributes[emitter.partitionKey]
+	}
+
+	return &sarama.ProducerMessage{
+		Topic: emitter.topic,
+		Key:   sarama.StringEncoder(key),
+		Value: sarama.ByteEncoder(payload),
+	}, nil
+}
+
+func (emitter *KafkaEmitter) buffer(message *sarama.ProducerMessage) {
+	for {
+		select {
+		case emitter.messages <- message:
+			return
+		default:
+		}
+
+		// the buffer is full, most likely because the brokers are unavailable;
+		// make room by dropping the oldest message
+		select {
+		case <-emitter.messages:
+			atomic.AddUint64(&emitter.dropped, 1)
+		default:
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package emitter
+
+import (
+	"encoding/json"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/cenkalti/backoff"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const (
+	// kinesisMaxRecords is the maximum number of records accepted by a single
+	// PutRecords call
+	kinesisMaxRecords = 500
+
+	// kinesisMaxAttempts is the number of times a batch is sent before the
+	// records that keep failing are dropped
+	kinesisMaxAttempts = 5
+)
+
+type KinesisEmitter struct {
+	client     kinesisiface.KinesisAPI
+	streamName string
+	batcher    *batcher
+}
+
+type KinesisConfig struct {
+	StreamName    string        `long:"kinesis-stream-name"                   description:"Kinesis stream to put metric records to."`
+	Region        string        `long:"kinesis-region"                        description:"AWS region of the Kinesis stream. Defaults to the region configured for the AWS SDK."`
+	FlushInterval time.Duration `long:"kinesis-flush-interval" default:"10s" description:"Interval on which to flush batched records to Kinesis."`
+}
+
+type kinesisEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+func init() {
+	metric.RegisterEmitter(&KinesisConfig{})
+}
+
+func (config *KinesisConfig) Description() string { return "Kinesis" }
+func (config *KinesisConfig) FlagPrefix() string  { return "kinesis" }
+func (config *KinesisConfig) IsConfigured() bool  { return config.StreamName != "" }
+
+func (config *KinesisConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("kinesis-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &KinesisEmitter{}, err
+	}
+
+	awsConfig := aws.NewConfig()
+	if config.Region != "" {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return &KinesisEmitter{}, err
+	}
+
+	return NewKinesisEmitter(kinesis.New(sess), config.StreamName, config.FlushInterval), nil
+}
+
+// NewKinesisEmitter returns an emitter which puts records to the stream with
+// the given client, in batches flushed on the interval.
+func NewKinesisEmitter(client kinesisiface.KinesisAPI, streamName string, flushInterval time.Duration) *KinesisEmitter {
+	emitter := &KinesisEmitter{
+		client:     client,
+		streamName: streamName,
+	}
+
+	emitter.batcher = newBatcher(kinesisMaxRecords, flushInterval, emitter.putRecords)
+
+	return emitter
+}
+
+func (emitter *KinesisEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *KinesisEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	payload, err := json.Marshal(kinesisEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+	if err != nil {
+		logger.Error("failed-to-serialize-event", err)
+		return nil
+	}
+
+	// keying by host keeps the records of an ATC on a single shard, in order
+	return emitter.batcher.Add(logger, &kinesis.PutRecordsRequestEntry{
+		Data:         payload,
+		PartitionKey: aws.String(event.Host),
+	})
+}
+
+// Close flushes any batched records.
+func (emitter *KinesisEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+func (emitter *KinesisEmitter) putRecords(logger lager.Logger, items []interface{}) error {
+	records := make([]*kinesis.PutRecordsRequestEntry, len(items))
+	for i, item := range items {
+		records[i] = item.(*kinesis.PutRecordsRequestEntry)
+	}
+
+	retry := backoff.NewExponentialBackOff()
+
+	for attempt := 1; ; attempt++ {
+		output, err := emitter.client.PutRecords(&kinesis.PutRecordsInput{
+			StreamName: aws.String(emitter.streamName),
+			Records:    records,
+		})
+		if err != nil {
+			logger.Error("failed-to-send-metric",
+				errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+
+			// some of the records were put already, so the batch must not be
+			// sent again as a whole
+			if attempt > 1 {
+				return nil
+			}
+
+			return awsError(err)
+		}
+
+		if aws.Int64Value(output.FailedRecordCount) == 0 {
+			return nil
+		}
+
+		// records fail individually, most commonly because a shard's write
+		// throughput was exceeded; only the failed ones are sent again
+		var failed []*kinesis.PutRecordsRequestEntry
+		var errorCode string
+		for i, result := range output.Records {
+			if result.ErrorCode != nil {
+				failed = append(failed, records[i])
+				errorCode = aws.StringValue(result.ErrorCode)
+			}
+		}
+
+		if attempt == kinesisMaxAttempts {
+			logger.Error("failed-to-send-metric",
+				errors.Wrap(metric.ErrFailedToEmit, errorCode),
+				lager.Data{"dropped": len(failed)})
+			return nil
+		}
+
+		records = failed
+
+		time.Sleep(retry.NextBackOff())
+	}
+}
@@ -0,0 +1,180 @@
+package emitter_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeKinesis records the names of the metrics put with each call, and fails
+// the records for which fail returns true.
+type fakeKinesis struct {
+	kinesisiface.KinesisAPI
+
+	fail func(call int, name string) bool
+	err  func(call int) error
+
+	calls  [][]string
+	stream string
+	keys   []string
+	mu     sync.Mutex
+}
+
+func (fake *fakeKinesis) PutRecords(input *kinesis.PutRecordsInput) (*kinesis.PutRecordsOutput, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	call := len(fake.calls)
+	fake.stream = aws.StringValue(input.StreamName)
+
+	names := []string{}
+	for _, record := range input.Records {
+		var event struct {
+			Name string `json:"name"`
+		}
+		Expect(json.Unmarshal(record.Data, &event)).To(Succeed())
+
+		names = append(names, event.Name)
+		fake.keys = append(fake.keys, aws.StringValue(record.PartitionKey))
+	}
+
+	fake.calls = append(fake.calls, names)
+
+	if fake.err != nil {
+		if err := fake.err(call); err != nil {
+			return nil, err
+		}
+	}
+
+	output := &kinesis.PutRecordsOutput{FailedRecordCount: aws.Int64(0)}
+	for _, name := range names {
+		result := &kinesis.PutRecordsResultEntry{SequenceNumber: aws.String("1")}
+		if fake.fail != nil && fake.fail(call, name) {
+			result = &kinesis.PutRecordsResultEntry{ErrorCode: aws.String("ProvisionedThroughputExceededException")}
+			*output.FailedRecordCount++
+		}
+
+		output.Records = append(output.Records, result)
+	}
+
+	return output, nil
+}
+
+func (fake *fakeKinesis) Calls() [][]string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	return fake.calls
+}
+
+var _ = Describe("KinesisEmitter", func() {
+	var (
+		logger *lagertest.TestLogger
+		client *fakeKinesis
+		e      *emitter.KinesisEmitter
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		client = &fakeKinesis{}
+	})
+
+	JustBeforeEach(func() {
+		e = emitter.NewKinesisEmitter(client, "some-stream", time.Hour)
+
+		for i := 0; i < 3; i++ {
+			Expect(e.TryEmit(logger, metric.Event{
+				Name:  fmt.Sprintf("metric-%d", i),
+				Value: i,
+				Host:  "some-host",
+			})).To(Succeed())
+		}
+
+		Expect(e.Close()).To(Succeed())
+	})
+
+	It("puts the batched records to the stream, keyed by host", func() {
+		Expect(client.Calls()).To(Equal([][]string{
+			{"metric-0", "metric-1", "metric-2"},
+		}))
+		Expect(client.stream).To(Equal("some-stream"))
+		Expect(client.keys).To(Equal([]string{"some-host", "some-host", "some-host"}))
+	})
+
+	Context("when some records fail", func() {
+		BeforeEach(func() {
+			client.fail = func(call int, name string) bool {
+				return call == 0 && name != "metric-1"
+			}
+		})
+
+		It("puts only the failed records again", func() {
+			Expect(client.Calls()).To(Equal([][]string{
+				{"metric-0", "metric-1", "metric-2"},
+				{"metric-0", "metric-2"},
+			}))
+		})
+	})
+
+	Context("when a record keeps failing", func() {
+		BeforeEach(func() {
+			client.fail = func(call int, name string) bool {
+				return name == "metric-1"
+			}
+		})
+
+		It("drops it after the maximum number of attempts", func() {
+			Expect(client.Calls()).To(Equal([][]string{
+				{"metric-0", "metric-1", "metric-2"},
+				{"metric-1"},
+				{"metric-1"},
+				{"metric-1"},
+				{"metric-1"},
+			}))
+
+			dropped := []interface{}{}
+			for _, log := range logger.Logs() {
+				if log.Message == "test.failed-to-send-metric" {
+					dropped = append(dropped, log.Data["dropped"])
+				}
+			}
+
+			Expect(dropped).To(ConsistOf(BeEquivalentTo(1)))
+		})
+	})
+
+	Context("when putting the failed records again fails", func() {
+		BeforeEach(func() {
+			client.fail = func(call int, name string) bool {
+				return call == 0 && name == "metric-1"
+			}
+
+			client.err = func(call int) error {
+				if call == 1 {
+					return errors.New("nope")
+				}
+
+				return nil
+			}
+		})
+
+		It("does not put the batch again, as some of it was put already", func() {
+			Expect(client.Calls()).To(Equal([][]string{
+				{"metric-0", "metric-1", "metric-2"},
+				{"metric-1"},
+			}))
+		})
+	})
+})
@@ -1,16 +1,26 @@
 package emitter
 
 import (
+	"encoding/json"
+	"io"
+	"os"
 	"strings"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/concourse/concourse/atc/metric"
 )
 
-type LagerEmitter struct{}
+type LagerEmitter struct {
+	metric.NopCloser
+
+	// when set, events are written to the writer as indented JSON rather than
+	// through the logger
+	pretty io.Writer
+}
 
 type LagerConfig struct {
 	Enabled bool `long:"emit-to-logs" description:"Emit metrics to logs."`
+	Pretty  bool `long:"emit-to-logs-pretty" description:"Print metrics to stdout as indented JSON rather than lager's format."`
 }
 
 func init() {
@@ -18,16 +28,25 @@ func init() {
 }
 
 func (config *LagerConfig) Description() string { return "Lager" }
+func (config *LagerConfig) FlagPrefix() string  { return "emit-to-logs" }
 func (config *LagerConfig) IsConfigured() bool  { return config.Enabled }
 
 func (config *LagerConfig) NewEmitter() (metric.Emitter, error) {
-	return &LagerEmitter{}, nil
+	emitter := &LagerEmitter{}
+
+	if config.Pretty {
+		emitter.pretty = os.Stdout
+	}
+
+	return emitter, nil
 }
 
 func (emitter *LagerEmitter) Emit(logger lager.Logger, event metric.Event) {
 	data := lager.Data{
 		"name":  event.Name,
-		"value": event.Value,
+		"value": eventValue(event.Value),
+		"host":  event.Host,
+		"state": event.State,
 	}
 
 	for k, v := range event.Attributes {
@@ -36,5 +55,19 @@ func (emitter *LagerEmitter) Emit(logger lager.Logger, event metric.Event) {
 		data[lagerKey] = v
 	}
 
-	logger.Debug("event", data)
+	if emitter.pretty == nil {
+		logger.Info("event", data)
+		return
+	}
+
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		logger.Error("failed-to-serialize-event", err)
+		return
+	}
+
+	_, err = emitter.pretty.Write(append(payload, '\n'))
+	if err != nil {
+		logger.Error("failed-to-print-event", err)
+	}
 }
@@ -0,0 +1,182 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	nats "github.com/nats-io/go-nats"
+	"github.com/pkg/errors"
+)
+
+type NatsEmitter struct {
+	conn           *nats.Conn
+	subject        string
+	jetStream      bool
+	requestTimeout time.Duration
+
+	logger lager.Logger
+	mu     sync.Mutex
+}
+
+type NatsConfig struct {
+	URL             string        `long:"nats-url" description:"NATS server URL to publish metrics to."`
+	Subject         string        `long:"nats-subject" default:"concourse.metrics.{name}" description:"Subject to publish metrics to. {name} is replaced with the metric name."`
+	CredentialsFile string        `long:"nats-credentials-file" description:"Path to a NATS credentials file to authenticate with."`
+	JetStream       bool          `long:"nats-jetstream" description:"Publish to a JetStream stream and wait for the server to acknowledge each metric."`
+	AckTimeout      time.Duration `long:"nats-ack-timeout" default:"5s" description:"How long to wait for JetStream to acknowledge a metric."`
+}
+
+type natsEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+type natsPubAck struct {
+	Stream string `json:"stream"`
+	Error  *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error"`
+}
+
+func init() {
+	metric.RegisterEmitter(&NatsConfig{})
+}
+
+func (config *NatsConfig) Description() string { return "NATS" }
+func (config *NatsConfig) FlagPrefix() string  { return "nats" }
+func (config *NatsConfig) IsConfigured() bool  { return config.URL != "" }
+
+func (config *NatsConfig) NewEmitter() (metric.Emitter, error) {
+	emitter := &NatsEmitter{
+		subject:        config.Subject,
+		jetStream:      config.JetStream,
+		requestTimeout: config.AckTimeout,
+	}
+
+	options := []nats.Option{
+		nats.Name("concourse"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectHandler(func(*nats.Conn) {
+			emitter.log(func(logger lager.Logger) { logger.Info("disconnected") })
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			emitter.log(func(logger lager.Logger) {
+				logger.Info("reconnected", lager.Data{"url": conn.ConnectedUrl()})
+			})
+		}),
+	}
+
+	if config.CredentialsFile != "" {
+		options = append(options, nats.UserCredentials(config.CredentialsFile))
+	}
+
+	conn, err := nats.Connect(config.URL, options...)
+	if err != nil {
+		return &NatsEmitter{}, err
+	}
+
+	emitter.conn = conn
+
+	return emitter, nil
+}
+
+func (emitter *NatsEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+// TryEmit publishes the event, returning a metric.TransientError when the
+// connection is down or JetStream did not acknowledge it in time.
+func (emitter *NatsEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	emitter.mu.Lock()
+	emitter.logger = logger
+	emitter.mu.Unlock()
+
+	payload, err := json.Marshal(natsEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize event")
+	}
+
+	subject := strings.Replace(emitter.subject, "{name}", pathComponent(event.Name), -1)
+
+	if !emitter.jetStream {
+		err = emitter.conn.Publish(subject, payload)
+		if err != nil {
+			return natsError(err)
+		}
+
+		return nil
+	}
+
+	// publishing to a subject bound to a stream is a request; the server
+	// replies with an ack once the message has been persisted
+	reply, err := emitter.conn.Request(subject, payload, emitter.requestTimeout)
+	if err != nil {
+		return natsError(err)
+	}
+
+	var ack natsPubAck
+	err = json.Unmarshal(reply.Data, &ack)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse ack '%s'", string(reply.Data))
+	}
+
+	if ack.Error != nil {
+		err = fmt.Errorf("%s (code %d)", ack.Error.Description, ack.Error.Code)
+
+		// the stream is unavailable, e.g. while a leader is being elected
+		if ack.Error.Code == 503 {
+			return metric.TransientError{Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func natsError(err error) error {
+	switch err {
+	case nats.ErrConnectionClosed, nats.ErrConnectionReconnecting, nats.ErrTimeout, nats.ErrReconnectBufExceeded:
+		return metric.TransientError{Err: err}
+	default:
+		return err
+	}
+}
+
+// Close flushes any pending metrics and closes the connection.
+func (emitter *NatsEmitter) Close() error {
+	err := emitter.conn.Flush()
+	emitter.conn.Close()
+	return err
+}
+
+func (emitter *NatsEmitter) log(f func(lager.Logger)) {
+	emitter.mu.Lock()
+	logger := emitter.logger
+	emitter.mu.Unlock()
+
+	if logger != nil {
+		f(logger)
+	}
+}
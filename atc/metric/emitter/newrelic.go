@@ -1,7 +1,6 @@
 package emitter
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,41 +25,71 @@ type (
 		prefix     string
 		containers *stats
 		volumes    *stats
+		proxy      *proxyCheck
+		batcher    *batcher
 	}
 
 	NewRelicConfig struct {
-		AccountID     string `long:"newrelic-account-id" description:"New Relic Account ID"`
-		APIKey        string `long:"newrelic-api-key" description:"New Relic Insights API Key"`
-		ServicePrefix string `long:"newrelic-service-prefix" default:"" description:"An optional prefix for emitted New Relic events"`
+		AccountID     string        `long:"newrelic-account-id" description:"New Relic Account ID"`
+		APIKey        string        `long:"newrelic-api-key" description:"New Relic Insights API Key"`
+		URL           string        `long:"newrelic-url" default:"https://insights-collector.newrelic.com" description:"New Relic Insights collector URL to send events to"`
+		ServicePrefix string        `long:"newrelic-service-prefix" default:"" description:"An optional prefix for emitted New Relic events"`
+		FlushInterval time.Duration `long:"newrelic-flush-interval" default:"60s" description:"Interval on which to flush buffered events to New Relic"`
+
+		Proxy ProxyConfig `group:"NewRelic Proxy" namespace:"newrelic"`
 	}
 
 	singlePayload map[string]interface{}
 	fullPayload   []singlePayload
 )
 
+const (
+	// Insights rejects requests with more events or larger bodies than this
+	newRelicMaxEvents      = 1000
+	newRelicMaxPayloadSize = 1000000
+)
+
 func init() {
 	metric.RegisterEmitter(&NewRelicConfig{})
 }
 
 func (config *NewRelicConfig) Description() string { return "NewRelic" }
+func (config *NewRelicConfig) FlagPrefix() string  { return "newrelic" }
 func (config *NewRelicConfig) IsConfigured() bool {
 	return config.AccountID != "" && config.APIKey != ""
 }
 
 func (config *NewRelicConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("newrelic-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &NewRelicEmitter{}, err
+	}
+
+	eventsURL := fmt.Sprintf("%s/v1/accounts/%s/events", strings.TrimSuffix(config.URL, "/"), config.AccountID)
+
+	transport, proxy, err := config.Proxy.transport("newrelic", eventsURL, nil)
+	if err != nil {
+		return &NewRelicEmitter{}, err
+	}
+
 	client := &http.Client{
-		Transport: &http.Transport{},
+		Transport: transport,
 		Timeout:   time.Minute,
 	}
 
-	return &NewRelicEmitter{
+	emitter := &NewRelicEmitter{
 		client:     client,
-		url:        fmt.Sprintf("https://insights-collector.newrelic.com/v1/accounts/%s/events", config.AccountID),
+		url:        eventsURL,
+		proxy:      proxy,
 		apikey:     config.APIKey,
 		prefix:     config.ServicePrefix,
 		containers: new(stats),
 		volumes:    new(stats),
-	}, nil
+	}
+
+	emitter.batcher = newBatcher(newRelicMaxEvents, config.FlushInterval, emitter.flush)
+
+	return emitter, nil
 }
 
 func (emitter *NewRelicEmitter) simplePayload(logger lager.Logger, event metric.Event, nameOverride string) singlePayload {
@@ -73,10 +102,10 @@ func (emitter *NewRelicEmitter) simplePayload(logger lager.Logger, event metric.
 
 	payload := singlePayload{
 		"eventType": eventType,
-		"value":     event.Value,
+		"value":     eventValue(event.Value),
 		"state":     string(event.State),
 		"host":      event.Host,
-		"timestamp": event.Time.Unix(),
+		"timestamp": event.Timestamp().Unix(),
 	}
 
 	for k, v := range event.Attributes {
@@ -85,33 +114,86 @@ func (emitter *NewRelicEmitter) simplePayload(logger lager.Logger, event metric.
 	return payload
 }
 
-func (emitter *NewRelicEmitter) emitPayload(logger lager.Logger, payload fullPayload) {
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		logger.Error("failed-to-serialize-payload", err)
-		return
+// flush sends the buffered events, splitting them into as many requests as
+// necessary to stay below the Insights payload size limit.
+func (emitter *NewRelicEmitter) flush(logger lager.Logger, payloads []interface{}) error {
+	chunk := []json.RawMessage{}
+	chunkSize := 2
+
+	// only a failure of the first request is returned, as the events would
+	// be sent again as a whole
+	sent := false
+
+	for _, payload := range payloads {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			logger.Error("failed-to-serialize-payload", err)
+			continue
+		}
+
+		if len(chunk) > 0 && chunkSize+len(payloadJSON)+1 > newRelicMaxPayloadSize {
+			err := emitter.emitPayload(logger, chunk)
+			if err != nil && !sent {
+				return err
+			}
+
+			sent = true
+
+			chunk = []json.RawMessage{}
+			chunkSize = 2
+		}
+
+		chunk = append(chunk, payloadJSON)
+		chunkSize += len(payloadJSON) + 1
+	}
+
+	if len(chunk) > 0 {
+		err := emitter.emitPayload(logger, chunk)
+		if err != nil && !sent {
+			return err
+		}
 	}
 
-	req, err := http.NewRequest("POST", emitter.url, bytes.NewBuffer(payloadJSON))
+	return nil
+}
+
+func (emitter *NewRelicEmitter) emitPayload(logger lager.Logger, payload []json.RawMessage) error {
+	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		logger.Error("failed-to-construct-request", err)
+		logger.Error("failed-to-serialize-payload", err)
+		return err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-Insert-Key", emitter.apikey)
+	emitter.proxy.logUnreachable(logger)
 
-	resp, err := emitter.client.Do(req)
+	_, err = post(emitter.client, emitter.url, http.Header{
+		"Content-Type": {"application/json"},
+		"X-Insert-Key": {emitter.apikey},
+	}, payloadJSON, 0, retryServerErrors)
+	if statusErr, ok := err.(httpStatusError); ok && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+		logger.Error("failed-to-authenticate",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
 
 	if err != nil {
 		logger.Error("failed-to-send-request",
 			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
-		return
+		return err
 	}
 
-	resp.Body.Close()
+	return nil
 }
 
 func (emitter *NewRelicEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *NewRelicEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
 	payload := make(fullPayload, 0)
 
 	switch event.Name {
@@ -179,7 +261,18 @@ func (emitter *NewRelicEmitter) Emit(logger lager.Logger, event metric.Event) {
 		payload = append(payload, singlePayload)
 	}
 
-	if len(payload) > 0 {
-		emitter.emitPayload(logger, payload)
+	for _, singlePayload := range payload {
+		err := emitter.batcher.Add(logger, singlePayload)
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
+}
+
+// Close flushes any batched events.
+func (emitter *NewRelicEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
 }
@@ -0,0 +1,29 @@
+package emitter
+
+import (
+	"github.com/concourse/concourse/atc/metric"
+)
+
+type OpenMetricsConfig struct {
+	Enabled     bool   `long:"openmetrics-enabled" description:"Serve the last value of each metric in the OpenMetrics text format at /api/v1/metrics. Only admins may scrape it unless --openmetrics-scrape-token is set."`
+	ScrapeToken string `long:"openmetrics-scrape-token" description:"Bearer token which lets scrapers which are not admins scrape /api/v1/metrics by presenting it."`
+}
+
+func init() {
+	metric.RegisterEmitter(&OpenMetricsConfig{})
+}
+
+func (config *OpenMetricsConfig) Description() string { return "OpenMetrics" }
+func (config *OpenMetricsConfig) FlagPrefix() string  { return "openmetrics" }
+func (config *OpenMetricsConfig) IsConfigured() bool  { return config.Enabled }
+
+// NewEmitter returns the snapshot served by the API, so that it keeps its
+// values when the emitters are reloaded.
+func (config *OpenMetricsConfig) NewEmitter() (metric.Emitter, error) {
+	return metric.OpenMetrics, nil
+}
+
+// Activate requires the scrape token once the emitters have been swapped in.
+func (config *OpenMetricsConfig) Activate() {
+	metric.OpenMetrics.SetScrapeToken(config.ScrapeToken)
+}
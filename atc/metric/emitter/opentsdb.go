@@ -0,0 +1,162 @@
+package emitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type OpenTSDBEmitter struct {
+	client  *http.Client
+	url     string
+	prefix  string
+	proxy   *proxyCheck
+	batcher *batcher
+}
+
+type OpenTSDBConfig struct {
+	URL    string `long:"opentsdb-url" description:"OpenTSDB server address to emit datapoints to."`
+	Prefix string `long:"opentsdb-prefix" default:"concourse" description:"Prefix for all metrics to easily find them in OpenTSDB."`
+
+	Proxy ProxyConfig `group:"OpenTSDB Proxy" namespace:"opentsdb"`
+
+	BatchSize     int           `long:"opentsdb-batch-size"     default:"50"  description:"Number of datapoints to send to OpenTSDB in a single request."`
+	FlushInterval time.Duration `long:"opentsdb-flush-interval" default:"10s" description:"Interval on which to flush batched datapoints to OpenTSDB, regardless of the batch size."`
+}
+
+type openTSDBDatapoint struct {
+	Metric    string            `json:"metric"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+	Tags      map[string]string `json:"tags"`
+}
+
+// openTSDBInvalidTagChars matches the characters OpenTSDB does not allow in
+// tag keys and values
+var openTSDBInvalidTagChars = regexp.MustCompile("[^a-zA-Z0-9_./-]+")
+
+func init() {
+	metric.RegisterEmitter(&OpenTSDBConfig{})
+}
+
+func (config *OpenTSDBConfig) Description() string { return "OpenTSDB" }
+func (config *OpenTSDBConfig) FlagPrefix() string  { return "opentsdb" }
+func (config *OpenTSDBConfig) IsConfigured() bool  { return config.URL != "" }
+
+func (config *OpenTSDBConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("opentsdb", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &OpenTSDBEmitter{}, err
+	}
+
+	putURL := strings.TrimSuffix(config.URL, "/") + "/api/put"
+
+	transport, proxy, err := config.Proxy.transport("opentsdb", putURL, nil)
+	if err != nil {
+		return &OpenTSDBEmitter{}, err
+	}
+
+	emitter := &OpenTSDBEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:    putURL,
+		prefix: namespace(strings.ToLower(config.Prefix)),
+		proxy:  proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.put)
+
+	return emitter, nil
+}
+
+func (emitter *OpenTSDBEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *OpenTSDBEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	name := emitter.prefix + normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-opentsdb", nil, lager.Data{
+			"metric-name": name,
+		})
+		return nil
+	}
+
+	tags := map[string]string{}
+	for k, v := range event.Attributes {
+		tags[k] = v
+	}
+
+	// opentsdb requires at least one tag per datapoint
+	if len(tags) == 0 {
+		tags["source"] = "concourse"
+	}
+
+	tags["host"] = event.Host
+	tags["state"] = string(event.State)
+
+	if event.Unit != "" {
+		tags["unit"] = event.Unit
+	}
+
+	datapoint := openTSDBDatapoint{
+		Metric:    name,
+		Timestamp: event.Timestamp().Unix(),
+		Value:     value,
+		Tags:      map[string]string{},
+	}
+
+	for k, v := range tags {
+		v = openTSDBInvalidTagChars.ReplaceAllString(v, "_")
+
+		// empty tag values are rejected as well
+		if v == "" {
+			continue
+		}
+
+		datapoint.Tags[openTSDBInvalidTagChars.ReplaceAllString(k, "_")] = v
+	}
+
+	return emitter.batcher.Add(logger, datapoint)
+}
+
+// Close flushes any batched data points.
+func (emitter *OpenTSDBEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+func (emitter *OpenTSDBEmitter) put(logger lager.Logger, datapoints []interface{}) error {
+	payload, err := json.Marshal(datapoints)
+	if err != nil {
+		logger.Error("failed-to-serialize-payload", err)
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = post(emitter.client, emitter.url, http.Header{
+		"Content-Type": {"application/json"},
+	}, payload, 3, retryServerErrors)
+	if err != nil {
+		logger.Error("failed-to-send-datapoints",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,412 @@
+package emitter
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cenkalti/backoff"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// otlpMaxDataPoints bounds the size of an export between two intervals
+const otlpMaxDataPoints = 1000
+
+// otlpExportMethod is the RPC of the OTLP metrics service which receives
+// exports over gRPC
+const otlpExportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+const (
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http/json"
+)
+
+// OTLPEmitter exports metrics as OTLP gauges, either over gRPC or over
+// OTLP/HTTP using the JSON encoding. Data points are collected and exported
+// on an interval, like the OpenTelemetry SDK's periodic reader does.
+//
+// The OpenTelemetry exporters require a far newer gRPC than we vendor, so the
+// export requests are declared here, with the field numbers of the OTLP
+// protobuf messages, and sent with the gRPC client we have.
+type OTLPEmitter struct {
+	conn *grpc.ClientConn
+
+	client *http.Client
+	url    string
+	header http.Header
+	proxy  *proxyCheck
+
+	headers *requestHeaders
+	batcher *batcher
+}
+
+type OTLPConfig struct {
+	Endpoint string `long:"otlp-endpoint" description:"Host and port of the OTLP receiver to export metrics to, e.g. otel-collector:4317 over gRPC or otel-collector:4318 over HTTP."`
+	Protocol string `long:"otlp-protocol" default:"grpc" choice:"grpc" choice:"http/json" description:"Protocol to export metrics over."`
+	Insecure bool   `long:"otlp-insecure" description:"Export without TLS."`
+
+	Headers HeaderConfig `group:"OpenTelemetry Headers" namespace:"otlp"`
+	Proxy   ProxyConfig  `group:"OpenTelemetry Proxy" namespace:"otlp"`
+
+	ExportInterval time.Duration `long:"otlp-export-interval" default:"10s" description:"Interval on which to export collected data points."`
+}
+
+// The messages below are those of the OTLP metrics protobuf, tagged for both
+// its protobuf and its JSON encoding.
+
+type otlpExportRequest struct {
+	ResourceMetrics []*otlpResourceMetrics `protobuf:"bytes,1,rep,name=resource_metrics" json:"resourceMetrics"`
+}
+
+func (m *otlpExportRequest) Reset()         { *m = otlpExportRequest{} }
+func (m *otlpExportRequest) String() string { return proto.CompactTextString(m) }
+func (*otlpExportRequest) ProtoMessage()    {}
+
+// otlpExportResponse ignores any partial success reported by the receiver
+type otlpExportResponse struct{}
+
+func (m *otlpExportResponse) Reset()         { *m = otlpExportResponse{} }
+func (m *otlpExportResponse) String() string { return proto.CompactTextString(m) }
+func (*otlpExportResponse) ProtoMessage()    {}
+
+type otlpResourceMetrics struct {
+	Resource     *otlpResource       `protobuf:"bytes,1,opt,name=resource" json:"resource"`
+	ScopeMetrics []*otlpScopeMetrics `protobuf:"bytes,2,rep,name=scope_metrics" json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpAttribute `protobuf:"bytes,1,rep,name=attributes" json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   *otlpScope    `protobuf:"bytes,1,opt,name=scope" json:"scope"`
+	Metrics []*otlpMetric `protobuf:"bytes,2,rep,name=metrics" json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+}
+
+type otlpMetric struct {
+	Name  string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+	Gauge *otlpGauge `protobuf:"bytes,5,opt,name=gauge" json:"gauge"`
+}
+
+type otlpGauge struct {
+	DataPoints []*otlpDataPoint `protobuf:"bytes,1,rep,name=data_points" json:"dataPoints"`
+}
+
+type otlpDataPoint struct {
+	Attributes   []*otlpAttribute `protobuf:"bytes,7,rep,name=attributes" json:"attributes"`
+	TimeUnixNano uint64           `protobuf:"fixed64,3,opt,name=time_unix_nano,proto3" json:"timeUnixNano,string"`
+
+	// only one of these is set, as they are a oneof in OTLP. 64 bit integers
+	// are encoded as strings in OTLP's JSON encoding.
+	AsDouble *float64 `protobuf:"fixed64,4,opt,name=as_double" json:"asDouble,omitempty"`
+	AsInt    *int64   `protobuf:"fixed64,6,opt,name=as_int" json:"asInt,omitempty,string"`
+}
+
+type otlpAttribute struct {
+	Key   string        `protobuf:"bytes,1,opt,name=key,proto3" json:"key"`
+	Value *otlpAnyValue `protobuf:"bytes,2,opt,name=value" json:"value"`
+}
+
+type otlpAnyValue struct {
+	// not tagged as proto3, so that empty values are sent too
+	StringValue string `protobuf:"bytes,1,opt,name=string_value" json:"stringValue"`
+}
+
+// otlpPoint is what gets batched, until it's grouped by metric on export
+type otlpPoint struct {
+	name  string
+	point *otlpDataPoint
+}
+
+func init() {
+	metric.RegisterEmitter(&OTLPConfig{})
+}
+
+func (config *OTLPConfig) Description() string { return "OpenTelemetry" }
+func (config *OTLPConfig) FlagPrefix() string  { return "otlp" }
+func (config *OTLPConfig) IsConfigured() bool  { return config.Endpoint != "" }
+
+func (config *OTLPConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("otlp-export-interval", config.ExportInterval)
+	if err != nil {
+		return &OTLPEmitter{}, err
+	}
+
+	headers, err := config.Headers.requestHeaders("otlp")
+	if err != nil {
+		return &OTLPEmitter{}, err
+	}
+
+	emitter := &OTLPEmitter{
+		headers: headers,
+	}
+
+	if config.Protocol == otlpProtocolHTTP {
+		err = config.httpExporter(emitter)
+	} else {
+		err = config.grpcExporter(emitter)
+	}
+	if err != nil {
+		return &OTLPEmitter{}, err
+	}
+
+	emitter.batcher = newBatcher(otlpMaxDataPoints, config.ExportInterval, emitter.export)
+
+	return emitter, nil
+}
+
+func (config *OTLPConfig) grpcExporter(emitter *OTLPEmitter) error {
+	// gRPC dials through the proxy from the HTTPS_PROXY environment variable
+	// by itself, but has no way of being given another one
+	if config.Proxy.URL != "" {
+		return fmt.Errorf("--otlp-proxy-url is only supported with --otlp-protocol=%s", otlpProtocolHTTP)
+	}
+
+	transportCredentials := grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))
+	if config.Insecure {
+		transportCredentials = grpc.WithInsecure()
+	}
+
+	// dialing does not block, the connection is made on the first export
+	conn, err := grpc.Dial(config.Endpoint, transportCredentials)
+	if err != nil {
+		return fmt.Errorf("failed to dial otlp endpoint: %s", err)
+	}
+
+	emitter.conn = conn
+
+	return nil
+}
+
+func (config *OTLPConfig) httpExporter(emitter *OTLPEmitter) error {
+	scheme := "https"
+	if config.Insecure {
+		scheme = "http"
+	}
+
+	exportURL := fmt.Sprintf("%s://%s/v1/metrics", scheme, strings.TrimSuffix(config.Endpoint, "/"))
+
+	transport, proxy, err := config.Proxy.transport("otlp", exportURL, nil)
+	if err != nil {
+		return err
+	}
+
+	emitter.client = &http.Client{
+		Transport: transport,
+		Timeout:   time.Minute,
+	}
+	emitter.url = exportURL
+	emitter.header = http.Header{
+		"Content-Type": {"application/json"},
+	}
+	emitter.proxy = proxy
+
+	return nil
+}
+
+func (emitter *OTLPEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *OTLPEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	point := &otlpDataPoint{
+		Attributes:   otlpAttributes(event),
+		TimeUnixNano: uint64(event.Timestamp().UnixNano()),
+	}
+
+	// uint and uint64 may not fit in an int64, so they are sent as doubles
+	switch v := event.Value.(type) {
+	case int:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case int8:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case int16:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case int32:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case int64:
+		point.AsInt = &v
+	case uint8:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case uint16:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	case uint32:
+		asInt := int64(v)
+		point.AsInt = &asInt
+	default:
+		value, err := getFloatHelper(event.Value)
+		if err != nil {
+			logger.Error("failed-to-convert-metric-for-otlp", nil, lager.Data{
+				"metric-name": event.Name,
+			})
+			return nil
+		}
+
+		point.AsDouble = &value
+	}
+
+	return emitter.batcher.Add(logger, otlpPoint{
+		name:  "concourse." + normalizeName(event.Name),
+		point: point,
+	})
+}
+
+// Close exports any collected data points.
+func (emitter *OTLPEmitter) Close() error {
+	emitter.batcher.Close()
+
+	if emitter.conn != nil {
+		return emitter.conn.Close()
+	}
+
+	return nil
+}
+
+func otlpAttributes(event metric.Event) []*otlpAttribute {
+	values := map[string]string{
+		"host":  event.Host,
+		"state": string(event.State),
+	}
+
+	for k, v := range event.Attributes {
+		values[k] = v
+	}
+
+	keys := []string{}
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	attributes := make([]*otlpAttribute, len(keys))
+	for i, k := range keys {
+		attributes[i] = &otlpAttribute{
+			Key:   k,
+			Value: &otlpAnyValue{StringValue: values[k]},
+		}
+	}
+
+	return attributes
+}
+
+func (emitter *OTLPEmitter) export(logger lager.Logger, items []interface{}) error {
+	metrics := []*otlpMetric{}
+	indices := map[string]int{}
+
+	for _, item := range items {
+		point := item.(otlpPoint)
+
+		i, found := indices[point.name]
+		if !found {
+			i = len(metrics)
+			indices[point.name] = i
+			metrics = append(metrics, &otlpMetric{Name: point.name, Gauge: &otlpGauge{}})
+		}
+
+		metrics[i].Gauge.DataPoints = append(metrics[i].Gauge.DataPoints, point.point)
+	}
+
+	request := &otlpExportRequest{
+		ResourceMetrics: []*otlpResourceMetrics{
+			{
+				Resource: &otlpResource{
+					Attributes: []*otlpAttribute{
+						{Key: "service.name", Value: &otlpAnyValue{StringValue: "concourse"}},
+					},
+				},
+				ScopeMetrics: []*otlpScopeMetrics{
+					{
+						Scope:   &otlpScope{Name: "github.com/concourse/concourse/atc/metric"},
+						Metrics: metrics,
+					},
+				},
+			},
+		},
+	}
+
+	header, err := emitter.headers.with(logger, emitter.header)
+	if err != nil {
+		logger.Error("failed-to-send-metrics",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	if emitter.conn != nil {
+		err = emitter.exportGRPC(header, request)
+	} else {
+		err = emitter.exportHTTP(logger, header, request)
+	}
+	if err != nil {
+		logger.Error("failed-to-send-metrics",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// exportGRPC sends the request with the headers as metadata, retrying up to
+// 3 times on the errors which OTLP deems retryable.
+func (emitter *OTLPEmitter) exportGRPC(header http.Header, request *otlpExportRequest) error {
+	md := metadata.MD{}
+	for k, vs := range header {
+		md[strings.ToLower(k)] = vs
+	}
+
+	return backoff.Retry(func() error {
+		ctx, cancel := context.WithTimeout(metadata.NewOutgoingContext(context.Background(), md), time.Minute)
+		defer cancel()
+
+		err := emitter.conn.Invoke(ctx, otlpExportMethod, request, &otlpExportResponse{})
+		if err == nil {
+			return nil
+		}
+
+		switch status.Code(err) {
+		case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+			return metric.TransientError{Err: err}
+		}
+
+		return backoff.Permanent(err)
+	}, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3))
+}
+
+func (emitter *OTLPEmitter) exportHTTP(logger lager.Logger, header http.Header, request *otlpExportRequest) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = post(emitter.client, emitter.url, header, payload, 3, retryServerErrors)
+	return err
+}
@@ -0,0 +1,309 @@
+package emitter_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// rawCodec leaves messages as they are on the wire, so that the export
+// requests can be checked against the field numbers of the OTLP protobuf.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) { return *v.(*[]byte), nil }
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte{}, data...)
+	return nil
+}
+func (rawCodec) String() string { return "raw" }
+
+type otlpExport struct {
+	method   string
+	metadata metadata.MD
+	body     []byte
+}
+
+// protoFields holds the values of each field of a protobuf message, with
+// fixed64 values as uint64s and length-delimited ones as []byte.
+type protoFields map[uint64][]interface{}
+
+func decodeProto(b []byte) protoFields {
+	fields := protoFields{}
+
+	buf := proto.NewBuffer(b)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil {
+			return fields
+		}
+
+		var value interface{}
+		switch key & 7 {
+		case 0:
+			value, err = buf.DecodeVarint()
+		case 1:
+			value, err = buf.DecodeFixed64()
+		case 2:
+			value, err = buf.DecodeRawBytes(true)
+		case 5:
+			value, err = buf.DecodeFixed32()
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		fields[key>>3] = append(fields[key>>3], value)
+	}
+}
+
+func (fields protoFields) message(field uint64) protoFields {
+	Expect(fields[field]).To(HaveLen(1))
+	return decodeProto(fields[field][0].([]byte))
+}
+
+func (fields protoFields) messages(field uint64) []protoFields {
+	messages := []protoFields{}
+	for _, value := range fields[field] {
+		messages = append(messages, decodeProto(value.([]byte)))
+	}
+
+	return messages
+}
+
+func (fields protoFields) text(field uint64) string {
+	Expect(fields[field]).To(HaveLen(1))
+	return string(fields[field][0].([]byte))
+}
+
+func (fields protoFields) attributes(field uint64) map[string]string {
+	attributes := map[string]string{}
+	for _, attribute := range fields.messages(field) {
+		attributes[attribute.text(1)] = attribute.message(2).text(1)
+	}
+
+	return attributes
+}
+
+var _ = Describe("OTLPEmitter", func() {
+	var (
+		logger *lagertest.TestLogger
+		config *emitter.OTLPConfig
+		e      metric.Emitter
+
+		event metric.Event
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("otlp")
+
+		config = &emitter.OTLPConfig{
+			Insecure:       true,
+			ExportInterval: time.Hour,
+		}
+
+		event = metric.Event{
+			Name:       "worker containers",
+			Value:      5,
+			Host:       "some-host",
+			State:      metric.EventStateOK,
+			Attributes: map[string]string{"worker": "some-worker"},
+			Time:       time.Unix(1, 2),
+		}
+	})
+
+	Context("over gRPC", func() {
+		var (
+			server  *grpc.Server
+			exports chan otlpExport
+		)
+
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			exports = make(chan otlpExport, 10)
+
+			server = grpc.NewServer(
+				grpc.CustomCodec(rawCodec{}),
+				grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+					method, _ := grpc.MethodFromServerStream(stream)
+					md, _ := metadata.FromIncomingContext(stream.Context())
+
+					var body []byte
+					err := stream.RecvMsg(&body)
+					if err != nil {
+						return err
+					}
+
+					exports <- otlpExport{method: method, metadata: md, body: body}
+
+					return stream.SendMsg(&[]byte{})
+				}),
+			)
+
+			go server.Serve(listener)
+
+			config.Endpoint = listener.Addr().String()
+			config.Protocol = "grpc"
+			config.Headers.Headers = []string{"X-Api-Key: some-key"}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			e, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Stop()
+		})
+
+		It("exports the events to the metrics service on close", func() {
+			e.Emit(logger, event)
+
+			event.Name = "build duration"
+			event.Value = 1.5
+			e.Emit(logger, event)
+
+			Expect(e.Close()).To(Succeed())
+
+			var export otlpExport
+			Eventually(exports).Should(Receive(&export))
+
+			Expect(export.method).To(Equal("/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"))
+			Expect(export.metadata.Get("x-api-key")).To(Equal([]string{"some-key"}))
+
+			resourceMetrics := decodeProto(export.body).message(1)
+			Expect(resourceMetrics.message(1).attributes(1)).To(Equal(map[string]string{
+				"service.name": "concourse",
+			}))
+
+			metrics := resourceMetrics.message(2).messages(2)
+			Expect(metrics).To(HaveLen(2))
+
+			Expect(metrics[0].text(1)).To(Equal("concourse.worker_containers"))
+			containers := metrics[0].message(5).message(1)
+			Expect(containers[3]).To(Equal([]interface{}{uint64(time.Unix(1, 2).UnixNano())}))
+			Expect(containers[6]).To(Equal([]interface{}{uint64(5)}))
+			Expect(containers).NotTo(HaveKey(uint64(4)))
+			Expect(containers.attributes(7)).To(Equal(map[string]string{
+				"host":   "some-host",
+				"state":  "ok",
+				"worker": "some-worker",
+			}))
+
+			Expect(metrics[1].text(1)).To(Equal("concourse.build_duration"))
+			duration := metrics[1].message(5).message(1)
+			Expect(duration[4]).To(Equal([]interface{}{math.Float64bits(1.5)}))
+			Expect(duration).NotTo(HaveKey(uint64(6)))
+		})
+
+		It("sends int values of zero", func() {
+			event.Value = 0
+			e.Emit(logger, event)
+
+			Expect(e.Close()).To(Succeed())
+
+			var export otlpExport
+			Eventually(exports).Should(Receive(&export))
+
+			point := decodeProto(export.body).message(1).message(2).message(2).message(5).message(1)
+			Expect(point[6]).To(Equal([]interface{}{uint64(0)}))
+		})
+
+		Context("when a proxy URL is configured", func() {
+			It("fails, as gRPC can only be given one through the environment", func() {
+				config.Proxy.URL = "http://some-proxy:3128"
+
+				_, err := config.NewEmitter()
+				Expect(err).To(MatchError(ContainSubstring("--otlp-proxy-url")))
+			})
+		})
+	})
+
+	Context("over HTTP", func() {
+		var (
+			server *httptest.Server
+			bodies chan []byte
+		)
+
+		BeforeEach(func() {
+			bodies = make(chan []byte, 10)
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.URL.Path).To(Equal("/v1/metrics"))
+				Expect(r.Header.Get("Content-Type")).To(Equal("application/json"))
+
+				body, err := ioutil.ReadAll(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				bodies <- body
+			}))
+
+			config.Endpoint = strings.TrimPrefix(server.URL, "http://")
+			config.Protocol = "http/json"
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			e, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("exports the events as JSON, with 64 bit integers as strings", func() {
+			e.Emit(logger, event)
+
+			event.Name = "build duration"
+			event.Value = 1.5
+			e.Emit(logger, event)
+
+			Expect(e.Close()).To(Succeed())
+
+			var body []byte
+			Eventually(bodies).Should(Receive(&body))
+
+			var request struct {
+				ResourceMetrics []struct {
+					ScopeMetrics []struct {
+						Metrics []struct {
+							Name  string `json:"name"`
+							Gauge struct {
+								DataPoints []map[string]interface{} `json:"dataPoints"`
+							} `json:"gauge"`
+						} `json:"metrics"`
+					} `json:"scopeMetrics"`
+				} `json:"resourceMetrics"`
+			}
+			Expect(json.Unmarshal(body, &request)).To(Succeed())
+
+			metrics := request.ResourceMetrics[0].ScopeMetrics[0].Metrics
+			Expect(metrics).To(HaveLen(2))
+
+			Expect(metrics[0].Name).To(Equal("concourse.worker_containers"))
+			Expect(metrics[0].Gauge.DataPoints[0]).To(HaveKeyWithValue("asInt", "5"))
+			Expect(metrics[0].Gauge.DataPoints[0]).To(HaveKeyWithValue("timeUnixNano", "1000000002"))
+			Expect(metrics[0].Gauge.DataPoints[0]).NotTo(HaveKey("asDouble"))
+
+			Expect(metrics[1].Name).To(Equal("concourse.build_duration"))
+			Expect(metrics[1].Gauge.DataPoints[0]).To(HaveKeyWithValue("asDouble", 1.5))
+			Expect(metrics[1].Gauge.DataPoints[0]).NotTo(HaveKey("asInt"))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sort"
 	"sync"
 	"time"
 
@@ -16,6 +17,9 @@ import (
 )
 
 type PrometheusEmitter struct {
+	bind    string
+	handler http.Handler
+
 	buildDurationsVec *prometheus.HistogramVec
 	buildsAborted     prometheus.Counter
 	buildsErrored     prometheus.Counter
@@ -47,6 +51,38 @@ type PrometheusEmitter struct {
 
 	workerLastSeen map[string]time.Time
 	mu             sync.Mutex
+
+	gauges *prometheusGauges
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// prometheusGauges registers gauges on demand for events that have no
+// dedicated Prometheus metric, reusing the collector for subsequent events. The
+// label names of a gauge are fixed the first time the event is seen, as
+// Prometheus does not allow them to change afterwards.
+type prometheusGauges struct {
+	registerer prometheus.Registerer
+
+	// withoutHost leaves out the host label, for when the host is carried
+	// some other way
+	withoutHost bool
+
+	gauges map[string]*prometheusGauge
+	mu     sync.Mutex
+}
+
+type prometheusGauge struct {
+	vec    *prometheus.GaugeVec
+	labels []string
+}
+
+func newPrometheusGauges(registerer prometheus.Registerer) *prometheusGauges {
+	return &prometheusGauges{
+		registerer: registerer,
+		gauges:     map[string]*prometheusGauge{},
+	}
 }
 
 type PrometheusConfig struct {
@@ -59,6 +95,7 @@ func init() {
 }
 
 func (config *PrometheusConfig) Description() string { return "Prometheus" }
+func (config *PrometheusConfig) FlagPrefix() string  { return "prometheus" }
 func (config *PrometheusConfig) IsConfigured() bool {
 	return config.BindPort != "" && config.BindIP != ""
 }
@@ -67,6 +104,12 @@ func (config *PrometheusConfig) bind() string {
 }
 
 func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
+	// each emitter registers its metrics with a registry of its own, as the
+	// emitter replacing it on reload is built before it is closed
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector())
+	registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
 	// error log metrics
 	errorLogs := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -76,7 +119,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 			Help:      "Number of error logged",
 		}, []string{"message"},
 	)
-	prometheus.MustRegister(errorLogs)
+	registry.MustRegister(errorLogs)
 
 	// lock metrics
 	locksHeld := prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -85,7 +128,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "held",
 		Help:      "Database locks held",
 	}, []string{"type"})
-	prometheus.MustRegister(locksHeld)
+	registry.MustRegister(locksHeld)
 
 	// build metrics
 	buildsStarted := prometheus.NewCounter(prometheus.CounterOpts{
@@ -94,7 +137,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "started_total",
 		Help:      "Total number of Concourse builds started.",
 	})
-	prometheus.MustRegister(buildsStarted)
+	registry.MustRegister(buildsStarted)
 
 	buildsFinished := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -102,7 +145,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "finished_total",
 		Help:      "Total number of Concourse builds finished.",
 	})
-	prometheus.MustRegister(buildsFinished)
+	registry.MustRegister(buildsFinished)
 
 	buildsSucceeded := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -110,7 +153,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "succeeded_total",
 		Help:      "Total number of Concourse builds succeeded.",
 	})
-	prometheus.MustRegister(buildsSucceeded)
+	registry.MustRegister(buildsSucceeded)
 
 	buildsErrored := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -118,7 +161,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "errored_total",
 		Help:      "Total number of Concourse builds errored.",
 	})
-	prometheus.MustRegister(buildsErrored)
+	registry.MustRegister(buildsErrored)
 
 	buildsFailed := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -126,7 +169,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "failed_total",
 		Help:      "Total number of Concourse builds failed.",
 	})
-	prometheus.MustRegister(buildsFailed)
+	registry.MustRegister(buildsFailed)
 
 	buildsAborted := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -134,7 +177,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "aborted_total",
 		Help:      "Total number of Concourse builds aborted.",
 	})
-	prometheus.MustRegister(buildsAborted)
+	registry.MustRegister(buildsAborted)
 
 	buildsFinishedVec := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -145,7 +188,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"team", "pipeline", "job", "status"},
 	)
-	prometheus.MustRegister(buildsFinishedVec)
+	registry.MustRegister(buildsFinishedVec)
 
 	buildDurationsVec := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -157,7 +200,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"team", "pipeline"},
 	)
-	prometheus.MustRegister(buildDurationsVec)
+	registry.MustRegister(buildDurationsVec)
 
 	// worker metrics
 	workerContainers := prometheus.NewGaugeVec(
@@ -169,7 +212,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"worker", "platform"},
 	)
-	prometheus.MustRegister(workerContainers)
+	registry.MustRegister(workerContainers)
 
 	workerVolumes := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -180,7 +223,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"worker", "platform"},
 	)
-	prometheus.MustRegister(workerVolumes)
+	registry.MustRegister(workerVolumes)
 
 	workersRegistered := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -191,7 +234,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"state"},
 	)
-	prometheus.MustRegister(workersRegistered)
+	registry.MustRegister(workersRegistered)
 
 	// http metrics
 	httpRequestsDuration := prometheus.NewHistogramVec(
@@ -203,7 +246,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"method", "route", "status"},
 	)
-	prometheus.MustRegister(httpRequestsDuration)
+	registry.MustRegister(httpRequestsDuration)
 
 	// scheduling metrics
 	schedulingFullDuration := prometheus.NewCounterVec(
@@ -215,7 +258,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"pipeline"},
 	)
-	prometheus.MustRegister(schedulingFullDuration)
+	registry.MustRegister(schedulingFullDuration)
 
 	schedulingLoadingDuration := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -226,7 +269,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"pipeline"},
 	)
-	prometheus.MustRegister(schedulingLoadingDuration)
+	registry.MustRegister(schedulingLoadingDuration)
 
 	pipelineScheduled := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -237,7 +280,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"pipeline"},
 	)
-	prometheus.MustRegister(pipelineScheduled)
+	registry.MustRegister(pipelineScheduled)
 
 	dbQueriesTotal := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "concourse",
@@ -245,7 +288,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		Name:      "queries_total",
 		Help:      "Total number of database Concourse database queries",
 	})
-	prometheus.MustRegister(dbQueriesTotal)
+	registry.MustRegister(dbQueriesTotal)
 
 	dbConnections := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -256,7 +299,7 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"dbname"},
 	)
-	prometheus.MustRegister(dbConnections)
+	registry.MustRegister(dbConnections)
 
 	resourceChecksVec := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -267,16 +310,12 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		},
 		[]string{"team", "pipeline"},
 	)
-	prometheus.MustRegister(resourceChecksVec)
-
-	listener, err := net.Listen("tcp", config.bind())
-	if err != nil {
-		return nil, err
-	}
-
-	go http.Serve(listener, promhttp.Handler())
+	registry.MustRegister(resourceChecksVec)
 
 	emitter := &PrometheusEmitter{
+		bind:    config.bind(),
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+
 		buildDurationsVec: buildDurationsVec,
 		buildsAborted:     buildsAborted,
 		buildsErrored:     buildsErrored,
@@ -306,12 +345,25 @@ func (config *PrometheusConfig) NewEmitter() (metric.Emitter, error) {
 		workersRegistered: workersRegistered,
 		workerLastSeen:    map[string]time.Time{},
 		workerVolumes:     workerVolumes,
+
+		gauges: newPrometheusGauges(registry),
+
+		done: make(chan struct{}),
 	}
+
+	err := servePrometheus(emitter)
+	if err != nil {
+		return nil, err
+	}
+
 	go emitter.periodicMetricGC()
 
 	return emitter, nil
 }
 
+// EmitsHistograms returns true, as durations are observed by histograms.
+func (emitter *PrometheusEmitter) EmitsHistograms() bool { return true }
+
 // Emit processes incoming metrics.
 // In order to provide idiomatic Prometheus metrics, we'll have to convert the various
 // Event types (differentiated by the less-than-ideal string Name field) into different
@@ -328,6 +380,8 @@ func (emitter *PrometheusEmitter) Emit(logger lager.Logger, event metric.Event)
 		emitter.lock(logger, event)
 	case "build started":
 		emitter.buildsStarted.Inc()
+	case "builds started":
+		// counted by "build started" already
 	case "build finished":
 		emitter.buildFinishedMetrics(logger, event)
 	case "worker containers":
@@ -351,10 +405,192 @@ func (emitter *PrometheusEmitter) Emit(logger lager.Logger, event metric.Event)
 	case "resource checked":
 		emitter.resourceMetric(logger, event)
 	default:
-		// unless we have a specific metric, we do nothing
+		// unless we have a specific metric, fall back to a generic gauge
+		emitter.gauges.Set(logger, event)
 	}
 }
 
+// Close stops serving metrics. The listener is handed over to the newest
+// emitter bound to the same address, if any.
+func (emitter *PrometheusEmitter) Close() error {
+	var err error
+	emitter.closeOnce.Do(func() {
+		close(emitter.done)
+		err = stopServingPrometheus(emitter)
+	})
+
+	return err
+}
+
+var (
+	prometheusServers     = map[string]*prometheusServer{}
+	prometheusServersLock sync.Mutex
+)
+
+// prometheusServer serves the metrics of the newest emitter bound to its
+// address, so that emitters built on reload can take over the listener of the
+// ones they replace without the address being in use.
+type prometheusServer struct {
+	listener net.Listener
+	emitters []*PrometheusEmitter
+}
+
+func servePrometheus(emitter *PrometheusEmitter) error {
+	prometheusServersLock.Lock()
+	defer prometheusServersLock.Unlock()
+
+	server, found := prometheusServers[emitter.bind]
+	if !found {
+		listener, err := net.Listen("tcp", emitter.bind)
+		if err != nil {
+			return err
+		}
+
+		server = &prometheusServer{listener: listener}
+		prometheusServers[emitter.bind] = server
+
+		go http.Serve(listener, server)
+	}
+
+	server.emitters = append(server.emitters, emitter)
+
+	return nil
+}
+
+func stopServingPrometheus(emitter *PrometheusEmitter) error {
+	prometheusServersLock.Lock()
+	defer prometheusServersLock.Unlock()
+
+	server, found := prometheusServers[emitter.bind]
+	if !found {
+		return nil
+	}
+
+	for i, serving := range server.emitters {
+		if serving == emitter {
+			server.emitters = append(server.emitters[:i], server.emitters[i+1:]...)
+			break
+		}
+	}
+
+	if len(server.emitters) > 0 {
+		return nil
+	}
+
+	delete(prometheusServers, emitter.bind)
+
+	return server.listener.Close()
+}
+
+func (server *prometheusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prometheusServersLock.Lock()
+	var handler http.Handler
+	if len(server.emitters) > 0 {
+		handler = server.emitters[len(server.emitters)-1].handler
+	}
+	prometheusServersLock.Unlock()
+
+	if handler == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+func (gauges *prometheusGauges) Set(logger lager.Logger, event metric.Event) {
+	name := "concourse_" + normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-prometheus", nil, lager.Data{
+			"metric-name": name,
+		})
+		return
+	}
+
+	labelValues := map[string]string{
+		"host":  event.Host,
+		"state": string(event.State),
+	}
+
+	if gauges.withoutHost {
+		delete(labelValues, "host")
+	}
+
+	for k, v := range event.Attributes {
+		labelValues[specialChars.ReplaceAllString(k, "_")] = v
+	}
+
+	gauges.mu.Lock()
+	defer gauges.mu.Unlock()
+
+	gauge, found := gauges.gauges[name]
+	if !found {
+		labels := []string{}
+		for label := range labelValues {
+			labels = append(labels, label)
+		}
+
+		sort.Strings(labels)
+
+		help := metric.Lookup(event.Name).Help
+		if help == "" {
+			help = fmt.Sprintf("Concourse metric '%s'", event.Name)
+		}
+
+		vec := prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: name,
+				Help: help,
+			},
+			labels,
+		)
+
+		err := gauges.registerer.Register(vec)
+		if err != nil {
+			logger.Error("failed-to-register-gauge", err, lager.Data{
+				"metric-name": name,
+			})
+			return
+		}
+
+		gauge = &prometheusGauge{
+			vec:    vec,
+			labels: labels,
+		}
+
+		gauges.gauges[name] = gauge
+	}
+
+	// a mismatching label set would either panic or silently create a new
+	// time series per attribute combination, so only accept the set of labels
+	// the gauge was registered with
+	if len(labelValues) != len(gauge.labels) {
+		logger.Error("mismatched-labels-for-gauge", nil, lager.Data{
+			"metric-name": name,
+			"expected":    gauge.labels,
+		})
+		return
+	}
+
+	values := make([]string, len(gauge.labels))
+	for i, label := range gauge.labels {
+		v, exists := labelValues[label]
+		if !exists {
+			logger.Error("mismatched-labels-for-gauge", nil, lager.Data{
+				"metric-name": name,
+				"expected":    gauge.labels,
+			})
+			return
+		}
+
+		values[i] = v
+	}
+
+	gauge.vec.WithLabelValues(values...).Set(value)
+}
+
 func (emitter *PrometheusEmitter) lock(logger lager.Logger, event metric.Event) {
 	lockType, exists := event.Attributes["type"]
 	if !exists {
@@ -427,9 +663,9 @@ func (emitter *PrometheusEmitter) buildFinishedMetrics(logger lager.Logger, even
 	}
 
 	// concourse_builds_duration_seconds
-	duration, ok := event.Value.(float64)
-	if !ok {
-		logger.Error("build-finished-event-value-type-mismatch", fmt.Errorf("expected event.Value to be a float64"))
+	duration, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("build-finished-event-value-type-mismatch", fmt.Errorf("expected event.Value to be a duration"))
 		return
 	}
 	// seconds are the standard prometheus base unit for time
@@ -514,9 +750,9 @@ func (emitter *PrometheusEmitter) httpResponseTimeMetrics(logger lager.Logger, e
 		return
 	}
 
-	responseTime, ok := event.Value.(float64)
-	if !ok {
-		logger.Error("http-response-time-event-value-type-mismatch", fmt.Errorf("expected event.Value to be a float64"))
+	responseTime, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("http-response-time-event-value-type-mismatch", fmt.Errorf("expected event.Value to be a duration"))
 		return
 	}
 
@@ -530,9 +766,9 @@ func (emitter *PrometheusEmitter) schedulingMetrics(logger lager.Logger, event m
 		return
 	}
 
-	duration, ok := event.Value.(float64)
-	if !ok {
-		logger.Error("scheduling-full-duration-value-type-mismatch", fmt.Errorf("expected event.Value to be a float64"))
+	duration, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("scheduling-full-duration-value-type-mismatch", fmt.Errorf("expected event.Value to be a duration"))
 		return
 	}
 
@@ -596,6 +832,9 @@ func (emitter *PrometheusEmitter) updateLastSeen(event metric.Event) {
 
 //periodically remove stale metrics for workers
 func (emitter *PrometheusEmitter) periodicMetricGC() {
+	ticker := time.NewTicker(60 * time.Second)
+	defer ticker.Stop()
+
 	for {
 		emitter.mu.Lock()
 		now := time.Now()
@@ -612,6 +851,11 @@ func (emitter *PrometheusEmitter) periodicMetricGC() {
 			}
 		}
 		emitter.mu.Unlock()
-		time.Sleep(60 * time.Second)
+
+		select {
+		case <-ticker.C:
+		case <-emitter.done:
+			return
+		}
 	}
 }
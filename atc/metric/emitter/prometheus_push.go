@@ -0,0 +1,134 @@
+package emitter
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+type PrometheusPushEmitter struct {
+	url      string
+	job      string
+	interval time.Duration
+
+	registry *prometheus.Registry
+	gauges   *prometheusGauges
+
+	pusher *push.Pusher
+	logger lager.Logger
+	mu     sync.Mutex
+
+	stop    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+type PrometheusPushConfig struct {
+	URL      string        `long:"prometheus-push-url" description:"Prometheus Pushgateway URL to push metrics to."`
+	Job      string        `long:"prometheus-push-job" default:"concourse" description:"Job name to group metrics pushed to the Pushgateway."`
+	Interval time.Duration `long:"prometheus-push-interval" default:"15s" description:"Interval on which to push metrics to the Pushgateway."`
+}
+
+func init() {
+	metric.RegisterEmitter(&PrometheusPushConfig{})
+}
+
+func (config *PrometheusPushConfig) Description() string { return "Prometheus Pushgateway" }
+func (config *PrometheusPushConfig) FlagPrefix() string  { return "prometheus-push" }
+func (config *PrometheusPushConfig) IsConfigured() bool  { return config.URL != "" }
+
+func (config *PrometheusPushConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("prometheus-push-interval", config.Interval)
+	if err != nil {
+		return &PrometheusPushEmitter{}, err
+	}
+
+	registry := prometheus.NewRegistry()
+
+	// the host is pushed as a grouping label instead, which the pusher refuses
+	// to find on the gathered metrics themselves
+	gauges := newPrometheusGauges(registry)
+	gauges.withoutHost = true
+
+	emitter := &PrometheusPushEmitter{
+		url:      config.URL,
+		job:      config.Job,
+		interval: config.Interval,
+
+		registry: registry,
+		gauges:   gauges,
+
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go emitter.periodicallyPush()
+
+	return emitter, nil
+}
+
+func (emitter *PrometheusPushEmitter) Emit(logger lager.Logger, event metric.Event) {
+	emitter.mu.Lock()
+	if emitter.pusher == nil {
+		// the host is only known once events start flowing; group by it so that
+		// multiple ATCs pushing to the same gateway don't clobber each other
+		emitter.pusher = push.New(emitter.url, emitter.job).
+			Gatherer(emitter.registry).
+			Grouping("host", event.Host)
+	}
+
+	emitter.logger = logger
+	emitter.mu.Unlock()
+
+	emitter.gauges.Set(logger, event)
+}
+
+// Close stops pushing periodically and pushes the latest values one last time.
+func (emitter *PrometheusPushEmitter) Close() error {
+	emitter.once.Do(func() { close(emitter.stop) })
+	<-emitter.stopped
+
+	emitter.push()
+	return nil
+}
+
+func (emitter *PrometheusPushEmitter) periodicallyPush() {
+	defer close(emitter.stopped)
+
+	ticker := time.NewTicker(emitter.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emitter.push()
+		case <-emitter.stop:
+			return
+		}
+	}
+}
+
+func (emitter *PrometheusPushEmitter) push() {
+	emitter.mu.Lock()
+	pusher, logger := emitter.pusher, emitter.logger
+	emitter.mu.Unlock()
+
+	if pusher == nil {
+		return
+	}
+
+	// the gauges keep their values in the registry, so a failed push is simply
+	// retried with the latest values on the next tick
+	err := pusher.Push()
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return
+	}
+}
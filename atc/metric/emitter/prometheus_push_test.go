@@ -0,0 +1,98 @@
+package emitter_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusPushEmitter", func() {
+	type push struct {
+		method string
+		path   string
+		body   string
+	}
+
+	var (
+		gateway *httptest.Server
+		pushes  chan push
+	)
+
+	BeforeEach(func() {
+		pushes = make(chan push, 10)
+
+		gateway = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			pushes <- push{method: r.Method, path: r.URL.Path, body: string(body)}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	})
+
+	AfterEach(func() {
+		gateway.Close()
+	})
+
+	It("pushes the metrics grouped by host", func() {
+		logger := lagertest.NewTestLogger("test")
+
+		config := &emitter.PrometheusPushConfig{
+			URL:      gateway.URL,
+			Job:      "concourse",
+			Interval: time.Hour,
+		}
+
+		e, err := config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+
+		e.Emit(logger, metric.Event{
+			Name:  "some metric",
+			Value: 42,
+			Host:  "some-host",
+			State: metric.EventStateOK,
+		})
+
+		Expect(e.Close()).To(Succeed())
+
+		var pushed push
+		Expect(pushes).To(Receive(&pushed))
+		Expect(pushed.method).To(Equal(http.MethodPut))
+		Expect(pushed.path).To(Equal("/metrics/job/concourse/host/some-host"))
+		Expect(pushed.body).To(ContainSubstring("concourse_some_metric"))
+
+		Expect(logger.LogMessages()).NotTo(ContainElement(ContainSubstring("failed-to-send-metric")))
+	})
+
+	It("stops pushing once closed", func() {
+		config := &emitter.PrometheusPushConfig{
+			URL:      gateway.URL,
+			Job:      "concourse",
+			Interval: 10 * time.Millisecond,
+		}
+
+		e, err := config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+
+		e.Emit(lagertest.NewTestLogger("test"), metric.Event{
+			Name:  "some metric",
+			Value: 42,
+			Host:  "some-host",
+		})
+
+		Eventually(pushes).Should(Receive())
+		Expect(e.Close()).To(Succeed())
+
+		for len(pushes) > 0 {
+			<-pushes
+		}
+
+		Consistently(pushes, 50*time.Millisecond).ShouldNot(Receive())
+	})
+})
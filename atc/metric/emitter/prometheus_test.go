@@ -0,0 +1,98 @@
+package emitter_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrometheusEmitter", func() {
+	var config *emitter.PrometheusConfig
+
+	BeforeEach(func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		port := listener.Addr().(*net.TCPAddr).Port
+		Expect(listener.Close()).To(Succeed())
+
+		config = &emitter.PrometheusConfig{
+			BindIP:   "127.0.0.1",
+			BindPort: strconv.Itoa(port),
+		}
+	})
+
+	scrape := func() (string, error) {
+		response, err := http.Get("http://" + config.BindIP + ":" + config.BindPort + "/metrics")
+		if err != nil {
+			return "", err
+		}
+
+		defer response.Body.Close()
+
+		body, err := ioutil.ReadAll(response.Body)
+		return string(body), err
+	}
+
+	Context("when a new emitter is built while the current one is running", func() {
+		var current, replacement metric.Emitter
+
+		BeforeEach(func() {
+			var err error
+			current, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+
+			replacement, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			current.Close()
+			replacement.Close()
+		})
+
+		It("serves the metrics of the new emitter", func() {
+			replacement.Emit(lagertest.NewTestLogger("test"), metric.Event{
+				Name:  "some metric",
+				Value: 42,
+				Host:  "some-host",
+			})
+
+			Expect(scrape()).To(ContainSubstring("concourse_some_metric"))
+		})
+
+		It("serves the metrics of the current emitter again if the new one is closed", func() {
+			current.Emit(lagertest.NewTestLogger("test"), metric.Event{
+				Name:  "some metric",
+				Value: 42,
+				Host:  "some-host",
+			})
+
+			Expect(replacement.Close()).To(Succeed())
+
+			Expect(scrape()).To(ContainSubstring("concourse_some_metric"))
+		})
+
+		It("keeps serving the new emitter once the current one is closed", func() {
+			Expect(current.Close()).To(Succeed())
+
+			Expect(scrape()).To(ContainSubstring("concourse_builds_started"))
+		})
+
+		It("stops listening once both are closed", func() {
+			Expect(current.Close()).To(Succeed())
+			Expect(replacement.Close()).To(Succeed())
+
+			_, err := scrape()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,116 @@
+package emitter
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// proxyDialTimeout bounds how long checking whether a proxy is reachable
+// holds up building an emitter.
+const proxyDialTimeout = 5 * time.Second
+
+// ProxyConfig configures the proxy which emitters that send metrics over HTTP
+// go through. Without one, the proxy is taken from the HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY environment variables. Each emitter namespaces the
+// flags, e.g. --webhook-proxy-url.
+type ProxyConfig struct {
+	URL string `long:"proxy-url" description:"URL of the HTTP proxy to send requests through, overriding the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables."`
+}
+
+// transport returns a transport which sends requests through the proxy, and
+// checks whether the proxy for requests to the target is reachable.
+func (config ProxyConfig) transport(prefix string, target string, tlsConfig *tls.Config) (*http.Transport, *proxyCheck, error) {
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+
+	if config.URL != "" {
+		proxyURL, err := url.Parse(config.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid %s proxy url: %s", prefix, err)
+		}
+
+		if proxyURL.Host == "" {
+			return nil, nil, fmt.Errorf("invalid %s proxy url '%s': must include a host", prefix, config.URL)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, checkProxy(transport, target), nil
+}
+
+// proxyCheck holds the outcome of checking whether a proxy is reachable, so
+// that emitters can log it with the logger they are given when first sending
+// metrics.
+type proxyCheck struct {
+	proxy string
+	err   error
+
+	logged sync.Once
+}
+
+func checkProxy(transport *http.Transport, target string) *proxyCheck {
+	req, err := http.NewRequest("POST", target, nil)
+	if err != nil {
+		// the target is invalid, which fails each request anyway
+		return &proxyCheck{}
+	}
+
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		return &proxyCheck{err: fmt.Errorf("failed to determine proxy: %s", err)}
+	}
+
+	if proxyURL == nil {
+		return &proxyCheck{}
+	}
+
+	// only the host is kept, as the URL may carry credentials
+	check := &proxyCheck{proxy: proxyURL.Host}
+
+	address := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5":
+			port = "1080"
+		}
+
+		address = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+
+	conn, err := net.DialTimeout("tcp", address, proxyDialTimeout)
+	if err != nil {
+		check.err = fmt.Errorf("proxy '%s' is unreachable: %s", proxyURL.Host, err)
+		return check
+	}
+
+	conn.Close()
+
+	return check
+}
+
+// logUnreachable logs, once, that the proxy was unreachable when the emitter
+// was built.
+func (check *proxyCheck) logUnreachable(logger lager.Logger) {
+	if check == nil || check.err == nil {
+		return
+	}
+
+	check.logged.Do(func() {
+		logger.Error("proxy-unreachable", check.err, lager.Data{
+			"proxy": check.proxy,
+		})
+	})
+}
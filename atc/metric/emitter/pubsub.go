@@ -0,0 +1,137 @@
+package emitter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type PubSubEmitter struct {
+	topic   *pubsub.Topic
+	results chan pubSubResult
+	done    chan struct{}
+}
+
+type PubSubConfig struct {
+	ProjectID     string        `long:"pubsub-project-id"     description:"Google Cloud project ID of the Pub/Sub topic to publish metrics to."`
+	Topic         string        `long:"pubsub-topic"          description:"Pub/Sub topic to publish metrics to."`
+	MaxBatch      int           `long:"pubsub-max-batch"      description:"Publish a batch once it contains this many metrics. Defaults to the client's setting."`
+	FlushInterval time.Duration `long:"pubsub-flush-interval" description:"Publish a non-empty batch after this delay. Defaults to the client's setting."`
+}
+
+type pubSubEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+type pubSubResult struct {
+	logger lager.Logger
+	name   string
+	result *pubsub.PublishResult
+}
+
+func init() {
+	metric.RegisterEmitter(&PubSubConfig{})
+}
+
+func (config *PubSubConfig) Description() string { return "Google Pub/Sub" }
+func (config *PubSubConfig) FlagPrefix() string  { return "pubsub" }
+func (config *PubSubConfig) IsConfigured() bool {
+	return config.ProjectID != "" && config.Topic != ""
+}
+
+func (config *PubSubConfig) NewEmitter() (metric.Emitter, error) {
+	// credentials are discovered through the application default credentials
+	client, err := pubsub.NewClient(context.Background(), config.ProjectID)
+	if err != nil {
+		return &PubSubEmitter{}, err
+	}
+
+	topic := client.Topic(config.Topic)
+
+	if config.MaxBatch > 0 {
+		topic.PublishSettings.CountThreshold = config.MaxBatch
+	}
+
+	if config.FlushInterval > 0 {
+		topic.PublishSettings.DelayThreshold = config.FlushInterval
+	}
+
+	emitter := &PubSubEmitter{
+		topic:   topic,
+		results: make(chan pubSubResult, 10000),
+		done:    make(chan struct{}),
+	}
+
+	go emitter.collectResults()
+
+	return emitter, nil
+}
+
+func (emitter *PubSubEmitter) Emit(logger lager.Logger, event metric.Event) {
+	payload, err := json.Marshal(pubSubEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+	if err != nil {
+		logger.Error("failed-to-serialize-event", err)
+		return
+	}
+
+	attributes := map[string]string{}
+	for k, v := range event.Attributes {
+		attributes[k] = v
+	}
+
+	attributes["name"] = event.Name
+	attributes["host"] = event.Host
+	attributes["state"] = string(event.State)
+
+	result := emitter.topic.Publish(context.Background(), &pubsub.Message{
+		Data:       payload,
+		Attributes: attributes,
+	})
+
+	select {
+	case emitter.results <- pubSubResult{logger: logger, name: event.Name, result: result}:
+	default:
+		logger.Info("dropped-publish-result", lager.Data{
+			"metric-name": event.Name,
+		})
+	}
+}
+
+// Close publishes any batched metrics and waits for their results.
+func (emitter *PubSubEmitter) Close() error {
+	emitter.topic.Stop()
+	close(emitter.results)
+	<-emitter.done
+
+	return nil
+}
+
+func (emitter *PubSubEmitter) collectResults() {
+	defer close(emitter.done)
+
+	for result := range emitter.results {
+		_, err := result.result.Get(context.Background())
+		if err != nil {
+			result.logger.Error("failed-to-send-metric",
+				errors.Wrap(metric.ErrFailedToEmit, err.Error()),
+				lager.Data{"metric-name": result.name})
+		}
+	}
+}
@@ -3,6 +3,7 @@ package emitter
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"code.cloudfoundry.org/lager"
 	"github.com/The-Cloud-Source/goryman"
@@ -16,6 +17,7 @@ type RiemannEmitter struct {
 
 	servicePrefix string
 	tags          []string
+	ttl           float32
 }
 
 type RiemannConfig struct {
@@ -25,6 +27,8 @@ type RiemannConfig struct {
 	ServicePrefix string `long:"riemann-service-prefix" default:"" description:"An optional prefix for emitted Riemann services"`
 
 	Tags []string `long:"riemann-tag" description:"Tag to attach to emitted metrics. Can be specified multiple times." value-name:"TAG"`
+
+	TTL time.Duration `long:"riemann-ttl" default:"0s" description:"Default time-to-live to attach to emitted Riemann events."`
 }
 
 func init() {
@@ -32,6 +36,7 @@ func init() {
 }
 
 func (config *RiemannConfig) Description() string { return "Riemann" }
+func (config *RiemannConfig) FlagPrefix() string  { return "riemann" }
 func (config *RiemannConfig) IsConfigured() bool  { return config.Host != "" }
 
 func (config *RiemannConfig) NewEmitter() (metric.Emitter, error) {
@@ -41,6 +46,7 @@ func (config *RiemannConfig) NewEmitter() (metric.Emitter, error) {
 
 		servicePrefix: config.ServicePrefix,
 		tags:          config.Tags,
+		ttl:           float32(config.TTL.Seconds()),
 	}, nil
 }
 
@@ -57,14 +63,15 @@ func (emitter *RiemannEmitter) Emit(logger lager.Logger, event metric.Event) {
 
 	err := emitter.client.SendEvent(&goryman.Event{
 		Service:    emitter.servicePrefix + event.Name,
-		Metric:     event.Value,
+		Metric:     eventValue(event.Value),
 		State:      string(event.State),
 		Attributes: event.Attributes,
 
 		Host: event.Host,
-		Time: event.Time.Unix(),
+		Time: event.Timestamp().Unix(),
 
 		Tags: emitter.tags,
+		Ttl:  emitter.ttl,
 	})
 	if err != nil {
 		logger.Error("failed-to-send-metric",
@@ -77,3 +84,14 @@ func (emitter *RiemannEmitter) Emit(logger lager.Logger, event metric.Event) {
 		emitter.connected = false
 	}
 }
+
+// Close closes the connection to Riemann, if any.
+func (emitter *RiemannEmitter) Close() error {
+	if !emitter.connected {
+		return nil
+	}
+
+	emitter.connected = false
+
+	return emitter.client.Close()
+}
@@ -0,0 +1,177 @@
+package emitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const (
+	signalFxMaxDimensionKeyLength   = 128
+	signalFxMaxDimensionValueLength = 256
+)
+
+type SignalFxEmitter struct {
+	client  *http.Client
+	url     string
+	token   string
+	proxy   *proxyCheck
+	batcher *batcher
+}
+
+type SignalFxConfig struct {
+	Token string `long:"signalfx-token" description:"SignalFx access token to authenticate with."`
+	Realm string `long:"signalfx-realm" default:"us0" description:"SignalFx realm to send datapoints to."`
+
+	IngestURL string `long:"signalfx-ingest-url" description:"URL of the ingest API to send datapoints to, e.g. of a SignalFx Smart Gateway. Defaults to the ingest API of the realm."`
+
+	Proxy ProxyConfig `group:"SignalFx Proxy" namespace:"signalfx"`
+
+	BatchSize     int           `long:"signalfx-batch-size"     default:"100" description:"Number of datapoints to send to SignalFx in a single request."`
+	FlushInterval time.Duration `long:"signalfx-flush-interval" default:"10s" description:"Interval on which to flush batched datapoints to SignalFx, regardless of the batch size."`
+}
+
+type signalFxDatapoint struct {
+	Metric     string            `json:"metric"`
+	Value      float64           `json:"value"`
+	Dimensions map[string]string `json:"dimensions"`
+	Timestamp  int64             `json:"timestamp"`
+}
+
+func init() {
+	metric.RegisterEmitter(&SignalFxConfig{})
+}
+
+func (config *SignalFxConfig) Description() string { return "SignalFx" }
+func (config *SignalFxConfig) FlagPrefix() string  { return "signalfx" }
+func (config *SignalFxConfig) IsConfigured() bool  { return config.Token != "" }
+
+func (config *SignalFxConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("signalfx", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &SignalFxEmitter{}, err
+	}
+
+	ingestURL := config.IngestURL
+	if ingestURL == "" {
+		ingestURL = fmt.Sprintf("https://ingest.%s.signalfx.com", config.Realm)
+	}
+
+	datapointURL := strings.TrimSuffix(ingestURL, "/") + "/v2/datapoint"
+
+	transport, proxy, err := config.Proxy.transport("signalfx", datapointURL, nil)
+	if err != nil {
+		return &SignalFxEmitter{}, err
+	}
+
+	emitter := &SignalFxEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:   datapointURL,
+		token: config.Token,
+		proxy: proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.send)
+
+	return emitter, nil
+}
+
+func (emitter *SignalFxEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *SignalFxEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	name := normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-signalfx", nil, lager.Data{
+			"metric-name": name,
+		})
+		return nil
+	}
+
+	dimensions := map[string]string{
+		"host":  event.Host,
+		"state": string(event.State),
+	}
+
+	for k, v := range event.Attributes {
+		dimensions[k] = v
+	}
+
+	datapoint := signalFxDatapoint{
+		Metric:     name,
+		Value:      value,
+		Dimensions: map[string]string{},
+		Timestamp:  event.Timestamp().UnixNano() / int64(time.Millisecond),
+	}
+
+	for k, v := range dimensions {
+		if v == "" {
+			continue
+		}
+
+		datapoint.Dimensions[signalFxDimensionKey(k)] = truncate(v, signalFxMaxDimensionValueLength)
+	}
+
+	return emitter.batcher.Add(logger, datapoint)
+}
+
+// Close flushes any batched datapoints.
+func (emitter *SignalFxEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+// signalFxDimensionKey strips the characters SignalFx disallows in dimension
+// keys, along with leading underscores which are reserved.
+func signalFxDimensionKey(key string) string {
+	key = strings.TrimLeft(specialChars.ReplaceAllString(key, "_"), "_")
+	return truncate(key, signalFxMaxDimensionKeyLength)
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
+
+func (emitter *SignalFxEmitter) send(logger lager.Logger, datapoints []interface{}) error {
+	payload, err := json.Marshal(map[string][]interface{}{
+		"gauge": datapoints,
+	})
+	if err != nil {
+		logger.Error("failed-to-serialize-payload", err)
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = post(emitter.client, emitter.url, http.Header{
+		"Content-Type": {"application/json"},
+		"X-SF-Token":   {emitter.token},
+	}, payload, 3, retryServerErrors)
+	if err != nil {
+		logger.Error("failed-to-send-datapoints",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package emitter_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type signalFxRequest struct {
+	Token string
+	Gauge []struct {
+		Metric     string            `json:"metric"`
+		Value      float64           `json:"value"`
+		Dimensions map[string]string `json:"dimensions"`
+		Timestamp  int64             `json:"timestamp"`
+	}
+}
+
+var _ = Describe("SignalFxEmitter", func() {
+	var (
+		logger   *lagertest.TestLogger
+		server   *httptest.Server
+		requests chan signalFxRequest
+		e        metric.Emitter
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		requests = make(chan signalFxRequest, 10)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.URL.Path).To(Equal("/v2/datapoint"))
+
+			var request signalFxRequest
+			Expect(json.NewDecoder(r.Body).Decode(&request)).To(Succeed())
+			request.Token = r.Header.Get("X-SF-Token")
+
+			requests <- request
+		}))
+
+		config := &emitter.SignalFxConfig{
+			Token:         "some-token",
+			IngestURL:     server.URL + "/",
+			BatchSize:     100,
+			FlushInterval: time.Hour,
+		}
+
+		var err error
+		e, err = config.NewEmitter()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	emit := func(event metric.Event) signalFxRequest {
+		e.Emit(logger, event)
+		Expect(e.Close()).To(Succeed())
+
+		var request signalFxRequest
+		Eventually(requests).Should(Receive(&request))
+		Expect(request.Gauge).To(HaveLen(1))
+
+		return request
+	}
+
+	It("sends the event as a gauge datapoint with the token", func() {
+		request := emit(metric.Event{
+			Name:       "worker containers",
+			Value:      5,
+			Host:       "some-host",
+			State:      metric.EventStateOK,
+			Attributes: map[string]string{"worker": "some-worker"},
+			Time:       time.Unix(1, 2000000),
+		})
+
+		Expect(request.Token).To(Equal("some-token"))
+		Expect(request.Gauge[0].Metric).To(Equal("worker_containers"))
+		Expect(request.Gauge[0].Value).To(Equal(5.0))
+		Expect(request.Gauge[0].Timestamp).To(Equal(int64(1002)))
+		Expect(request.Gauge[0].Dimensions).To(Equal(map[string]string{
+			"host":   "some-host",
+			"state":  "ok",
+			"worker": "some-worker",
+		}))
+	})
+
+	It("sanitizes the dimension keys", func() {
+		request := emit(metric.Event{
+			Name:  "worker containers",
+			Value: 5,
+			Host:  "some-host",
+			Attributes: map[string]string{
+				"_reserved":      "a",
+				"team.name":      "b",
+				"some key-value": "c",
+			},
+		})
+
+		Expect(request.Gauge[0].Dimensions).To(Equal(map[string]string{
+			"host":           "some-host",
+			"reserved":       "a",
+			"team_name":      "b",
+			"some_key_value": "c",
+		}))
+	})
+
+	It("truncates the dimension keys and values", func() {
+		request := emit(metric.Event{
+			Name:  "worker containers",
+			Value: 5,
+			Host:  "some-host",
+			Attributes: map[string]string{
+				strings.Repeat("k", 200): strings.Repeat("v", 300),
+			},
+		})
+
+		Expect(request.Gauge[0].Dimensions).To(HaveKeyWithValue(strings.Repeat("k", 128), strings.Repeat("v", 256)))
+	})
+
+	It("leaves out dimensions without a value", func() {
+		request := emit(metric.Event{
+			Name:       "worker containers",
+			Value:      5,
+			Host:       "some-host",
+			Attributes: map[string]string{"worker": ""},
+		})
+
+		Expect(request.Gauge[0].Dimensions).To(Equal(map[string]string{
+			"host": "some-host",
+		}))
+	})
+})
@@ -0,0 +1,165 @@
+package emitter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type SplunkEmitter struct {
+	client     *http.Client
+	url        string
+	token      string
+	index      string
+	sourcetype string
+	compressor *compressor
+	proxy      *proxyCheck
+	batcher    *batcher
+}
+
+type SplunkConfig struct {
+	URL                string `long:"splunk-url" description:"Splunk HTTP Event Collector URL, e.g. https://splunk.example.com:8088."`
+	Token              string `long:"splunk-token" description:"HTTP Event Collector token to authenticate with."`
+	Index              string `long:"splunk-index" description:"Splunk index to send events to. Defaults to the token's default index."`
+	Sourcetype         string `long:"splunk-sourcetype" default:"concourse:metric" description:"Sourcetype to assign to the events."`
+	InsecureSkipVerify bool   `long:"splunk-insecure-skip-verify" description:"Skip TLS verification of the HTTP Event Collector's certificate. Deprecated in favour of --splunk-skip-verify."`
+
+	TLS   TLSConfig   `group:"Splunk TLS" namespace:"splunk"`
+	Proxy ProxyConfig `group:"Splunk Proxy" namespace:"splunk"`
+
+	Compress bool `long:"splunk-compress" description:"Gzip-compress request bodies larger than 1KB. Falls back to uncompressed requests if the HTTP Event Collector does not accept them."`
+
+	BatchSize     int           `long:"splunk-batch-size"     default:"100" description:"Number of events to send to Splunk in a single request."`
+	FlushInterval time.Duration `long:"splunk-flush-interval" default:"10s" description:"Interval on which to flush batched events to Splunk, regardless of the batch size."`
+}
+
+type splunkEnvelope struct {
+	Time       float64     `json:"time"`
+	Host       string      `json:"host"`
+	Index      string      `json:"index,omitempty"`
+	Sourcetype string      `json:"sourcetype"`
+	Event      splunkEvent `json:"event"`
+}
+
+type splunkEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+func init() {
+	metric.RegisterEmitter(&SplunkConfig{})
+}
+
+func (config *SplunkConfig) Description() string { return "Splunk" }
+func (config *SplunkConfig) FlagPrefix() string  { return "splunk" }
+func (config *SplunkConfig) IsConfigured() bool {
+	return config.URL != "" && config.Token != ""
+}
+
+func (config *SplunkConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("splunk", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &SplunkEmitter{}, err
+	}
+
+	tlsConfig, err := config.TLS.TLSClientConfig()
+	if err != nil {
+		return &SplunkEmitter{}, err
+	}
+
+	tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || config.InsecureSkipVerify
+
+	eventURL := strings.TrimSuffix(config.URL, "/") + "/services/collector/event"
+
+	transport, proxy, err := config.Proxy.transport("splunk", eventURL, tlsConfig)
+	if err != nil {
+		return &SplunkEmitter{}, err
+	}
+
+	emitter := &SplunkEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:        eventURL,
+		token:      config.Token,
+		index:      config.Index,
+		sourcetype: config.Sourcetype,
+		compressor: newCompressor(config.Compress),
+		proxy:      proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.send)
+
+	return emitter, nil
+}
+
+func (emitter *SplunkEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *SplunkEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	return emitter.batcher.Add(logger, splunkEnvelope{
+		Time:       float64(event.Timestamp().UnixNano()) / float64(time.Second),
+		Host:       event.Host,
+		Index:      emitter.index,
+		Sourcetype: emitter.sourcetype,
+		Event: splunkEvent{
+			Name:       event.Name,
+			Value:      eventValue(event.Value),
+			State:      string(event.State),
+			Attributes: event.Attributes,
+		},
+	})
+}
+
+// Close flushes any batched events.
+func (emitter *SplunkEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+func (emitter *SplunkEmitter) send(logger lager.Logger, envelopes []interface{}) error {
+	// HEC accepts multiple events in one request as concatenated JSON objects
+	payload := bytes.Buffer{}
+	encoder := json.NewEncoder(&payload)
+
+	for _, envelope := range envelopes {
+		err := encoder.Encode(envelope)
+		if err != nil {
+			logger.Error("failed-to-serialize-event", err)
+			return err
+		}
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err := emitter.compressor.post(logger, emitter.client, emitter.url, http.Header{
+		"Content-Type":  {"application/json"},
+		"Authorization": {"Splunk " + emitter.token},
+	}, payload.Bytes(), 3, retryServiceUnavailable)
+	if err != nil {
+		logger.Error("failed-to-send-events",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// retryServiceUnavailable retries when HEC is busy, i.e. its queues are full.
+func retryServiceUnavailable(statusCode int) bool {
+	return statusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,171 @@
+package emitter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3"
+	metricpb "google.golang.org/genproto/googleapis/api/metric"
+	monitoredrespb "google.golang.org/genproto/googleapis/api/monitoredres"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stackdriverMaxTimeSeries is the maximum number of time series accepted by a
+// single CreateTimeSeries call
+const stackdriverMaxTimeSeries = 200
+
+type StackdriverEmitter struct {
+	client       *monitoring.MetricClient
+	projectID    string
+	resourceType string
+	batcher      *batcher
+}
+
+type StackdriverConfig struct {
+	ProjectID     string        `long:"stackdriver-project-id"                      description:"Google Cloud project ID to emit metrics to."`
+	ResourceType  string        `long:"stackdriver-resource-type"  default:"global" description:"Monitored resource type to attach to emitted time series."`
+	FlushInterval time.Duration `long:"stackdriver-flush-interval" default:"60s"    description:"Interval on which to flush batched time series to Cloud Monitoring."`
+}
+
+func init() {
+	metric.RegisterEmitter(&StackdriverConfig{})
+}
+
+func (config *StackdriverConfig) Description() string { return "Stackdriver" }
+func (config *StackdriverConfig) FlagPrefix() string  { return "stackdriver" }
+func (config *StackdriverConfig) IsConfigured() bool  { return config.ProjectID != "" }
+
+func (config *StackdriverConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkInterval("stackdriver-flush-interval", config.FlushInterval)
+	if err != nil {
+		return &StackdriverEmitter{}, err
+	}
+
+	// credentials are discovered through the application default credentials
+	client, err := monitoring.NewMetricClient(context.Background())
+	if err != nil {
+		return &StackdriverEmitter{}, err
+	}
+
+	emitter := &StackdriverEmitter{
+		client:       client,
+		projectID:    config.ProjectID,
+		resourceType: config.ResourceType,
+	}
+
+	emitter.batcher = newBatcher(stackdriverMaxTimeSeries, config.FlushInterval, emitter.createTimeSeries)
+
+	return emitter, nil
+}
+
+func (emitter *StackdriverEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *StackdriverEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	name := normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-stackdriver", nil, lager.Data{
+			"metric-name": name,
+		})
+		return nil
+	}
+
+	timestamp, err := ptypes.TimestampProto(event.Timestamp())
+	if err != nil {
+		logger.Error("failed-to-convert-timestamp", err)
+		return nil
+	}
+
+	labels := map[string]string{
+		"host":  event.Host,
+		"state": string(event.State),
+	}
+
+	for k, v := range event.Attributes {
+		labels[normalizeName(k)] = v
+	}
+
+	return emitter.batcher.Add(logger, &monitoringpb.TimeSeries{
+		Metric: &metricpb.Metric{
+			Type:   "custom.googleapis.com/concourse/" + name,
+			Labels: labels,
+		},
+		Resource: &monitoredrespb.MonitoredResource{
+			Type: emitter.resourceType,
+			Labels: map[string]string{
+				"project_id": emitter.projectID,
+			},
+		},
+		Points: []*monitoringpb.Point{
+			{
+				Interval: &monitoringpb.TimeInterval{
+					EndTime: timestamp,
+				},
+				Value: &monitoringpb.TypedValue{
+					Value: &monitoringpb.TypedValue_DoubleValue{
+						DoubleValue: value,
+					},
+				},
+			},
+		},
+	})
+}
+
+// Close flushes any batched time series and closes the client.
+func (emitter *StackdriverEmitter) Close() error {
+	emitter.batcher.Close()
+	return emitter.client.Close()
+}
+
+func (emitter *StackdriverEmitter) createTimeSeries(logger lager.Logger, items []interface{}) error {
+	// the API rejects requests containing more than one point for the same time
+	// series, so only the latest point for each one is sent
+	series := []*monitoringpb.TimeSeries{}
+	seen := map[string]int{}
+
+	for _, item := range items {
+		ts := item.(*monitoringpb.TimeSeries)
+
+		key := fmt.Sprintf("%s%v", ts.Metric.Type, ts.Metric.Labels)
+		if i, found := seen[key]; found {
+			series[i] = ts
+			continue
+		}
+
+		seen[key] = len(series)
+		series = append(series, ts)
+	}
+
+	err := emitter.client.CreateTimeSeries(context.Background(), &monitoringpb.CreateTimeSeriesRequest{
+		Name:       "projects/" + emitter.projectID,
+		TimeSeries: series,
+	})
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+			return metric.TransientError{Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package emitter
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/DataDog/datadog-go/statsd"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type StatsdEmitter struct {
+	client *statsd.Client
+}
+
+type StatsdConfig struct {
+	Host   string `long:"statsd-host" description:"StatsD server host to emit metrics to."`
+	Port   string `long:"statsd-port" description:"StatsD server port to emit metrics to."`
+	Prefix string `long:"statsd-prefix" description:"Prefix for all metrics to easily find them in StatsD."`
+}
+
+func init() {
+	metric.RegisterEmitter(&StatsdConfig{})
+}
+
+func (config *StatsdConfig) Description() string { return "StatsD" }
+func (config *StatsdConfig) FlagPrefix() string  { return "statsd" }
+func (config *StatsdConfig) IsConfigured() bool  { return config.Host != "" && config.Port != "" }
+
+func (config *StatsdConfig) NewEmitter() (metric.Emitter, error) {
+	client, err := statsd.New(fmt.Sprintf("%s:%s", config.Host, config.Port))
+	if err != nil {
+		return &StatsdEmitter{}, err
+	}
+
+	client.Namespace = namespace(config.Prefix)
+
+	return &StatsdEmitter{
+		client: client,
+	}, nil
+}
+
+func (emitter *StatsdEmitter) Emit(logger lager.Logger, event metric.Event) {
+	// plain statsd has no tags, so the host and state become part of the metric
+	// path instead
+	name := strings.Join([]string{
+		pathComponent(event.Host),
+		pathComponent(string(event.State)),
+		normalizeName(event.Name),
+	}, ".")
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-statsd", nil, lager.Data{
+			"metric-name": name,
+		})
+		return
+	}
+
+	err = emitter.client.Gauge(name, value, nil, 1)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return
+	}
+}
+
+// Close closes the client.
+func (emitter *StatsdEmitter) Close() error {
+	return emitter.client.Close()
+}
@@ -0,0 +1,190 @@
+package emitter
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const (
+	// syslogMaxUDPMessageSize is the message size every RFC5424 UDP receiver
+	// must accept
+	syslogMaxUDPMessageSize = 1024
+
+	// syslogFacility is local0
+	syslogFacility = 16
+
+	// syslogSDID identifies the structured data element carrying the metric;
+	// 32473 is the private enterprise number reserved for documentation
+	syslogSDID = "metric@32473"
+)
+
+type SyslogEmitter struct {
+	hostname string
+	appName  string
+
+	udpConn   net.Conn
+	tcpWriter *tcpWriter
+}
+
+type SyslogConfig struct {
+	Address   string `long:"syslog-address" description:"Address of the syslog server to emit metrics to, e.g. syslog.example.com:514."`
+	Transport string `long:"syslog-transport" default:"udp" choice:"udp" choice:"tcp" description:"Transport to send syslog messages over."`
+	Hostname  string `long:"syslog-hostname" description:"Hostname to send in syslog messages. Defaults to the host of the metric."`
+	AppName   string `long:"syslog-app-name" default:"concourse" description:"Application name to send in syslog messages."`
+
+	MaxConns int `long:"syslog-max-conns" default:"1" description:"Number of connections to the syslog server to write messages over in turn, with the tcp transport."`
+}
+
+func init() {
+	metric.RegisterEmitter(&SyslogConfig{})
+}
+
+func (config *SyslogConfig) Description() string { return "Syslog" }
+func (config *SyslogConfig) FlagPrefix() string  { return "syslog" }
+func (config *SyslogConfig) IsConfigured() bool  { return config.Address != "" }
+
+func (config *SyslogConfig) NewEmitter() (metric.Emitter, error) {
+	emitter := &SyslogEmitter{
+		hostname: config.Hostname,
+		appName:  config.AppName,
+	}
+
+	if config.Transport == "tcp" {
+		emitter.tcpWriter = newTCPWriter(config.Address, config.MaxConns)
+		return emitter, nil
+	}
+
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return &SyslogEmitter{}, err
+	}
+
+	emitter.udpConn = conn
+
+	return emitter, nil
+}
+
+func (emitter *SyslogEmitter) Emit(logger lager.Logger, event metric.Event) {
+	params := []string{
+		syslogParam("name", event.Name),
+		syslogParam("value", fmt.Sprintf("%v", eventValue(event.Value))),
+		syslogParam("state", string(event.State)),
+	}
+
+	keys := []string{}
+	for k := range event.Attributes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		params = append(params, syslogParam(k, event.Attributes[k]))
+	}
+
+	message := emitter.format(event, params)
+
+	if emitter.tcpWriter != nil {
+		// octet-counting framing as described in RFC6587
+		emitter.tcpWriter.Write(logger, fmt.Sprintf("%d %s", len(message), message))
+		return
+	}
+
+	if len(message) > syslogMaxUDPMessageSize {
+		// drop attributes until the message fits, keeping the metric itself
+		truncated := len(params)
+		for len(params) > 3 && len(message) > syslogMaxUDPMessageSize {
+			params = params[:len(params)-1]
+			message = emitter.format(event, params)
+		}
+
+		logger.Info("truncated-structured-data", lager.Data{
+			"metric-name":        event.Name,
+			"dropped-attributes": truncated - len(params),
+		})
+
+		if len(message) > syslogMaxUDPMessageSize {
+			message = message[:syslogMaxUDPMessageSize]
+		}
+	}
+
+	_, err := emitter.udpConn.Write([]byte(message))
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+// Close closes the connection to the syslog server.
+func (emitter *SyslogEmitter) Close() error {
+	if emitter.tcpWriter != nil {
+		return emitter.tcpWriter.Close()
+	}
+
+	return emitter.udpConn.Close()
+}
+
+func (emitter *SyslogEmitter) format(event metric.Event, params []string) string {
+	hostname := emitter.hostname
+	if hostname == "" {
+		hostname = event.Host
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - - [%s %s] %s: %v",
+		syslogFacility*8+syslogSeverity(event.State),
+		event.Timestamp().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(hostname, 255),
+		syslogHeaderField(emitter.appName, 48),
+		syslogSDID,
+		strings.Join(params, " "),
+		event.Name,
+		eventValue(event.Value),
+	)
+}
+
+func syslogSeverity(state metric.EventState) int {
+	switch state {
+	case metric.EventStateCritical:
+		return 2
+	case metric.EventStateWarning:
+		return 4
+	default:
+		return 6
+	}
+}
+
+// syslogHeaderField strips characters which aren't allowed in a header field,
+// falling back to the nil value if nothing is left.
+func syslogHeaderField(value string, length int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, value)
+
+	if value == "" {
+		return "-"
+	}
+
+	return truncate(value, length)
+}
+
+var syslogParamValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func syslogParam(name string, value string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	return fmt.Sprintf(`%s="%s"`, truncate(name, 32), syslogParamValueEscaper.Replace(value))
+}
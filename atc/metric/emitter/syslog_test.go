@@ -0,0 +1,243 @@
+package emitter_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/emitter"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SyslogEmitter", func() {
+	var (
+		logger *lagertest.TestLogger
+		e      metric.Emitter
+
+		event metric.Event
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		event = metric.Event{
+			Name:  "worker containers",
+			Value: 5,
+			State: metric.EventStateOK,
+			Host:  "some-host",
+		}
+	})
+
+	Context("over UDP", func() {
+		var server *net.UDPConn
+
+		BeforeEach(func() {
+			var err error
+			server, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			Expect(err).NotTo(HaveOccurred())
+
+			config := &emitter.SyslogConfig{
+				Address:   server.LocalAddr().String(),
+				Transport: "udp",
+				AppName:   "concourse",
+			}
+
+			e, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(e.Close()).To(Succeed())
+			server.Close()
+		})
+
+		receive := func() string {
+			buf := make([]byte, 65536)
+			Expect(server.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+			n, err := server.Read(buf)
+			Expect(err).NotTo(HaveOccurred())
+			return string(buf[:n])
+		}
+
+		It("sends the metric as structured data", func() {
+			e.Emit(logger, event)
+
+			message := receive()
+			Expect(message).To(HavePrefix("<134>1 "))
+			Expect(message).To(ContainSubstring(` some-host concourse - - [metric@32473 name="worker containers" value="5" state="ok"] worker containers: 5`))
+		})
+
+		It("escapes the names and values of the parameters", func() {
+			event.Attributes = map[string]string{
+				`some=na]me "x"`:        `a "quoted" \ value]`,
+				strings.Repeat("a", 40): "long",
+			}
+
+			e.Emit(logger, event)
+
+			message := receive()
+			Expect(message).To(ContainSubstring(`some_na_me__x_="a \"quoted\" \\ value\]"`))
+			Expect(message).To(ContainSubstring(` ` + strings.Repeat("a", 32) + `="long"`))
+		})
+
+		Context("when the message is larger than 1024 bytes", func() {
+			BeforeEach(func() {
+				event.Attributes = map[string]string{}
+				for i := 0; i < 40; i++ {
+					event.Attributes[fmt.Sprintf("attribute-%02d", i)] = strings.Repeat("v", 40)
+				}
+			})
+
+			It("drops attributes until it fits, keeping the metric", func() {
+				e.Emit(logger, event)
+
+				message := receive()
+				Expect(len(message)).To(BeNumerically("<=", 1024))
+				Expect(message).To(ContainSubstring(`[metric@32473 name="worker containers" value="5" state="ok" attribute-00=`))
+				Expect(message).NotTo(ContainSubstring("attribute-39"))
+				Expect(message).To(HaveSuffix("] worker containers: 5"))
+
+				Expect(logger.LogMessages()).To(ContainElement("test.truncated-structured-data"))
+			})
+
+			It("cuts the message at 1024 bytes when the metric alone does not fit", func() {
+				event.Name = strings.Repeat("n", 2000)
+
+				e.Emit(logger, event)
+
+				message := receive()
+				Expect(message).To(HaveLen(1024))
+				Expect(message).NotTo(ContainSubstring("attribute-00"))
+			})
+		})
+	})
+
+	Context("over TCP", func() {
+		var (
+			listener net.Listener
+			address  string
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			address = listener.Addr().String()
+		})
+
+		JustBeforeEach(func() {
+			config := &emitter.SyslogConfig{
+				Address:   address,
+				Transport: "tcp",
+				AppName:   "concourse",
+				MaxConns:  1,
+			}
+
+			var err error
+			e, err = config.NewEmitter()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(e.Close()).To(Succeed())
+			listener.Close()
+		})
+
+		// readFrame reads a message framed by octet-counting
+		readFrame := func(reader *bufio.Reader) string {
+			length, err := reader.ReadString(' ')
+			Expect(err).NotTo(HaveOccurred())
+
+			n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			Expect(err).NotTo(HaveOccurred())
+
+			message := make([]byte, n)
+			_, err = io.ReadFull(reader, message)
+			Expect(err).NotTo(HaveOccurred())
+
+			return string(message)
+		}
+
+		accept := func() (net.Conn, *bufio.Reader) {
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+			return conn, bufio.NewReader(conn)
+		}
+
+		It("frames each message with its length", func() {
+			e.Emit(logger, event)
+
+			event.Value = 6
+			e.Emit(logger, event)
+
+			conn, reader := accept()
+			defer conn.Close()
+
+			Expect(readFrame(reader)).To(HaveSuffix("] worker containers: 5"))
+			Expect(readFrame(reader)).To(HaveSuffix("] worker containers: 6"))
+		})
+
+		Context("when the server is not reachable at first", func() {
+			BeforeEach(func() {
+				Expect(listener.Close()).To(Succeed())
+			})
+
+			It("reconnects and sends the messages which failed", func() {
+				e.Emit(logger, event)
+				Expect(logger.LogMessages()).To(ContainElement("test.connection-failed"))
+
+				var err error
+				listener, err = net.Listen("tcp", address)
+				Expect(err).NotTo(HaveOccurred())
+
+				event.Value = 6
+				e.Emit(logger, event)
+
+				conn, reader := accept()
+				defer conn.Close()
+
+				Expect(readFrame(reader)).To(HaveSuffix("] worker containers: 5"))
+				Expect(readFrame(reader)).To(HaveSuffix("] worker containers: 6"))
+			})
+		})
+
+		It("reconnects once the server closes the connection", func() {
+			e.Emit(logger, event)
+
+			conn, reader := accept()
+			Expect(readFrame(reader)).To(HaveSuffix("] worker containers: 5"))
+			Expect(conn.Close()).To(Succeed())
+
+			// the closed connection is only noticed on a later write
+			accepted := make(chan net.Conn, 1)
+			go func() {
+				defer GinkgoRecover()
+
+				conn, err := listener.Accept()
+				if err == nil {
+					accepted <- conn
+				}
+			}()
+
+			event.Value = 6
+			Eventually(func() <-chan net.Conn {
+				e.Emit(logger, event)
+				return accepted
+			}, 5*time.Second, 10*time.Millisecond).Should(Receive(&conn))
+			defer conn.Close()
+
+			Expect(conn.SetReadDeadline(time.Now().Add(5 * time.Second))).To(Succeed())
+			Expect(readFrame(bufio.NewReader(conn))).To(HaveSuffix("] worker containers: 6"))
+		})
+	})
+})
@@ -0,0 +1,129 @@
+package emitter
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+const maxPendingLines = 1000
+
+// tcpWriter maintains a pool of persistent connections for line-based
+// protocols such as Graphite's, writing to them in turn. Lines which fail to
+// be written are kept and retried on the next write, and the connection which
+// failed is replaced.
+type tcpWriter struct {
+	addr string
+
+	conns []*tcpConn
+	next  uint32
+
+	pending     []string
+	pendingLock sync.Mutex
+}
+
+type tcpConn struct {
+	conn net.Conn
+	lock sync.Mutex
+}
+
+func newTCPWriter(addr string, maxConns int) *tcpWriter {
+	if maxConns < 1 {
+		maxConns = 1
+	}
+
+	conns := make([]*tcpConn, maxConns)
+	for i := range conns {
+		conns[i] = &tcpConn{}
+	}
+
+	return &tcpWriter{
+		addr:  addr,
+		conns: conns,
+	}
+}
+
+func (writer *tcpWriter) Write(logger lager.Logger, line string) {
+	lines := writer.takePending(line)
+
+	slot := writer.conns[int(atomic.AddUint32(&writer.next, 1)-1)%len(writer.conns)]
+
+	slot.lock.Lock()
+	defer slot.lock.Unlock()
+
+	if slot.conn == nil {
+		conn, err := net.DialTimeout("tcp", writer.addr, 5*time.Second)
+		if err != nil {
+			logger.Error("connection-failed", err)
+			writer.requeue(lines)
+			return
+		}
+
+		slot.conn = conn
+	}
+
+	_, err := slot.conn.Write([]byte(strings.Join(lines, "")))
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+
+		if err := slot.conn.Close(); err != nil {
+			logger.Error("failed-to-close", err)
+		}
+
+		slot.conn = nil
+		writer.requeue(lines)
+		return
+	}
+}
+
+func (writer *tcpWriter) Close() error {
+	var closeErr error
+
+	for _, slot := range writer.conns {
+		slot.lock.Lock()
+
+		if slot.conn != nil {
+			err := slot.conn.Close()
+			if err != nil {
+				closeErr = err
+			}
+
+			slot.conn = nil
+		}
+
+		slot.lock.Unlock()
+	}
+
+	return closeErr
+}
+
+// takePending returns the lines which are yet to be written, followed by the
+// given one.
+func (writer *tcpWriter) takePending(line string) []string {
+	writer.pendingLock.Lock()
+	defer writer.pendingLock.Unlock()
+
+	lines := append(writer.pending, line)
+	writer.pending = nil
+
+	return lines
+}
+
+// requeue keeps lines which failed to be written for the next write, ahead of
+// any queued in the meantime. Only the latest maxPendingLines are kept.
+func (writer *tcpWriter) requeue(lines []string) {
+	writer.pendingLock.Lock()
+	defer writer.pendingLock.Unlock()
+
+	writer.pending = append(lines, writer.pending...)
+	if len(writer.pending) > maxPendingLines {
+		writer.pending = writer.pending[len(writer.pending)-maxPendingLines:]
+	}
+}
@@ -0,0 +1,55 @@
+package emitter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSConfig configures the TLS client of emitters which send metrics over
+// HTTPS. Each emitter namespaces the flags, e.g. --influxdb-ca-cert.
+type TLSConfig struct {
+	CACert     string `long:"ca-cert"     description:"Path to a PEM-encoded CA certificate to verify the server's certificate with."`
+	ClientCert string `long:"client-cert" description:"Path to a PEM-encoded client certificate to authenticate with."`
+	ClientKey  string `long:"client-key"  description:"Path to the PEM-encoded private key of the client certificate."`
+	SkipVerify bool   `long:"skip-verify" description:"Skip verification of the server's certificate."`
+}
+
+// TLSClientConfig loads the configured certificates, failing if any of them
+// cannot be read.
+func (config TLSConfig) TLSClientConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.SkipVerify,
+	}
+
+	if config.CACert != "" {
+		caCert, err := ioutil.ReadFile(config.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %s", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in CA certificate '%s'", config.CACert)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if (config.ClientCert == "") != (config.ClientKey == "") {
+		return nil, errors.New("client certificate and client key must be configured together")
+	}
+
+	if config.ClientCert != "" {
+		certificate, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %s", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
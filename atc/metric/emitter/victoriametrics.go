@@ -0,0 +1,155 @@
+package emitter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type VictoriaMetricsEmitter struct {
+	client  *http.Client
+	url     string
+	proxy   *proxyCheck
+	batcher *batcher
+}
+
+type VMConfig struct {
+	ImportURL string `long:"vm-import-url" description:"VictoriaMetrics Prometheus import URL, e.g. http://victoriametrics:8428/api/v1/import/prometheus."`
+
+	Proxy ProxyConfig `group:"VictoriaMetrics Proxy" namespace:"vm"`
+
+	BatchSize     int           `long:"vm-batch-size"     default:"1000" description:"Number of samples to send to VictoriaMetrics in a single request."`
+	FlushInterval time.Duration `long:"vm-flush-interval" default:"10s"  description:"Interval on which to flush batched samples to VictoriaMetrics, regardless of the batch size."`
+}
+
+func init() {
+	metric.RegisterEmitter(&VMConfig{})
+}
+
+func (config *VMConfig) Description() string { return "VictoriaMetrics" }
+func (config *VMConfig) FlagPrefix() string  { return "vm" }
+func (config *VMConfig) IsConfigured() bool  { return config.ImportURL != "" }
+
+func (config *VMConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("vm", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &VictoriaMetricsEmitter{}, err
+	}
+
+	transport, proxy, err := config.Proxy.transport("vm", config.ImportURL, nil)
+	if err != nil {
+		return &VictoriaMetricsEmitter{}, err
+	}
+
+	emitter := &VictoriaMetricsEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Minute,
+		},
+		url:   config.ImportURL,
+		proxy: proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.send)
+
+	return emitter, nil
+}
+
+func (emitter *VictoriaMetricsEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *VictoriaMetricsEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	// same naming as the prometheus emitter, so that dashboards work with both
+	name := "concourse_" + normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-victoriametrics", nil, lager.Data{
+			"metric-name": name,
+		})
+		return nil
+	}
+
+	labelValues := map[string]string{
+		"host":  event.Host,
+		"state": string(event.State),
+	}
+
+	for k, v := range event.Attributes {
+		labelValues[specialChars.ReplaceAllString(k, "_")] = v
+	}
+
+	labels := []string{}
+	for label := range labelValues {
+		labels = append(labels, label)
+	}
+
+	sort.Strings(labels)
+
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, label, prometheusLabelValueEscaper.Replace(labelValues[label]))
+	}
+
+	return emitter.batcher.Add(logger, fmt.Sprintf("%s{%s} %s %d\n",
+		name,
+		strings.Join(pairs, ","),
+		strconv.FormatFloat(value, 'f', -1, 64),
+		event.Timestamp().UnixNano()/int64(time.Millisecond),
+	))
+}
+
+// Close flushes any batched samples.
+func (emitter *VictoriaMetricsEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+var prometheusLabelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (emitter *VictoriaMetricsEmitter) send(logger lager.Logger, lines []interface{}) error {
+	payload := bytes.Buffer{}
+
+	writer := gzip.NewWriter(&payload)
+	for _, line := range lines {
+		_, err := writer.Write([]byte(line.(string)))
+		if err != nil {
+			logger.Error("failed-to-compress-payload", err)
+			return err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		logger.Error("failed-to-compress-payload", err)
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = post(emitter.client, emitter.url, http.Header{
+		"Content-Type":     {"text/plain"},
+		"Content-Encoding": {"gzip"},
+	}, payload.Bytes(), 3, retryServerErrors)
+	if err != nil {
+		logger.Error("failed-to-send-metrics",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package emitter
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+)
+
+type WavefrontEmitter struct {
+	writer *tcpWriter
+}
+
+type WavefrontConfig struct {
+	ProxyHost string `long:"wavefront-proxy-host"                 description:"Wavefront proxy address to emit metrics to."`
+	ProxyPort uint16 `long:"wavefront-proxy-port" default:"2878" description:"Port of the Wavefront proxy to emit metrics to."`
+
+	MaxConns int `long:"wavefront-max-conns" default:"1" description:"Number of connections to the Wavefront proxy to write metrics over in turn."`
+}
+
+func init() {
+	metric.RegisterEmitter(&WavefrontConfig{})
+}
+
+func (config *WavefrontConfig) Description() string { return "Wavefront" }
+func (config *WavefrontConfig) FlagPrefix() string  { return "wavefront" }
+func (config *WavefrontConfig) IsConfigured() bool  { return config.ProxyHost != "" }
+
+func (config *WavefrontConfig) NewEmitter() (metric.Emitter, error) {
+	return &WavefrontEmitter{
+		writer: newTCPWriter(net.JoinHostPort(config.ProxyHost, fmt.Sprintf("%d", config.ProxyPort)), config.MaxConns),
+	}, nil
+}
+
+func (emitter *WavefrontEmitter) Emit(logger lager.Logger, event metric.Event) {
+	name := normalizeName(event.Name)
+
+	value, err := getFloatHelper(event.Value)
+	if err != nil {
+		logger.Error("failed-to-convert-metric-for-wavefront", nil, lager.Data{
+			"metric-name": name,
+		})
+		return
+	}
+
+	tags := map[string]string{
+		"state": string(event.State),
+	}
+
+	for k, v := range event.Attributes {
+		tags[k] = v
+	}
+
+	keys := []string{}
+	for k := range tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	line := fmt.Sprintf(
+		"%s %s %d source=%s",
+		name,
+		strconv.FormatFloat(value, 'f', -1, 64),
+		event.Timestamp().Unix(),
+		wavefrontQuote(event.Host),
+	)
+
+	for _, k := range keys {
+		// empty point tag values are rejected by the proxy
+		if tags[k] == "" {
+			continue
+		}
+
+		line += fmt.Sprintf(" %s=%s", specialChars.ReplaceAllString(k, "_"), wavefrontQuote(tags[k]))
+	}
+
+	emitter.writer.Write(logger, line+"\n")
+}
+
+// Close closes the connection to the proxy.
+func (emitter *WavefrontEmitter) Close() error {
+	return emitter.writer.Close()
+}
+
+func wavefrontQuote(value string) string {
+	return `"` + strings.Replace(value, `"`, `\"`, -1) + `"`
+}
@@ -0,0 +1,143 @@
+package emitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/pkg/errors"
+)
+
+type WebhookEmitter struct {
+	client     *http.Client
+	url        string
+	header     http.Header
+	headers    *requestHeaders
+	compressor *compressor
+	proxy      *proxyCheck
+	batcher    *batcher
+}
+
+type WebhookConfig struct {
+	URL     string        `long:"webhook-url" description:"URL to POST metrics to as a JSON array of events."`
+	Timeout time.Duration `long:"webhook-timeout" default:"30s" description:"Timeout for each request to the webhook."`
+
+	Headers HeaderConfig `group:"Webhook Headers" namespace:"webhook"`
+	TLS     TLSConfig    `group:"Webhook TLS" namespace:"webhook"`
+	Proxy   ProxyConfig  `group:"Webhook Proxy" namespace:"webhook"`
+
+	Compress bool `long:"webhook-compress" description:"Gzip-compress request bodies larger than 1KB. Falls back to uncompressed requests if the webhook does not accept them."`
+
+	BatchSize     int           `long:"webhook-batch-size"     default:"100" description:"Number of events to send in a single request."`
+	FlushInterval time.Duration `long:"webhook-flush-interval" default:"10s" description:"Interval on which to flush batched events, regardless of the batch size."`
+}
+
+type webhookEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      string            `json:"state"`
+	Host       string            `json:"host"`
+	Attributes map[string]string `json:"attributes"`
+	Time       int64             `json:"time"`
+}
+
+func init() {
+	metric.RegisterEmitter(&WebhookConfig{})
+}
+
+func (config *WebhookConfig) Description() string { return "Webhook" }
+func (config *WebhookConfig) FlagPrefix() string  { return "webhook" }
+func (config *WebhookConfig) IsConfigured() bool  { return config.URL != "" }
+
+func (config *WebhookConfig) NewEmitter() (metric.Emitter, error) {
+	err := checkBatching("webhook", config.BatchSize, config.FlushInterval)
+	if err != nil {
+		return &WebhookEmitter{}, err
+	}
+
+	headers, err := config.Headers.requestHeaders("webhook")
+	if err != nil {
+		return &WebhookEmitter{}, err
+	}
+
+	tlsConfig, err := config.TLS.TLSClientConfig()
+	if err != nil {
+		return &WebhookEmitter{}, err
+	}
+
+	transport, proxy, err := config.Proxy.transport("webhook", config.URL, tlsConfig)
+	if err != nil {
+		return &WebhookEmitter{}, err
+	}
+
+	emitter := &WebhookEmitter{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+		url: config.URL,
+		header: http.Header{
+			"Content-Type": {"application/json"},
+		},
+		headers:    headers,
+		compressor: newCompressor(config.Compress),
+		proxy:      proxy,
+	}
+
+	emitter.batcher = newBatcher(config.BatchSize, config.FlushInterval, emitter.send)
+
+	return emitter, nil
+}
+
+func (emitter *WebhookEmitter) Emit(logger lager.Logger, event metric.Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+	}
+}
+
+func (emitter *WebhookEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	return emitter.batcher.Add(logger, webhookEvent{
+		Name:       event.Name,
+		Value:      eventValue(event.Value),
+		State:      string(event.State),
+		Host:       event.Host,
+		Attributes: event.Attributes,
+		Time:       event.Timestamp().Unix(),
+	})
+}
+
+// Close flushes any batched events.
+func (emitter *WebhookEmitter) Close() error {
+	emitter.batcher.Close()
+	return nil
+}
+
+func (emitter *WebhookEmitter) send(logger lager.Logger, events []interface{}) error {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		logger.Error("failed-to-serialize-payload", err)
+		return err
+	}
+
+	header, err := emitter.headers.with(logger, emitter.header)
+	if err != nil {
+		logger.Error("failed-to-send-events",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	emitter.proxy.logUnreachable(logger)
+
+	_, err = emitter.compressor.post(logger, emitter.client, emitter.url, header, payload, 3, retryServerErrors)
+	if err != nil {
+		logger.Error("failed-to-send-events",
+			errors.Wrap(metric.ErrFailedToEmit, err.Error()))
+		return err
+	}
+
+	return nil
+}
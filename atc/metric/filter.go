@@ -0,0 +1,118 @@
+package metric
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var nameSpecialChars = regexp.MustCompile("[^a-z0-9_]+")
+
+// FilterEmitter only passes on events whose name is allowed, so that the
+// wrapped emitter never sees the others.
+type FilterEmitter struct {
+	Emitter
+
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// NewFilterEmitter wraps an emitter with a filter matching event names
+// against the given globs. With no allow globs every name is allowed; a name
+// matching any deny glob is never emitted.
+func NewFilterEmitter(emitter Emitter, allow []string, deny []string) (*FilterEmitter, error) {
+	allowPatterns, err := compileGlobs(allow)
+	if err != nil {
+		return nil, err
+	}
+
+	denyPatterns, err := compileGlobs(deny)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilterEmitter{
+		Emitter: emitter,
+
+		allow: allowPatterns,
+		deny:  denyPatterns,
+	}, nil
+}
+
+func (emitter *FilterEmitter) Emit(logger lager.Logger, event Event) {
+	if !emitter.allows(event.Name) {
+		return
+	}
+
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *FilterEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	allowed := make([]Event, 0, len(events))
+	for _, event := range events {
+		if emitter.allows(event.Name) {
+			allowed = append(allowed, event)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return
+	}
+
+	EmitBatch(logger, emitter.Emitter, allowed)
+}
+
+func (emitter *FilterEmitter) allows(name string) bool {
+	name = sanitizeName(name)
+
+	for _, pattern := range emitter.deny {
+		if pattern.MatchString(name) {
+			return false
+		}
+	}
+
+	if len(emitter.allow) == 0 {
+		return true
+	}
+
+	for _, pattern := range emitter.allow {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sanitizeName turns e.g. "build finished" into "build_finished", the form
+// most emitters send names in.
+func sanitizeName(name string) string {
+	return nameSpecialChars.ReplaceAllString(strings.Replace(strings.ToLower(name), " ", "_", -1), "")
+}
+
+// compileGlobs converts globs in which '*' matches any characters and '?'
+// matches a single character into anchored regular expressions.
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, glob := range globs {
+		if glob == "" {
+			return nil, fmt.Errorf("invalid metric glob: must not be empty")
+		}
+
+		expr := regexp.QuoteMeta(glob)
+		expr = strings.Replace(expr, `\*`, ".*", -1)
+		expr = strings.Replace(expr, `\?`, ".", -1)
+
+		pattern, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric glob '%s': %s", glob, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
@@ -0,0 +1,83 @@
+package metric_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FilterEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		allow       []string
+		deny        []string
+
+		filter *metric.FilterEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		allow = nil
+		deny = nil
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		filter, err = metric.NewFilterEmitter(fakeEmitter, allow, deny)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	emitted := func(names ...string) []string {
+		logger := lagertest.NewTestLogger("test")
+		for _, name := range names {
+			filter.Emit(logger, metric.Event{Name: name})
+		}
+
+		var emittedNames []string
+		for i := 0; i < fakeEmitter.EmitCallCount(); i++ {
+			_, event := fakeEmitter.EmitArgsForCall(i)
+			emittedNames = append(emittedNames, event.Name)
+		}
+
+		return emittedNames
+	}
+
+	Context("with no globs", func() {
+		It("emits every event", func() {
+			Expect(emitted("build started", "build finished")).To(Equal([]string{"build started", "build finished"}))
+		})
+	})
+
+	Context("with allow globs", func() {
+		BeforeEach(func() {
+			allow = []string{"build_*", "worker_container?"}
+		})
+
+		It("only emits events with a matching sanitized name", func() {
+			Expect(emitted("build started", "worker containers", "worker volumes")).To(Equal([]string{"build started", "worker containers"}))
+		})
+
+		Context("with deny globs", func() {
+			BeforeEach(func() {
+				deny = []string{"*_started"}
+			})
+
+			It("does not emit denied events, even when allowed", func() {
+				Expect(emitted("build started", "build finished")).To(Equal([]string{"build finished"}))
+			})
+		})
+	})
+
+	It("closes the wrapped emitter", func() {
+		Expect(filter.Close()).To(Succeed())
+		Expect(fakeEmitter.CloseCallCount()).To(Equal(1))
+	})
+
+	It("rejects empty globs", func() {
+		_, err := metric.NewFilterEmitter(fakeEmitter, []string{""}, nil)
+		Expect(err).To(HaveOccurred())
+	})
+})
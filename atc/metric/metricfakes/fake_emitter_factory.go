@@ -18,6 +18,16 @@ type FakeEmitterFactory struct {
 	descriptionReturnsOnCall map[int]struct {
 		result1 string
 	}
+	FlagPrefixStub        func() string
+	flagPrefixMutex       sync.RWMutex
+	flagPrefixArgsForCall []struct {
+	}
+	flagPrefixReturns struct {
+		result1 string
+	}
+	flagPrefixReturnsOnCall map[int]struct {
+		result1 string
+	}
 	IsConfiguredStub        func() bool
 	isConfiguredMutex       sync.RWMutex
 	isConfiguredArgsForCall []struct {
@@ -96,6 +106,58 @@ func (fake *FakeEmitterFactory) DescriptionReturnsOnCall(i int, result1 string)
 	}{result1}
 }
 
+func (fake *FakeEmitterFactory) FlagPrefix() string {
+	fake.flagPrefixMutex.Lock()
+	ret, specificReturn := fake.flagPrefixReturnsOnCall[len(fake.flagPrefixArgsForCall)]
+	fake.flagPrefixArgsForCall = append(fake.flagPrefixArgsForCall, struct {
+	}{})
+	fake.recordInvocation("FlagPrefix", []interface{}{})
+	fake.flagPrefixMutex.Unlock()
+	if fake.FlagPrefixStub != nil {
+		return fake.FlagPrefixStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.flagPrefixReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeEmitterFactory) FlagPrefixCallCount() int {
+	fake.flagPrefixMutex.RLock()
+	defer fake.flagPrefixMutex.RUnlock()
+	return len(fake.flagPrefixArgsForCall)
+}
+
+func (fake *FakeEmitterFactory) FlagPrefixCalls(stub func() string) {
+	fake.flagPrefixMutex.Lock()
+	defer fake.flagPrefixMutex.Unlock()
+	fake.FlagPrefixStub = stub
+}
+
+func (fake *FakeEmitterFactory) FlagPrefixReturns(result1 string) {
+	fake.flagPrefixMutex.Lock()
+	defer fake.flagPrefixMutex.Unlock()
+	fake.FlagPrefixStub = nil
+	fake.flagPrefixReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeEmitterFactory) FlagPrefixReturnsOnCall(i int, result1 string) {
+	fake.flagPrefixMutex.Lock()
+	defer fake.flagPrefixMutex.Unlock()
+	fake.FlagPrefixStub = nil
+	if fake.flagPrefixReturnsOnCall == nil {
+		fake.flagPrefixReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.flagPrefixReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *FakeEmitterFactory) IsConfigured() bool {
 	fake.isConfiguredMutex.Lock()
 	ret, specificReturn := fake.isConfiguredReturnsOnCall[len(fake.isConfiguredArgsForCall)]
@@ -208,6 +270,8 @@ func (fake *FakeEmitterFactory) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.descriptionMutex.RLock()
 	defer fake.descriptionMutex.RUnlock()
+	fake.flagPrefixMutex.RLock()
+	defer fake.flagPrefixMutex.RUnlock()
 	fake.isConfiguredMutex.RLock()
 	defer fake.isConfiguredMutex.RUnlock()
 	fake.newEmitterMutex.RLock()
@@ -2,6 +2,7 @@ package metric
 
 import (
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/concourse/concourse/atc/db/lock"
@@ -22,6 +23,85 @@ var FailedVolumes = Meter(0)
 var ContainersDeleted = Meter(0)
 var VolumesDeleted = Meter(0)
 
+// DroppedEvents counts the events which were dropped because the emitter
+// could not keep up with them.
+var DroppedEvents = Meter(0)
+
+// EmittedEvents counts the events passed on to the emitter.
+var EmittedEvents = Meter(0)
+
+// SuppressedZeros counts the events which were not emitted because their
+// value was zero, with --metric-drop-zeros.
+var SuppressedZeros = Meter(0)
+
+var (
+	emitErrors     = map[string]*Meter{}
+	emitErrorsLock sync.Mutex
+)
+
+// EmitFailed records that an emitter, named by the description of its
+// factory, failed to emit an event.
+func EmitFailed(emitter string) {
+	emitErrorsLock.Lock()
+	defer emitErrorsLock.Unlock()
+
+	meter, found := emitErrors[emitter]
+	if !found {
+		meter = new(Meter)
+		emitErrors[emitter] = meter
+	}
+
+	meter.Inc()
+}
+
+var (
+	rateLimitedEvents     = map[string]*Meter{}
+	rateLimitedEventsLock sync.Mutex
+)
+
+// EventRateLimited records that an event was dropped because an emitter,
+// named by its sanitized description, exceeded its rate limit.
+func EventRateLimited(emitter string) {
+	rateLimitedEventsLock.Lock()
+	defer rateLimitedEventsLock.Unlock()
+
+	meter, found := rateLimitedEvents[emitter]
+	if !found {
+		meter = new(Meter)
+		rateLimitedEvents[emitter] = meter
+	}
+
+	meter.Inc()
+}
+
+// rateLimitedDeltas returns the number of events each emitter dropped due to
+// its rate limit since the last call.
+func rateLimitedDeltas() map[string]int {
+	rateLimitedEventsLock.Lock()
+	defer rateLimitedEventsLock.Unlock()
+
+	deltas := map[string]int{}
+	for emitter, meter := range rateLimitedEvents {
+		deltas[emitter] = meter.Delta()
+	}
+
+	return deltas
+}
+
+// emitErrorDeltas returns the number of failures of each emitter since the
+// last call.
+func emitErrorDeltas() map[string]int {
+	emitErrorsLock.Lock()
+	defer emitErrorsLock.Unlock()
+
+	deltas := map[string]int{}
+	for emitter, meter := range emitErrors {
+		deltas[emitter] = meter.Delta()
+	}
+
+	return deltas
+}
+
 type SchedulingFullDuration struct {
 	PipelineName string
 	Duration     time.Duration
@@ -42,7 +122,7 @@ func (event SchedulingFullDuration) Emit(logger lager.Logger) {
 		logger.Session("full-scheduling-duration"),
 		Event{
 			Name:  "scheduling: full duration (ms)",
-			Value: ms(event.Duration),
+			Value: event.Duration,
 			State: state,
 			Attributes: map[string]string{
 				"pipeline": event.PipelineName,
@@ -71,7 +151,7 @@ func (event SchedulingLoadVersionsDuration) Emit(logger lager.Logger) {
 		logger.Session("loading-versions-duration"),
 		Event{
 			Name:  "scheduling: loading versions duration (ms)",
-			Value: ms(event.Duration),
+			Value: event.Duration,
 			State: state,
 			Attributes: map[string]string{
 				"pipeline": event.PipelineName,
@@ -101,7 +181,7 @@ func (event SchedulingJobDuration) Emit(logger lager.Logger) {
 		logger.Session("job-scheduling-duration"),
 		Event{
 			Name:  "scheduling: job duration (ms)",
-			Value: ms(event.Duration),
+			Value: event.Duration,
 			State: state,
 			Attributes: map[string]string{
 				"pipeline": event.PipelineName,
@@ -124,6 +204,7 @@ func (event WorkerContainers) Emit(logger lager.Logger) {
 			Name:  "worker containers",
 			Value: event.Containers,
 			State: EventStateOK,
+			Unit:  UnitCount,
 			Attributes: map[string]string{
 				"worker":   event.WorkerName,
 				"platform": event.Platform,
@@ -145,6 +226,7 @@ func (event WorkerVolumes) Emit(logger lager.Logger) {
 			Name:  "worker volumes",
 			Value: event.Volumes,
 			State: EventStateOK,
+			Unit:  UnitCount,
 			Attributes: map[string]string{
 				"worker":   event.WorkerName,
 				"platform": event.Platform,
@@ -164,6 +246,7 @@ func (event VolumesToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "orphaned volumes to be garbage collected",
 			Value:      event.Volumes,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -180,6 +263,7 @@ func (event CreatingContainersToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "creating containers to be garbage collected",
 			Value:      event.Containers,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -196,6 +280,7 @@ func (event CreatedContainersToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "created containers to be garbage collected",
 			Value:      event.Containers,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -212,6 +297,7 @@ func (event DestroyingContainersToBeGarbageCollected) Emit(logger lager.Logger)
 			Name:       "destroying containers to be garbage collected",
 			Value:      event.Containers,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -228,6 +314,7 @@ func (event FailedContainersToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "failed containers to be garbage collected",
 			Value:      event.Containers,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -244,6 +331,7 @@ func (event CreatedVolumesToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "created volumes to be garbage collected",
 			Value:      event.Volumes,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -260,6 +348,7 @@ func (event DestroyingVolumesToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "destroying volumes to be garbage collected",
 			Value:      event.Volumes,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -276,6 +365,7 @@ func (event FailedVolumesToBeGarbageCollected) Emit(logger lager.Logger) {
 			Name:       "failed volumes to be garbage collected",
 			Value:      event.Volumes,
 			State:      EventStateOK,
+			Unit:       UnitCount,
 			Attributes: map[string]string{},
 		},
 	)
@@ -292,6 +382,8 @@ func (event GarbageCollectionContainerCollectorJobDropped) Emit(logger lager.Log
 			Name:  "GC container collector job dropped",
 			Value: 1,
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 			Attributes: map[string]string{
 				"worker": event.WorkerName,
 			},
@@ -307,6 +399,8 @@ type BuildStarted struct {
 	TeamName     string
 }
 
+// Emit emits the ID of the build as "build started", and counts it in
+// "builds started".
 func (event BuildStarted) Emit(logger lager.Logger) {
 	emit(
 		logger.Session("build-started"),
@@ -323,6 +417,22 @@ func (event BuildStarted) Emit(logger lager.Logger) {
 			},
 		},
 	)
+
+	emit(
+		logger.Session("builds-started"),
+		Event{
+			Name:  "builds started",
+			Value: 1,
+			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
+			Attributes: map[string]string{
+				"pipeline":  event.PipelineName,
+				"job":       event.JobName,
+				"team_name": event.TeamName,
+			},
+		},
+	)
 }
 
 type BuildFinished struct {
@@ -340,7 +450,7 @@ func (event BuildFinished) Emit(logger lager.Logger) {
 		logger.Session("build-finished"),
 		Event{
 			Name:  "build finished",
-			Value: ms(event.BuildDuration),
+			Value: event.BuildDuration,
 			State: EventStateOK,
 			Attributes: map[string]string{
 				"pipeline":     event.PipelineName,
@@ -354,10 +464,6 @@ func (event BuildFinished) Emit(logger lager.Logger) {
 	)
 }
 
-func ms(duration time.Duration) float64 {
-	return float64(duration) / 1000000
-}
-
 type ErrorLog struct {
 	Message string
 	Value   int
@@ -370,6 +476,8 @@ func (e ErrorLog) Emit(logger lager.Logger) {
 			Name:  "error log",
 			Value: e.Value,
 			State: EventStateWarning,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 			Attributes: map[string]string{
 				"message": e.Message,
 			},
@@ -400,7 +508,7 @@ func (event HTTPResponseTime) Emit(logger lager.Logger) {
 		logger.Session("http-response-time"),
 		Event{
 			Name:  "http response time",
-			Value: ms(event.Duration),
+			Value: event.Duration,
 			State: state,
 			Attributes: map[string]string{
 				"route":  event.Route,
@@ -430,6 +538,8 @@ func (event ResourceCheck) Emit(logger lager.Logger) {
 			Name:  "resource checked",
 			Value: 1,
 			State: state,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 			Attributes: map[string]string{
 				"pipeline": event.PipelineName,
 				"resource": event.ResourceName,
@@ -542,6 +652,7 @@ func (event WorkersState) Emit(logger lager.Logger) {
 				Name:  "worker state",
 				Value: count,
 				State: eventState,
+				Unit:  UnitCount,
 				Attributes: map[string]string{
 					"state": string(state),
 				},
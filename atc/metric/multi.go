@@ -0,0 +1,83 @@
+package metric
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/hashicorp/go-multierror"
+)
+
+// MultiEmitter emits each event to all of its emitters. A panic in one of them
+// is logged rather than keeping the event from reaching the others.
+type MultiEmitter struct {
+	emitters []Emitter
+}
+
+func NewMultiEmitter(emitters ...Emitter) *MultiEmitter {
+	return &MultiEmitter{
+		emitters: emitters,
+	}
+}
+
+func (emitter *MultiEmitter) Emit(logger lager.Logger, event Event) {
+	for i, child := range emitter.emitters {
+		emitter.emitTo(logger.Session(fmt.Sprintf("emitter-%d", i)), child, event)
+	}
+}
+
+func (emitter *MultiEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	for i, child := range emitter.emitters {
+		emitter.emitBatchTo(logger.Session(fmt.Sprintf("emitter-%d", i)), child, events)
+	}
+}
+
+func (emitter *MultiEmitter) emitTo(logger lager.Logger, child Emitter, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("emitter-panicked", fmt.Errorf("%v", r), lager.Data{
+				"metric-name": event.Name,
+			})
+		}
+	}()
+
+	child.Emit(logger, event)
+}
+
+func (emitter *MultiEmitter) emitBatchTo(logger lager.Logger, child Emitter, events []Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("emitter-panicked", fmt.Errorf("%v", r), lager.Data{
+				"events": len(events),
+			})
+		}
+	}()
+
+	EmitBatch(logger, child, events)
+}
+
+// Close closes all of the emitters at once, returning all of their errors.
+func (emitter *MultiEmitter) Close() error {
+	errs := make([]error, len(emitter.emitters))
+
+	wg := new(sync.WaitGroup)
+	for i, child := range emitter.emitters {
+		wg.Add(1)
+
+		go func(i int, child Emitter) {
+			defer wg.Done()
+			errs[i] = child.Close()
+		}(i, child)
+	}
+
+	wg.Wait()
+
+	var result error
+	for _, err := range errs {
+		if err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,78 @@
+package metric_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MultiEmitter", func() {
+	var (
+		first  *metricfakes.FakeEmitter
+		second *metricfakes.FakeEmitter
+
+		multi *metric.MultiEmitter
+	)
+
+	BeforeEach(func() {
+		first = &metricfakes.FakeEmitter{}
+		second = &metricfakes.FakeEmitter{}
+
+		multi = metric.NewMultiEmitter(first, second)
+	})
+
+	It("emits events to every emitter", func() {
+		multi.Emit(lagertest.NewTestLogger("test"), metric.Event{Name: "build started"})
+
+		Expect(first.EmitCallCount()).To(Equal(1))
+		_, event := first.EmitArgsForCall(0)
+		Expect(event.Name).To(Equal("build started"))
+
+		Expect(second.EmitCallCount()).To(Equal(1))
+		_, event = second.EmitArgsForCall(0)
+		Expect(event.Name).To(Equal("build started"))
+	})
+
+	Context("when an emitter panics", func() {
+		BeforeEach(func() {
+			first.EmitStub = func(lager.Logger, metric.Event) {
+				panic("boom")
+			}
+		})
+
+		It("still emits to the others", func() {
+			logger := lagertest.NewTestLogger("test")
+			multi.Emit(logger, metric.Event{Name: "build started"})
+
+			Expect(second.EmitCallCount()).To(Equal(1))
+			Expect(logger.LogMessages()).To(ContainElement("test.emitter-0.emitter-panicked"))
+		})
+	})
+
+	Describe("Close", func() {
+		It("closes every emitter", func() {
+			Expect(multi.Close()).To(Succeed())
+			Expect(first.CloseCallCount()).To(Equal(1))
+			Expect(second.CloseCallCount()).To(Equal(1))
+		})
+
+		Context("when emitters fail to close", func() {
+			BeforeEach(func() {
+				first.CloseReturns(errors.New("first"))
+				second.CloseReturns(errors.New("second"))
+			})
+
+			It("returns all of the errors", func() {
+				err := multi.Close()
+				Expect(err).To(MatchError(ContainSubstring("first")))
+				Expect(err).To(MatchError(ContainSubstring("second")))
+			})
+		})
+	})
+})
@@ -0,0 +1,242 @@
+package metric
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// OpenMetricsContentType is the content type of the OpenMetrics text format.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// OpenMetrics is the snapshot served by the GetMetrics route. It is updated
+// while the OpenMetrics emitter is configured.
+var OpenMetrics = NewOpenMetricsHandler()
+
+// OpenMetricsHandler is an emitter which keeps the last value of each series,
+// i.e. of each metric for each host and set of attributes, and serves them in
+// the OpenMetrics text format. Counters are summed into totals rather than
+// replaced.
+//
+// The state of events is not a label, so that a series does not split when
+// its state changes. Neither are attributes which differ for every build or
+// error, which would otherwise add a series that is kept forever each time.
+type OpenMetricsHandler struct {
+	NopCloser
+
+	series     map[string]*openMetricsSeries
+	seriesLock sync.RWMutex
+
+	scrapeToken     string
+	scrapeTokenLock sync.RWMutex
+}
+
+type openMetricsSeries struct {
+	name      string
+	labels    string
+	eventType EventType
+	unit      string
+	value     float64
+}
+
+func NewOpenMetricsHandler() *OpenMetricsHandler {
+	return &OpenMetricsHandler{
+		series: map[string]*openMetricsSeries{},
+	}
+}
+
+func (handler *OpenMetricsHandler) Emit(logger lager.Logger, event Event) {
+	value, ok := timerValue(event.Value)
+	if !ok {
+		logger.Error("failed-to-convert-metric-for-openmetrics", nil, lager.Data{
+			"metric-name": event.Name,
+		})
+		return
+	}
+
+	labels := openMetricsLabels(event)
+	key := event.Name + "\x00" + labels
+
+	handler.seriesLock.Lock()
+	defer handler.seriesLock.Unlock()
+
+	series, found := handler.series[key]
+	if !found {
+		series = &openMetricsSeries{
+			name:   event.Name,
+			labels: labels,
+		}
+
+		handler.series[key] = series
+	}
+
+	if event.Type == EventTypeCounter && series.eventType == EventTypeCounter {
+		series.value += value
+	} else {
+		series.value = value
+	}
+
+	series.eventType = event.Type
+	series.unit = event.Unit
+}
+
+// SetScrapeToken sets the bearer token which scrapers must present. If it is
+// empty, only admins may scrape.
+func (handler *OpenMetricsHandler) SetScrapeToken(token string) {
+	handler.scrapeTokenLock.Lock()
+	handler.scrapeToken = token
+	handler.scrapeTokenLock.Unlock()
+}
+
+// Authorized reports whether the request may scrape the metrics without being
+// an admin, i.e. whether it presents the scrape token. No request does if no
+// token is set.
+func (handler *OpenMetricsHandler) Authorized(r *http.Request) bool {
+	handler.scrapeTokenLock.RLock()
+	token := handler.scrapeToken
+	handler.scrapeTokenLock.RUnlock()
+
+	if token == "" {
+		return false
+	}
+
+	given := r.Header.Get("Authorization")
+	if !strings.HasPrefix(given, "Bearer ") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(given, "Bearer ")), []byte(token)) == 1
+}
+
+func (handler *OpenMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", OpenMetricsContentType)
+	_, _ = w.Write(handler.render())
+}
+
+// render writes the series in the OpenMetrics text format, grouped by metric
+// and sorted by name and labels.
+func (handler *OpenMetricsHandler) render() []byte {
+	handler.seriesLock.RLock()
+
+	families := map[string][]openMetricsSeries{}
+	for _, series := range handler.series {
+		families[series.name] = append(families[series.name], *series)
+	}
+
+	handler.seriesLock.RUnlock()
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	for _, name := range names {
+		series := families[name]
+
+		sort.Slice(series, func(i, j int) bool {
+			return series[i].labels < series[j].labels
+		})
+
+		metadata := Lookup(name)
+
+		unit := series[0].unit
+		if unit == UnitCount {
+			unit = ""
+		}
+
+		family := "concourse_" + sanitizeName(name)
+		if unit != "" && !strings.HasSuffix(family, "_"+unit) {
+			family += "_" + unit
+		}
+
+		familyType := "gauge"
+		sample := family
+		if series[0].eventType == EventTypeCounter {
+			familyType = "counter"
+			sample += "_total"
+		}
+
+		fmt.Fprintf(buf, "# TYPE %s %s\n", family, familyType)
+
+		if unit != "" {
+			fmt.Fprintf(buf, "# UNIT %s %s\n", family, unit)
+		}
+
+		if metadata.Help != "" {
+			fmt.Fprintf(buf, "# HELP %s %s\n", family, openMetricsEscaper.Replace(metadata.Help))
+		}
+
+		for _, s := range series {
+			// a metric whose type changed cannot be part of the family
+			if (s.eventType == EventTypeCounter) != (familyType == "counter") {
+				continue
+			}
+
+			fmt.Fprintf(buf, "%s%s %s\n", sample, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+
+	buf.WriteString("# EOF\n")
+
+	return buf.Bytes()
+}
+
+// labelSpecialChars matches the characters which may not be part of a label
+// name.
+var labelSpecialChars = regexp.MustCompile("[^a-zA-Z0-9_]")
+
+// openMetricsUnboundedAttributes are the attributes which are not rendered as
+// labels as they take a new value for every build or error.
+var openMetricsUnboundedAttributes = map[string]bool{
+	"build_id":   true,
+	"build_name": true,
+	"message":    true,
+}
+
+var openMetricsEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// openMetricsLabels renders the host and attributes of the event as a sorted
+// label set, e.g. {host="web",team="main"}.
+func openMetricsLabels(event Event) string {
+	values := map[string]string{}
+	for k, v := range event.Attributes {
+		if openMetricsUnboundedAttributes[k] {
+			continue
+		}
+
+		values[labelSpecialChars.ReplaceAllString(k, "_")] = v
+	}
+
+	if event.Host != "" {
+		values["host"] = event.Host
+	}
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, k, openMetricsEscaper.Replace(values[k]))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,167 @@
+package metric_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpenMetricsHandler", func() {
+	var (
+		handler *metric.OpenMetricsHandler
+		logger  *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		handler = metric.NewOpenMetricsHandler()
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	render := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/api/v1/metrics", nil))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal(metric.OpenMetricsContentType))
+
+		body, err := ioutil.ReadAll(recorder.Body)
+		Expect(err).ToNot(HaveOccurred())
+
+		return string(body)
+	}
+
+	It("renders the last value of each series with its metadata", func() {
+		handler.Emit(logger, metric.Event{
+			Name:       "build finished",
+			Value:      2 * time.Second,
+			Type:       metric.EventTypeTimer,
+			Unit:       metric.UnitMilliseconds,
+			Host:       "web",
+			Attributes: map[string]string{"team_name": "main"},
+		})
+
+		handler.Emit(logger, metric.Event{
+			Name:       "build finished",
+			Value:      3 * time.Second,
+			Type:       metric.EventTypeTimer,
+			Unit:       metric.UnitMilliseconds,
+			Host:       "web",
+			Attributes: map[string]string{"team_name": "main"},
+		})
+
+		handler.Emit(logger, metric.Event{
+			Name:       "worker containers",
+			Value:      5,
+			Type:       metric.EventTypeGauge,
+			Unit:       metric.UnitCount,
+			Host:       "web",
+			Attributes: map[string]string{"worker": "w\"1"},
+		})
+
+		Expect(render()).To(Equal(`# TYPE concourse_build_finished_ms gauge
+# UNIT concourse_build_finished_ms ms
+# HELP concourse_build_finished_ms Duration of a finished build.
+concourse_build_finished_ms{host="web",team_name="main"} 3000
+# TYPE concourse_worker_containers gauge
+# HELP concourse_worker_containers Number of containers on a worker.
+concourse_worker_containers{host="web",worker="w\"1"} 5
+# EOF
+`))
+	})
+
+	Describe("Authorized", func() {
+		var request *http.Request
+
+		BeforeEach(func() {
+			request = httptest.NewRequest("GET", "/api/v1/metrics", nil)
+		})
+
+		It("lets no one scrape without being an admin when no token is set", func() {
+			Expect(handler.Authorized(request)).To(BeFalse())
+
+			request.Header.Set("Authorization", "Bearer ")
+			Expect(handler.Authorized(request)).To(BeFalse())
+		})
+
+		Context("when a token is set", func() {
+			BeforeEach(func() {
+				handler.SetScrapeToken("some-token")
+			})
+
+			It("requires it as a bearer token", func() {
+				Expect(handler.Authorized(request)).To(BeFalse())
+
+				request.Header.Set("Authorization", "some-token")
+				Expect(handler.Authorized(request)).To(BeFalse())
+
+				request.Header.Set("Authorization", "Bearer other-token")
+				Expect(handler.Authorized(request)).To(BeFalse())
+
+				request.Header.Set("Authorization", "Bearer some-token")
+				Expect(handler.Authorized(request)).To(BeTrue())
+			})
+		})
+	})
+
+	It("sums counters into totals", func() {
+		for i := 0; i < 3; i++ {
+			handler.Emit(logger, metric.Event{
+				Name:  "unregistered events",
+				Value: 2,
+				Type:  metric.EventTypeCounter,
+				Unit:  metric.UnitCount,
+			})
+		}
+
+		Expect(render()).To(Equal(`# TYPE concourse_unregistered_events counter
+concourse_unregistered_events_total 6
+# EOF
+`))
+	})
+
+	It("keeps a bounded number of series across builds and errors", func() {
+		for i := 0; i < 100; i++ {
+			handler.Emit(logger, metric.Event{
+				Name:  "error log",
+				Value: 1,
+				Type:  metric.EventTypeCounter,
+				Unit:  metric.UnitCount,
+				Host:  "web",
+				Attributes: map[string]string{
+					"message": "error " + strconv.Itoa(i),
+				},
+			})
+
+			handler.Emit(logger, metric.Event{
+				Name:  "build finished",
+				Value: time.Second,
+				Type:  metric.EventTypeTimer,
+				Unit:  metric.UnitMilliseconds,
+				Host:  "web",
+				Attributes: map[string]string{
+					"team_name":  "main",
+					"build_name": strconv.Itoa(i),
+					"build_id":   strconv.Itoa(i),
+				},
+			})
+		}
+
+		Expect(render()).To(Equal(`# TYPE concourse_build_finished_ms gauge
+# UNIT concourse_build_finished_ms ms
+# HELP concourse_build_finished_ms Duration of a finished build.
+concourse_build_finished_ms{host="web",team_name="main"} 1000
+# TYPE concourse_error_log counter
+# HELP concourse_error_log Number of errors logged.
+concourse_error_log_total{host="web"} 100
+# EOF
+`))
+	})
+})
@@ -0,0 +1,136 @@
+package metric
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistogramEmitter can be implemented by emitters which keep the distribution
+// of timers themselves, e.g. as histograms, so that the percentiles of their
+// timers are not computed for them.
+type HistogramEmitter interface {
+	Emitter
+
+	EmitsHistograms() bool
+}
+
+// emitsHistograms returns whether the emitter keeps the distribution of timers
+// itself.
+func emitsHistograms(emitter Emitter) bool {
+	histograms, ok := emitter.(HistogramEmitter)
+	return ok && histograms.EmitsHistograms()
+}
+
+// PercentileEmitter computes percentiles of timers for backends which have no
+// notion of a distribution, e.g. plain StatsD or Graphite. Timers are buffered
+// per name, host and attributes, and at the end of each window replaced by
+// gauges of the chosen percentiles and the maximum, named e.g.
+// "build duration p95" and "build duration max".
+type PercentileEmitter struct {
+	*windowedEmitter
+}
+
+// NewPercentileEmitter wraps an emitter, emitting the percentiles of the
+// timers seen every window. Percentiles must be greater than 0 and at most
+// 100.
+func NewPercentileEmitter(emitter Emitter, percentiles []float64, window time.Duration) (*PercentileEmitter, error) {
+	for _, percentile := range percentiles {
+		if percentile <= 0 || percentile > 100 {
+			return nil, fmt.Errorf("invalid metric percentile '%v': must be greater than 0 and at most 100", percentile)
+		}
+	}
+
+	return &PercentileEmitter{
+		windowedEmitter: newWindowedEmitter(emitter, percentileBuffer{percentiles: percentiles}, window),
+	}, nil
+}
+
+type percentileBuffer struct {
+	percentiles []float64
+}
+
+type timerSamples struct {
+	event  Event
+	values []float64
+}
+
+func (percentileBuffer) holds(event Event) bool {
+	if event.Type != EventTypeTimer {
+		return false
+	}
+
+	_, ok := timerValue(event.Value)
+	return ok
+}
+
+func (percentileBuffer) merge(entry interface{}, event Event) interface{} {
+	samples, found := entry.(*timerSamples)
+	if !found {
+		samples = &timerSamples{}
+	}
+
+	value, _ := timerValue(event.Value)
+
+	samples.event = event
+	samples.values = append(samples.values, value)
+
+	return samples
+}
+
+// flush replaces the samples of each timer with gauges of the percentiles
+// and the maximum.
+func (buffer percentileBuffer) flush(entries []interface{}) []Event {
+	events := []Event{}
+	for _, entry := range entries {
+		samples := entry.(*timerSamples)
+
+		sort.Float64s(samples.values)
+
+		for _, percentile := range buffer.percentiles {
+			events = append(events, percentileEvent(samples.event, percentileName(percentile), nearestRank(samples.values, percentile)))
+		}
+
+		events = append(events, percentileEvent(samples.event, "max", samples.values[len(samples.values)-1]))
+	}
+
+	return events
+}
+
+func percentileEvent(event Event, suffix string, value float64) Event {
+	event.Name = event.Name + " " + suffix
+	event.Type = EventTypeGauge
+	event.Unit = UnitMilliseconds
+	event.Value = value
+
+	return event
+}
+
+// percentileName returns the suffix of a percentile's gauge, e.g. "p95" or
+// "p99_9".
+func percentileName(percentile float64) string {
+	return "p" + strings.Replace(strconv.FormatFloat(percentile, 'f', -1, 64), ".", "_", -1)
+}
+
+// nearestRank returns the smallest of the sorted values which at least the
+// percentile of them are less than or equal to.
+func nearestRank(sorted []float64, percentile float64) float64 {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// timerValue returns a timer's value in milliseconds.
+func timerValue(value interface{}) (float64, bool) {
+	if duration, ok := value.(time.Duration); ok {
+		return float64(duration) / float64(time.Millisecond), true
+	}
+
+	return floatValue(value)
+}
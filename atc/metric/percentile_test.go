@@ -0,0 +1,101 @@
+package metric_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PercentileEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		logger      *lagertest.TestLogger
+		percentiles *metric.PercentileEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+
+		var err error
+		percentiles, err = metric.NewPercentileEmitter(fakeEmitter, []float64{50, 90, 99.9}, time.Hour)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	emitted := func() []metric.Event {
+		events := []metric.Event{}
+		for i := 0; i < fakeEmitter.EmitCallCount(); i++ {
+			_, event := fakeEmitter.EmitArgsForCall(i)
+			events = append(events, event)
+		}
+
+		return events
+	}
+
+	It("emits the percentiles of each timer once the window ends", func() {
+		for i := 1; i <= 10; i++ {
+			percentiles.Emit(logger, metric.Event{
+				Name:       "build duration",
+				Type:       metric.EventTypeTimer,
+				Unit:       metric.UnitMilliseconds,
+				Value:      time.Duration(i) * time.Second,
+				Attributes: map[string]string{"team": "main"},
+			})
+		}
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(0))
+
+		Expect(percentiles.Close()).To(Succeed())
+
+		gauge := func(name string, value float64) metric.Event {
+			return metric.Event{
+				Name:       name,
+				Type:       metric.EventTypeGauge,
+				Unit:       metric.UnitMilliseconds,
+				Value:      value,
+				Attributes: map[string]string{"team": "main"},
+			}
+		}
+
+		Expect(emitted()).To(Equal([]metric.Event{
+			gauge("build duration p50", 5000),
+			gauge("build duration p90", 9000),
+			gauge("build duration p99_9", 10000),
+			gauge("build duration max", 10000),
+		}))
+	})
+
+	It("computes the percentiles of each series separately", func() {
+		percentiles.EmitBatch(logger, []metric.Event{
+			{Name: "step duration", Type: metric.EventTypeTimer, Value: 10, Attributes: map[string]string{"step": "get"}},
+			{Name: "step duration", Type: metric.EventTypeTimer, Value: 20, Attributes: map[string]string{"step": "put"}},
+		})
+
+		Expect(percentiles.Close()).To(Succeed())
+
+		events := emitted()
+		Expect(events).To(HaveLen(8))
+		Expect(events[3].Name).To(Equal("step duration max"))
+		Expect(events[3].Value).To(Equal(10.0))
+		Expect(events[7].Value).To(Equal(20.0))
+	})
+
+	It("passes on other events as they come", func() {
+		percentiles.Emit(logger, metric.Event{Name: "containers", Type: metric.EventTypeGauge, Value: 1})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(1))
+	})
+
+	It("rejects invalid percentiles", func() {
+		_, err := metric.NewPercentileEmitter(fakeEmitter, []float64{0}, time.Hour)
+		Expect(err).To(HaveOccurred())
+
+		_, err = metric.NewPercentileEmitter(fakeEmitter, []float64{101}, time.Hour)
+		Expect(err).To(HaveOccurred())
+	})
+})
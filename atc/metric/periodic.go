@@ -28,12 +28,20 @@ func PeriodicallyEmit(logger lager.Logger, interval time.Duration) ifrit.Runner
 }
 
 func tick(logger lager.Logger) {
+	if suppressed := SuppressedZeros.Delta(); suppressed > 0 {
+		logger.Info("suppressed-zero-metrics", lager.Data{
+			"count": suppressed,
+		})
+	}
+
 	emit(
 		logger.Session("database-queries"),
 		Event{
 			Name:  "database queries",
 			Value: DatabaseQueries.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -45,6 +53,30 @@ func tick(logger lager.Logger) {
 					Name:  "database connections",
 					Value: database.Stats().OpenConnections,
 					State: EventStateOK,
+					Unit:  UnitCount,
+					Attributes: map[string]string{
+						"ConnectionName": database.Name(),
+					},
+				},
+			)
+
+			state := EventStateOK
+			value := 1
+
+			err := database.Ping()
+			if err != nil {
+				logger.Error("failed-to-ping-database", err)
+				state = EventStateCritical
+				value = 0
+			}
+
+			emit(
+				logger.Session("database-connectivity"),
+				Event{
+					Name:  "database connectivity",
+					Value: value,
+					State: state,
+					Type:  EventTypeServiceCheck,
 					Attributes: map[string]string{
 						"ConnectionName": database.Name(),
 					},
@@ -59,6 +91,8 @@ func tick(logger lager.Logger) {
 			Name:  "containers deleted",
 			Value: ContainersDeleted.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -68,6 +102,8 @@ func tick(logger lager.Logger) {
 			Name:  "volumes deleted",
 			Value: VolumesDeleted.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -77,6 +113,8 @@ func tick(logger lager.Logger) {
 			Name:  "containers created",
 			Value: ContainersCreated.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -86,6 +124,8 @@ func tick(logger lager.Logger) {
 			Name:  "volumes created",
 			Value: VolumesCreated.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -95,6 +135,8 @@ func tick(logger lager.Logger) {
 			Name:  "failed containers",
 			Value: FailedContainers.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -104,9 +146,90 @@ func tick(logger lager.Logger) {
 			Name:  "failed volumes",
 			Value: FailedVolumes.Delta(),
 			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
+		},
+	)
+
+	emit(
+		logger.Session("dropped-events"),
+		Event{
+			Name:  "dropped events",
+			Value: DroppedEvents.Delta(),
+			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
+		},
+	)
+
+	emit(
+		logger.Session("emitted-events"),
+		Event{
+			Name:  "emitted events",
+			Value: EmittedEvents.Delta(),
+			State: EventStateOK,
+			Type:  EventTypeCounter,
+			Unit:  UnitCount,
+		},
+	)
+
+	emit(
+		logger.Session("emission-queue-depth"),
+		Event{
+			Name:  "emission queue depth",
+			Value: queueDepth(),
+			State: EventStateOK,
+			Unit:  UnitCount,
 		},
 	)
 
+	for emitter, errors := range emitErrorDeltas() {
+		emit(
+			logger.Session("emit-errors"),
+			Event{
+				Name:  "emit errors",
+				Value: errors,
+				State: EventStateOK,
+				Type:  EventTypeCounter,
+				Unit:  UnitCount,
+				Attributes: map[string]string{
+					"emitter": emitter,
+				},
+			},
+		)
+	}
+
+	for emitter, dropped := range rateLimitedDeltas() {
+		emit(
+			logger.Session("rate-limited-events"),
+			Event{
+				Name:  "rate limited events",
+				Value: dropped,
+				State: EventStateOK,
+				Type:  EventTypeCounter,
+				Unit:  UnitCount,
+				Attributes: map[string]string{
+					"emitter": emitter,
+				},
+			},
+		)
+	}
+
+	for emitter, state := range circuitBreakerStates() {
+		emit(
+			logger.Session("emitter-circuit-breaker-state"),
+			Event{
+				Name:  "emitter circuit breaker state",
+				Value: int(state),
+				State: EventStateOK,
+				Attributes: map[string]string{
+					"emitter": emitter,
+					"circuit": state.String(),
+				},
+			},
+		)
+	}
+
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 
@@ -116,6 +239,7 @@ func tick(logger lager.Logger) {
 			Name:  "gc pause total duration",
 			Value: int(memStats.PauseTotalNs),
 			State: EventStateOK,
+			Unit:  UnitNanoseconds,
 		},
 	)
 
@@ -125,6 +249,7 @@ func tick(logger lager.Logger) {
 			Name:  "mallocs",
 			Value: int(memStats.Mallocs),
 			State: EventStateOK,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -134,6 +259,7 @@ func tick(logger lager.Logger) {
 			Name:  "frees",
 			Value: int(memStats.Frees),
 			State: EventStateOK,
+			Unit:  UnitCount,
 		},
 	)
 
@@ -143,6 +269,7 @@ func tick(logger lager.Logger) {
 			Name:  "goroutines",
 			Value: int(runtime.NumGoroutine()),
 			State: EventStateOK,
+			Unit:  UnitCount,
 		},
 	)
 }
@@ -35,7 +35,7 @@ var _ = Describe("Periodic emission of metrics", func() {
 		b := &dbfakes.FakeConn{}
 		b.NameReturns("B")
 		metric.Databases = []db.Conn{a, b}
-		metric.Initialize(nil, "test", map[string]string{})
+		metric.Initialize(nil, "test", map[string]string{}, metric.Config{})
 
 		process = ifrit.Invoke(metric.PeriodicallyEmit(lager.NewLogger("dont care"), 250*time.Millisecond))
 	})
@@ -46,6 +46,41 @@ var _ = Describe("Periodic emission of metrics", func() {
 		metric.Deinitialize(nil)
 	})
 
+	It("closes the emitter when deinitialized", func() {
+		metric.Deinitialize(nil)
+		Expect(emitter.CloseCallCount()).To(Equal(1))
+	})
+
+	It("emits errors of each emitter", func() {
+		metric.EmitFailed("Fake")
+
+		Eventually(func() []interface{} {
+			var events []interface{}
+			for i := 0; i < emitter.EmitCallCount(); i++ {
+				_, event := emitter.EmitArgsForCall(i)
+				events = append(events, event)
+			}
+			return events
+		}).Should(ContainElement(
+			MatchFields(IgnoreExtras, Fields{
+				"Name":       Equal("emit errors"),
+				"Value":      Equal(1),
+				"Type":       Equal(metric.EventTypeCounter),
+				"Attributes": Equal(map[string]string{"emitter": "Fake"}),
+			}),
+		))
+
+		Expect(emitter.Invocations()["Emit"]).To(
+			ContainElement(
+				ContainElement(
+					MatchFields(IgnoreExtras, Fields{
+						"Name": Equal("emission queue depth"),
+					}),
+				),
+			),
+		)
+	})
+
 	It("emits database queries", func() {
 		Eventually(emitter.EmitCallCount).Should(BeNumerically(">=", 1))
 		Expect(emitter.Invocations()["Emit"]).To(
@@ -53,6 +88,8 @@ var _ = Describe("Periodic emission of metrics", func() {
 				ContainElement(
 					MatchFields(IgnoreExtras, Fields{
 						"Name": Equal("database queries"),
+						"Type": Equal(metric.EventTypeCounter),
+						"Unit": Equal(metric.UnitCount),
 					}),
 				),
 			),
@@ -64,6 +101,7 @@ var _ = Describe("Periodic emission of metrics", func() {
 				ContainElement(
 					MatchFields(IgnoreExtras, Fields{
 						"Name":       Equal("database connections"),
+						"Type":       Equal(metric.EventTypeGauge),
 						"Attributes": Equal(map[string]string{"ConnectionName": "A"}),
 					}),
 				),
@@ -79,5 +117,19 @@ var _ = Describe("Periodic emission of metrics", func() {
 				),
 			),
 		)
+
+		By("emits database connectivity for each pool")
+		Expect(emitter.Invocations()["Emit"]).To(
+			ContainElement(
+				ContainElement(
+					MatchFields(IgnoreExtras, Fields{
+						"Name":       Equal("database connectivity"),
+						"State":      Equal(metric.EventStateOK),
+						"Type":       Equal(metric.EventTypeServiceCheck),
+						"Attributes": Equal(map[string]string{"ConnectionName": "A"}),
+					}),
+				),
+			),
+		)
 	})
 })
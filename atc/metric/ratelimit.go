@@ -0,0 +1,61 @@
+package metric
+
+import (
+	"math"
+
+	"code.cloudfoundry.org/lager"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitFlags are wired for each emitter, namespaced by its flag prefix,
+// e.g. --datadog-max-per-second.
+type rateLimitFlags struct {
+	MaxPerSecond float64 `long:"max-per-second" description:"Maximum number of events to emit per second. Events over the limit are dropped. 0 means no limit."`
+}
+
+// emitterRateLimits holds the rate limit flags wired for each factory.
+var emitterRateLimits = map[EmitterFactory]*rateLimitFlags{}
+
+// RateLimitedEmitter drops events beyond a maximum rate, allowing bursts of up
+// to a second's worth of events.
+type RateLimitedEmitter struct {
+	Emitter
+
+	name    string
+	limiter *rate.Limiter
+}
+
+func NewRateLimitedEmitter(emitter Emitter, name string, maxPerSecond float64) *RateLimitedEmitter {
+	return &RateLimitedEmitter{
+		Emitter: emitter,
+
+		name:    name,
+		limiter: rate.NewLimiter(rate.Limit(maxPerSecond), int(math.Max(1, math.Ceil(maxPerSecond)))),
+	}
+}
+
+func (emitter *RateLimitedEmitter) Emit(logger lager.Logger, event Event) {
+	if !emitter.limiter.Allow() {
+		EventRateLimited(emitter.name)
+		return
+	}
+
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *RateLimitedEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	allowed := make([]Event, 0, len(events))
+	for _, event := range events {
+		if emitter.limiter.Allow() {
+			allowed = append(allowed, event)
+		} else {
+			EventRateLimited(emitter.name)
+		}
+	}
+
+	if len(allowed) == 0 {
+		return
+	}
+
+	EmitBatch(logger, emitter.Emitter, allowed)
+}
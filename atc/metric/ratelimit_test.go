@@ -0,0 +1,88 @@
+package metric_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+	flags "github.com/jessevdk/go-flags"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimitedEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		logger      *lagertest.TestLogger
+		limited     *metric.RateLimitedEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+		limited = metric.NewRateLimitedEmitter(fakeEmitter, "test", 2)
+	})
+
+	It("drops events over the limit", func() {
+		for i := 0; i < 5; i++ {
+			limited.Emit(logger, metric.Event{Name: "build started"})
+		}
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(2))
+	})
+
+	It("drops events over the limit from batches", func() {
+		limited.EmitBatch(logger, []metric.Event{
+			{Name: "build started"},
+			{Name: "build started"},
+			{Name: "build finished"},
+		})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(2))
+
+		_, event := fakeEmitter.EmitArgsForCall(1)
+		Expect(event.Name).To(Equal("build started"))
+	})
+
+	Context("when configured with --<emitter>-max-per-second", func() {
+		var factory *metricfakes.FakeEmitterFactory
+
+		BeforeEach(func() {
+			factory = &metricfakes.FakeEmitterFactory{}
+			factory.IsConfiguredReturns(true)
+			factory.DescriptionReturns("Rate Limited")
+			factory.FlagPrefixReturns("limited")
+			factory.NewEmitterReturns(fakeEmitter, nil)
+			metric.RegisterEmitter(factory)
+
+			core := struct{ metric.Config }{}
+
+			parser := flags.NewParser(&struct{}{}, flags.None)
+			parser.NamespaceDelimiter = "-"
+
+			group, err := parser.AddGroup("Metrics & Diagnostics", "", &core)
+			Expect(err).ToNot(HaveOccurred())
+
+			metric.WireEmitterFactories(group, []metric.EmitterFactory{factory})
+
+			_, err = parser.ParseArgs([]string{"--limited-max-per-second=1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = metric.Initialize(logger, "test", map[string]string{}, core.Config)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			metric.Deinitialize(lagertest.NewTestLogger("test"))
+		})
+
+		It("limits the emitter", func() {
+			for i := 0; i < 3; i++ {
+				metric.ErrorLog{Message: "oops", Value: 1}.Emit(logger)
+			}
+
+			Eventually(fakeEmitter.EmitCallCount).Should(Equal(1))
+			Consistently(fakeEmitter.EmitCallCount).Should(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package metric
+
+import (
+	"sort"
+	"sync"
+)
+
+// Metadata describes what a metric means, for emitters which can attach a
+// description to it and for operators discovering the metrics Concourse
+// emits.
+type Metadata struct {
+	Name string    `json:"name"`
+	Help string    `json:"help"`
+	Unit string    `json:"unit"`
+	Type EventType `json:"type"`
+}
+
+var (
+	registry     = map[string]Metadata{}
+	registryLock sync.RWMutex
+)
+
+// Register describes the metric with the given name, replacing any earlier
+// description of it.
+func Register(name string, help string, unit string, eventType EventType) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	registry[name] = Metadata{
+		Name: name,
+		Help: help,
+		Unit: unit,
+		Type: eventType,
+	}
+}
+
+// Lookup returns the description of the metric with the given name. Metrics
+// which were not registered are still emitted, and their description is
+// empty apart from the name.
+func Lookup(name string) Metadata {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	metadata, found := registry[name]
+	if !found {
+		return Metadata{Name: name}
+	}
+
+	return metadata
+}
+
+// Catalog returns the descriptions of all registered metrics, sorted by name.
+func Catalog() []Metadata {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	catalog := make([]Metadata, 0, len(registry))
+	for _, metadata := range registry {
+		catalog = append(catalog, metadata)
+	}
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+
+	return catalog
+}
@@ -0,0 +1,39 @@
+package metric_test
+
+import (
+	"github.com/concourse/concourse/atc/metric"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metric registry", func() {
+	It("describes the metrics Concourse emits", func() {
+		Expect(metric.Lookup("build finished")).To(Equal(metric.Metadata{
+			Name: "build finished",
+			Help: "Duration of a finished build.",
+			Unit: metric.UnitMilliseconds,
+			Type: metric.EventTypeTimer,
+		}))
+	})
+
+	It("describes unregistered metrics by name only", func() {
+		Expect(metric.Lookup("bogus")).To(Equal(metric.Metadata{Name: "bogus"}))
+	})
+
+	It("lists registered metrics sorted by name", func() {
+		metric.Register("test metric", "A metric registered by a test.", metric.UnitCount, metric.EventTypeGauge)
+
+		catalog := metric.Catalog()
+		Expect(catalog).To(ContainElement(metric.Metadata{
+			Name: "test metric",
+			Help: "A metric registered by a test.",
+			Unit: metric.UnitCount,
+			Type: metric.EventTypeGauge,
+		}))
+
+		for i := 1; i < len(catalog); i++ {
+			Expect(catalog[i-1].Name < catalog[i].Name).To(BeTrue())
+		}
+	})
+})
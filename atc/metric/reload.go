@@ -0,0 +1,110 @@
+package metric
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/tedsuo/ifrit"
+)
+
+// NewEmitterFactories returns empty copies of the registered emitter
+// factories, for parsing their configuration again without touching the one
+// the current emitters were built from.
+func NewEmitterFactories() []EmitterFactory {
+	factories := make([]EmitterFactory, len(emitterFactories))
+	for i, factory := range emitterFactories {
+		factories[i] = reflect.New(reflect.TypeOf(factory).Elem()).Interface().(EmitterFactory)
+	}
+
+	return factories
+}
+
+// Reload builds emitters from the given factories and configuration and swaps
+// them in for the current ones, which are closed once the events queued for
+// them have been emitted. If the new emitters cannot be built, the current
+// ones keep running.
+//
+// Emitters which were paused stay paused.
+func Reload(logger lager.Logger, host string, attributes map[string]string, config Config, factories []EmitterFactory) error {
+	attributes, err := attributesFromEnv(logger, attributes, config.TagsFromEnv)
+	if err != nil {
+		return err
+	}
+
+	configured, err := buildEmitter(logger, factories, config)
+	if err != nil {
+		return err
+	}
+
+	emissionsLock.Lock()
+
+	for _, toggle := range configured.toggled {
+		for _, old := range toggledEmitters {
+			if old.name == toggle.name {
+				toggle.setEnabled(old.isEnabled())
+			}
+		}
+	}
+
+	oldEmitter, oldEmissions, oldEmitLoopDone := emitter, emissions, emitLoopDone
+
+	emitterFactories = factories
+
+	if configured.emitter != nil {
+		startEmitting(configured, host, attributes, config)
+	} else {
+		emitter = nil
+		toggledEmitters = nil
+		circuitBreakers = nil
+		dropZeros = false
+	}
+
+	activate(factories)
+
+	if oldEmitter != nil {
+		close(oldEmissions)
+	}
+
+	emissionsLock.Unlock()
+
+	if oldEmitter != nil {
+		<-oldEmitLoopDone
+
+		closeEmitter(logger, oldEmitter)
+	}
+
+	logger.Info("reloaded-emitters", lager.Data{
+		"emitters": len(configured.toggled),
+	})
+
+	return nil
+}
+
+// ReloadOnSignal calls reload whenever the process receives one of the
+// signals. Errors are logged rather than returned, so that a bad configuration
+// leaves the current emitters running.
+func ReloadOnSignal(logger lager.Logger, reload func(lager.Logger) error, sigs ...os.Signal) ifrit.Runner {
+	return ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+		received := make(chan os.Signal, 1)
+		signal.Notify(received, sigs...)
+		defer signal.Stop(received)
+
+		close(ready)
+
+		for {
+			select {
+			case <-signals:
+				return nil
+			case sig := <-received:
+				logger.Info("reloading-emitters", lager.Data{"signal": sig.String()})
+
+				err := reload(logger.Session("reload"))
+				if err != nil {
+					logger.Error("failed-to-reload-emitters", err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package metric_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type activatingFactory struct {
+	*metricfakes.FakeEmitterFactory
+
+	activations int
+}
+
+func (factory *activatingFactory) Activate() {
+	factory.activations++
+}
+
+var _ = Describe("Reloading emitters", func() {
+	var (
+		oldEmitter *metricfakes.FakeEmitter
+		newEmitter *metricfakes.FakeEmitter
+		newFactory *metricfakes.FakeEmitterFactory
+		logger     *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+
+		oldEmitter = &metricfakes.FakeEmitter{}
+
+		oldFactory := &metricfakes.FakeEmitterFactory{}
+		oldFactory.IsConfiguredReturns(true)
+		oldFactory.DescriptionReturns("Old")
+		oldFactory.NewEmitterReturns(oldEmitter, nil)
+		metric.RegisterEmitter(oldFactory)
+
+		err := metric.Initialize(logger, "test", map[string]string{}, metric.Config{})
+		Expect(err).ToNot(HaveOccurred())
+
+		newEmitter = &metricfakes.FakeEmitter{}
+
+		newFactory = &metricfakes.FakeEmitterFactory{}
+		newFactory.IsConfiguredReturns(true)
+		newFactory.DescriptionReturns("New")
+		newFactory.NewEmitterReturns(newEmitter, nil)
+	})
+
+	AfterEach(func() {
+		metric.Deinitialize(lagertest.NewTestLogger("test"))
+	})
+
+	It("swaps in the new emitters and closes the old ones", func() {
+		err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{newFactory})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(oldEmitter.CloseCallCount()).To(Equal(1))
+
+		metric.ErrorLog{Message: "oops", Value: 1}.Emit(logger)
+		Eventually(newEmitter.EmitCallCount).Should(Equal(1))
+		Expect(oldEmitter.EmitCallCount()).To(Equal(0))
+
+		Expect(metric.EmitterStates()).To(Equal([]metric.EmitterState{
+			{Name: "new", Description: "New", Enabled: true},
+		}))
+	})
+
+	It("keeps the current emitters if the new ones cannot be built", func() {
+		newFactory.NewEmitterReturns(nil, errors.New("bad config"))
+
+		err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{newFactory})
+		Expect(err).To(HaveOccurred())
+
+		Expect(oldEmitter.CloseCallCount()).To(Equal(0))
+
+		metric.ErrorLog{Message: "oops", Value: 1}.Emit(logger)
+		Eventually(oldEmitter.EmitCallCount).Should(Equal(1))
+	})
+
+	It("keeps paused emitters paused", func() {
+		newFactory.DescriptionReturns("Old")
+
+		Expect(metric.SetEmitterEnabled("old", false)).To(Succeed())
+
+		err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{newFactory})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(metric.EmitterStates()).To(Equal([]metric.EmitterState{
+			{Name: "old", Description: "Old", Enabled: false},
+		}))
+	})
+
+	Context("when a factory activates its configuration", func() {
+		var activating *activatingFactory
+
+		BeforeEach(func() {
+			activating = &activatingFactory{FakeEmitterFactory: newFactory}
+		})
+
+		It("activates it once the new emitters are swapped in", func() {
+			err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{activating})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(activating.activations).To(Equal(1))
+		})
+
+		It("does not activate it if the new emitters cannot be built", func() {
+			failingFactory := &metricfakes.FakeEmitterFactory{}
+			failingFactory.IsConfiguredReturns(true)
+			failingFactory.NewEmitterReturns(nil, errors.New("bad config"))
+
+			err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{activating, failingFactory})
+			Expect(err).To(HaveOccurred())
+
+			Expect(activating.activations).To(Equal(0))
+		})
+
+		It("does not activate it if it is not configured", func() {
+			newFactory.IsConfiguredReturns(false)
+
+			err := metric.Reload(logger, "test", map[string]string{}, metric.Config{}, []metric.EmitterFactory{activating})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(activating.activations).To(Equal(0))
+		})
+	})
+})
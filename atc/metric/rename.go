@@ -0,0 +1,95 @@
+package metric
+
+import (
+	"fmt"
+	"strings"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// RenamingEmitter renames events before passing them on, so that emitters
+// send them under the names existing dashboards expect.
+type RenamingEmitter struct {
+	Emitter
+
+	names    map[string]string
+	prefixes []renamePrefix
+}
+
+type renamePrefix struct {
+	old string
+	new string
+}
+
+// NewRenamingEmitter wraps an emitter with the given rules in the form
+// OLD=NEW. Names are matched as emitted by Concourse, before emitters
+// sanitize them. A rule whose sides both end in '*' replaces the prefix of
+// any name starting with OLD; exact rules take precedence, then the longest
+// matching prefix.
+func NewRenamingEmitter(emitter Emitter, rules []string) (*RenamingEmitter, error) {
+	renaming := &RenamingEmitter{
+		Emitter: emitter,
+
+		names: map[string]string{},
+	}
+
+	for _, rule := range rules {
+		segs := strings.SplitN(rule, "=", 2)
+		if len(segs) != 2 || segs[0] == "" || segs[1] == "" {
+			return nil, fmt.Errorf("invalid metric rename '%s': must be in the form OLD=NEW", rule)
+		}
+
+		oldName, newName := segs[0], segs[1]
+
+		oldPrefix := strings.HasSuffix(oldName, "*")
+		newPrefix := strings.HasSuffix(newName, "*")
+
+		switch {
+		case oldPrefix && newPrefix:
+			renaming.prefixes = append(renaming.prefixes, renamePrefix{
+				old: strings.TrimSuffix(oldName, "*"),
+				new: strings.TrimSuffix(newName, "*"),
+			})
+		case oldPrefix || newPrefix:
+			return nil, fmt.Errorf("invalid metric rename '%s': both or neither names must end in '*'", rule)
+		default:
+			renaming.names[oldName] = newName
+		}
+	}
+
+	return renaming, nil
+}
+
+func (emitter *RenamingEmitter) Emit(logger lager.Logger, event Event) {
+	event.Name = emitter.rename(event.Name)
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *RenamingEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	renamed := make([]Event, len(events))
+	for i, event := range events {
+		event.Name = emitter.rename(event.Name)
+		renamed[i] = event
+	}
+
+	EmitBatch(logger, emitter.Emitter, renamed)
+}
+
+func (emitter *RenamingEmitter) rename(name string) string {
+	if newName, found := emitter.names[name]; found {
+		return newName
+	}
+
+	var longest *renamePrefix
+	for i, prefix := range emitter.prefixes {
+		if strings.HasPrefix(name, prefix.old) && (longest == nil || len(prefix.old) > len(longest.old)) {
+			longest = &emitter.prefixes[i]
+		}
+	}
+
+	if longest == nil {
+		return name
+	}
+
+	return longest.new + strings.TrimPrefix(name, longest.old)
+}
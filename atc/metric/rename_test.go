@@ -0,0 +1,75 @@
+package metric_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RenamingEmitter", func() {
+	var fakeEmitter *metricfakes.FakeEmitter
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+	})
+
+	emitted := func(rules []string, names ...string) []string {
+		renaming, err := metric.NewRenamingEmitter(fakeEmitter, rules)
+		Expect(err).ToNot(HaveOccurred())
+
+		logger := lagertest.NewTestLogger("test")
+		for _, name := range names {
+			renaming.Emit(logger, metric.Event{Name: name})
+		}
+
+		var emittedNames []string
+		for i := 0; i < fakeEmitter.EmitCallCount(); i++ {
+			_, event := fakeEmitter.EmitArgsForCall(i)
+			emittedNames = append(emittedNames, event.Name)
+		}
+
+		return emittedNames
+	}
+
+	It("renames events with a matching name", func() {
+		Expect(emitted(
+			[]string{"build finished=ci build duration"},
+			"build finished", "build started",
+		)).To(Equal([]string{"ci build duration", "build started"}))
+	})
+
+	It("replaces the longest matching prefix", func() {
+		Expect(emitted(
+			[]string{"worker *=ci worker *", "worker container*=ci container*"},
+			"worker volumes", "worker containers", "build started",
+		)).To(Equal([]string{"ci worker volumes", "ci containers", "build started"}))
+	})
+
+	It("prefers exact names over prefixes", func() {
+		Expect(emitted(
+			[]string{"build *=ci build *", "build started=ci starts"},
+			"build started", "build finished",
+		)).To(Equal([]string{"ci starts", "ci build finished"}))
+	})
+
+	It("renames batches of events", func() {
+		renaming, err := metric.NewRenamingEmitter(fakeEmitter, []string{"build started=ci starts"})
+		Expect(err).ToNot(HaveOccurred())
+
+		renaming.EmitBatch(lagertest.NewTestLogger("test"), []metric.Event{{Name: "build started"}})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(1))
+		_, event := fakeEmitter.EmitArgsForCall(0)
+		Expect(event.Name).To(Equal("ci starts"))
+	})
+
+	It("rejects invalid rules", func() {
+		for _, rule := range []string{"build started", "=ci", "build *=ci build", "build=ci *"} {
+			_, err := metric.NewRenamingEmitter(fakeEmitter, []string{rule})
+			Expect(err).To(HaveOccurred(), rule)
+		}
+	})
+})
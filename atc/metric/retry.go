@@ -0,0 +1,176 @@
+package metric
+
+import (
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cenkalti/backoff"
+	"github.com/pkg/errors"
+)
+
+// FallibleEmitter can be implemented by emitters which know whether an event
+// was sent, so that events which failed to be sent can be retried.
+type FallibleEmitter interface {
+	Emitter
+
+	TryEmit(lager.Logger, Event) error
+}
+
+// FallibleBatchEmitter can be implemented by fallible emitters which send
+// several events at once, so that a batch which failed to be sent can be
+// retried or spooled as a whole rather than an event at a time.
+type FallibleBatchEmitter interface {
+	FallibleEmitter
+
+	TryEmitBatch(lager.Logger, []Event) error
+}
+
+// canTryBatch returns whether the emitter sends batches as a whole. The
+// retrying and circuit breaking emitters only do if the emitter they wrap
+// does.
+func canTryBatch(emitter FallibleEmitter) bool {
+	switch wrapper := emitter.(type) {
+	case *RetryingEmitter:
+		return canTryBatch(wrapper.FallibleEmitter)
+	case *CircuitBreakerEmitter:
+		return canTryBatch(wrapper.FallibleEmitter)
+	}
+
+	_, ok := emitter.(FallibleBatchEmitter)
+	return ok
+}
+
+// tryEmitBatch sends the events through the emitter's TryEmitBatch if it
+// sends batches as a whole, and one at a time otherwise, returning the last
+// error.
+func tryEmitBatch(logger lager.Logger, emitter FallibleEmitter, events []Event) error {
+	if canTryBatch(emitter) {
+		return emitter.(FallibleBatchEmitter).TryEmitBatch(logger, events)
+	}
+
+	var lastErr error
+	for _, event := range events {
+		err := emitter.TryEmit(logger, event)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// TransientError marks an error which may not happen again, e.g. because a
+// backend is briefly unavailable.
+type TransientError struct {
+	Err error
+}
+
+func (err TransientError) Error() string {
+	return err.Err.Error()
+}
+
+// IsTransient returns whether emitting an event could succeed when retried.
+// That is the case for TransientErrors and for temporary network errors.
+func IsTransient(err error) bool {
+	switch cause := errors.Cause(err).(type) {
+	case TransientError:
+		return true
+	case net.Error:
+		return cause.Temporary() || cause.Timeout()
+	default:
+		return false
+	}
+}
+
+// RetryingEmitter retries events which failed to be sent with a transient
+// error, backing off exponentially with jitter in between.
+//
+// Retries hold up the events queued behind them, which are dropped once the
+// queue is full.
+type RetryingEmitter struct {
+	FallibleEmitter
+
+	maxAttempts int
+	baseDelay   time.Duration
+}
+
+func NewRetryingEmitter(emitter FallibleEmitter, maxAttempts int, baseDelay time.Duration) *RetryingEmitter {
+	return &RetryingEmitter{
+		FallibleEmitter: emitter,
+
+		maxAttempts: maxAttempts,
+		baseDelay:   baseDelay,
+	}
+}
+
+func (emitter *RetryingEmitter) Emit(logger lager.Logger, event Event) {
+	err := emitter.TryEmit(logger, event)
+	if err != nil {
+		logger.Error("failed-to-send-metric", errors.Wrap(ErrFailedToEmit, err.Error()), lager.Data{
+			"metric-name": event.Name,
+		})
+	}
+}
+
+func (emitter *RetryingEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	if !canTryBatch(emitter.FallibleEmitter) {
+		for _, event := range events {
+			emitter.Emit(logger, event)
+		}
+
+		return
+	}
+
+	err := emitter.TryEmitBatch(logger, events)
+	if err != nil {
+		logger.Error("failed-to-send-metrics", errors.Wrap(ErrFailedToEmit, err.Error()), lager.Data{
+			"metrics": len(events),
+		})
+	}
+}
+
+// TryEmit returns the error of the last attempt if the event could not be
+// sent.
+func (emitter *RetryingEmitter) TryEmit(logger lager.Logger, event Event) error {
+	return emitter.retry(logger, lager.Data{"metric-name": event.Name}, func() error {
+		return emitter.FallibleEmitter.TryEmit(logger, event)
+	})
+}
+
+// TryEmitBatch retries the batch as a whole if the wrapped emitter sends
+// batches as a whole, and each event on its own otherwise.
+func (emitter *RetryingEmitter) TryEmitBatch(logger lager.Logger, events []Event) error {
+	if !canTryBatch(emitter.FallibleEmitter) {
+		return tryEmitBatch(logger, emitter, events)
+	}
+
+	return emitter.retry(logger, lager.Data{"metrics": len(events)}, func() error {
+		return tryEmitBatch(logger, emitter.FallibleEmitter, events)
+	})
+}
+
+func (emitter *RetryingEmitter) retry(logger lager.Logger, data lager.Data, try func() error) error {
+	retry := backoff.NewExponentialBackOff()
+	retry.InitialInterval = emitter.baseDelay
+	retry.MaxElapsedTime = 0
+
+	attempts := uint64(0)
+	if emitter.maxAttempts > 1 {
+		attempts = uint64(emitter.maxAttempts - 1)
+	}
+
+	return backoff.RetryNotify(func() error {
+		err := try()
+		if err != nil && !IsTransient(err) {
+			return backoff.Permanent(err)
+		}
+
+		return err
+	}, backoff.WithMaxRetries(retry, attempts), func(err error, wait time.Duration) {
+		logger.Info("retrying-metric", data, lager.Data{
+			"error":    err.Error(),
+			"retry-in": wait.String(),
+		})
+	})
+}
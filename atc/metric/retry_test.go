@@ -0,0 +1,149 @@
+package metric_test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fallibleEmitter struct {
+	metricfakes.FakeEmitter
+
+	errs     []error
+	attempts int
+	sent     []metric.Event
+	mu       sync.Mutex
+}
+
+func (emitter *fallibleEmitter) TryEmit(logger lager.Logger, event metric.Event) error {
+	return emitter.try(event)
+}
+
+// try sends the events as a single attempt, failing with the next of the
+// queued errors if there is one.
+func (emitter *fallibleEmitter) try(events ...metric.Event) error {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	emitter.attempts++
+
+	if len(emitter.errs) == 0 {
+		emitter.sent = append(emitter.sent, events...)
+		return nil
+	}
+
+	err := emitter.errs[0]
+	emitter.errs = emitter.errs[1:]
+	return err
+}
+
+func (emitter *fallibleEmitter) sentEvents() []metric.Event {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	return append([]metric.Event{}, emitter.sent...)
+}
+
+func (emitter *fallibleEmitter) attemptCount() int {
+	emitter.mu.Lock()
+	defer emitter.mu.Unlock()
+
+	return emitter.attempts
+}
+
+type fallibleBatchEmitter struct {
+	fallibleEmitter
+}
+
+func (emitter *fallibleBatchEmitter) TryEmitBatch(logger lager.Logger, events []metric.Event) error {
+	return emitter.try(events...)
+}
+
+var _ = Describe("RetryingEmitter", func() {
+	var (
+		fallible *fallibleEmitter
+		logger   *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		fallible = &fallibleEmitter{}
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	emit := func() {
+		metric.NewRetryingEmitter(fallible, 3, time.Millisecond).Emit(logger, metric.Event{Name: "build started"})
+	}
+
+	It("retries transient errors", func() {
+		fallible.errs = []error{
+			metric.TransientError{Err: errors.New("unavailable")},
+			metric.TransientError{Err: errors.New("unavailable")},
+		}
+
+		emit()
+
+		Expect(fallible.attempts).To(Equal(3))
+		Expect(logger.LogMessages()).ToNot(ContainElement("test.failed-to-send-metric"))
+	})
+
+	It("gives up after the maximum number of attempts", func() {
+		fallible.errs = []error{
+			metric.TransientError{Err: errors.New("unavailable")},
+			metric.TransientError{Err: errors.New("unavailable")},
+			metric.TransientError{Err: errors.New("unavailable")},
+			metric.TransientError{Err: errors.New("unavailable")},
+		}
+
+		emit()
+
+		Expect(fallible.attempts).To(Equal(3))
+		Expect(logger.LogMessages()).To(ContainElement("test.failed-to-send-metric"))
+	})
+
+	It("does not retry other errors", func() {
+		fallible.errs = []error{errors.New("invalid")}
+
+		emit()
+
+		Expect(fallible.attempts).To(Equal(1))
+		Expect(logger.LogMessages()).To(ContainElement("test.failed-to-send-metric"))
+	})
+
+	Context("when the emitter sends batches as a whole", func() {
+		It("retries the batch as a whole", func() {
+			batching := &fallibleBatchEmitter{}
+			batching.errs = []error{metric.TransientError{Err: errors.New("unavailable")}}
+
+			metric.NewRetryingEmitter(batching, 3, time.Millisecond).EmitBatch(logger, []metric.Event{
+				{Name: "build started"},
+				{Name: "build finished"},
+			})
+
+			Expect(batching.attempts).To(Equal(2))
+			Expect(batching.sentEvents()).To(HaveLen(2))
+			Expect(logger.LogMessages()).ToNot(ContainElement("test.failed-to-send-metrics"))
+		})
+	})
+
+	Context("when the emitter sends events one at a time", func() {
+		It("retries each event of a batch on its own", func() {
+			fallible.errs = []error{metric.TransientError{Err: errors.New("unavailable")}}
+
+			metric.NewRetryingEmitter(fallible, 3, time.Millisecond).EmitBatch(logger, []metric.Event{
+				{Name: "build started"},
+				{Name: "build finished"},
+			})
+
+			Expect(fallible.attempts).To(Equal(3))
+			Expect(fallible.sentEvents()).To(HaveLen(2))
+		})
+	})
+})
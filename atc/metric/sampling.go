@@ -0,0 +1,174 @@
+package metric
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// SamplingEmitter passes on a fraction of each metric's events. Counters are
+// scaled by the inverse of the rate so that their totals stay correct.
+//
+// Sampling is systematic rather than random: every 1/rate-th event of a metric
+// is kept, starting from an offset derived from a hash of its name, so the
+// same share of every metric is kept.
+type SamplingEmitter struct {
+	Emitter
+
+	rate      float64
+	overrides map[string]float64
+
+	seen  map[string]uint64
+	seenL sync.Mutex
+}
+
+// NewSamplingEmitter wraps an emitter, sampling events at the given rate or
+// at the rate of an override, in the form NAME=RATE.
+func NewSamplingEmitter(emitter Emitter, rate float64, overrides []string) (*SamplingEmitter, error) {
+	err := validateSampleRate(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	sampling := &SamplingEmitter{
+		Emitter: emitter,
+
+		rate:      rate,
+		overrides: map[string]float64{},
+
+		seen: map[string]uint64{},
+	}
+
+	for _, override := range overrides {
+		segs := strings.SplitN(override, "=", 2)
+		if len(segs) != 2 {
+			return nil, fmt.Errorf("invalid metric sample '%s': must be in the form NAME=RATE", override)
+		}
+
+		rate, err := strconv.ParseFloat(segs[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric sample '%s': %s", override, err)
+		}
+
+		err = validateSampleRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric sample '%s': %s", override, err)
+		}
+
+		sampling.overrides[sanitizeName(segs[0])] = rate
+	}
+
+	return sampling, nil
+}
+
+func validateSampleRate(rate float64) error {
+	if rate <= 0 || rate > 1 {
+		return fmt.Errorf("sample rate must be greater than 0 and at most 1, got %v", rate)
+	}
+
+	return nil
+}
+
+func (emitter *SamplingEmitter) Emit(logger lager.Logger, event Event) {
+	sampled, ok := emitter.sampleEvent(event)
+	if !ok {
+		return
+	}
+
+	emitter.Emitter.Emit(logger, sampled)
+}
+
+func (emitter *SamplingEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	sampled := make([]Event, 0, len(events))
+	for _, event := range events {
+		event, ok := emitter.sampleEvent(event)
+		if ok {
+			sampled = append(sampled, event)
+		}
+	}
+
+	if len(sampled) == 0 {
+		return
+	}
+
+	EmitBatch(logger, emitter.Emitter, sampled)
+}
+
+// sampleEvent returns whether the event is to be emitted, scaling counters
+// which are.
+func (emitter *SamplingEmitter) sampleEvent(event Event) (Event, bool) {
+	name := sanitizeName(event.Name)
+
+	rate, found := emitter.overrides[name]
+	if !found {
+		rate = emitter.rate
+	}
+
+	if rate == 1 {
+		return event, true
+	}
+
+	if !emitter.sample(name, rate) {
+		return event, false
+	}
+
+	if event.Type == EventTypeCounter {
+		event.Value = scaleValue(event.Value, 1/rate)
+	}
+
+	return event, true
+}
+
+func (emitter *SamplingEmitter) sample(name string, rate float64) bool {
+	emitter.seenL.Lock()
+	n := emitter.seen[name]
+	emitter.seen[name] = n + 1
+	emitter.seenL.Unlock()
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	offset := float64(hash.Sum32()) / math.MaxUint32
+
+	// keep the event whenever the sampled share of the events seen so far
+	// crosses a whole number
+	return math.Floor(float64(n+1)*rate+offset) > math.Floor(float64(n)*rate+offset)
+}
+
+func scaleValue(value interface{}, factor float64) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v) * factor
+	case int8:
+		return float64(v) * factor
+	case int16:
+		return float64(v) * factor
+	case int32:
+		return float64(v) * factor
+	case int64:
+		return float64(v) * factor
+	case uint:
+		return float64(v) * factor
+	case uint8:
+		return float64(v) * factor
+	case uint16:
+		return float64(v) * factor
+	case uint32:
+		return float64(v) * factor
+	case uint64:
+		return float64(v) * factor
+	case float32:
+		return float64(v) * factor
+	case float64:
+		return v * factor
+	case time.Duration:
+		return time.Duration(float64(v) * factor)
+	default:
+		return value
+	}
+}
@@ -0,0 +1,94 @@
+package metric_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SamplingEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		sampling    *metric.SamplingEmitter
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+
+		var err error
+		sampling, err = metric.NewSamplingEmitter(fakeEmitter, 0.25, []string{"build finished=1"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	emitN := func(n int, event metric.Event) {
+		logger := lagertest.NewTestLogger("test")
+		for i := 0; i < n; i++ {
+			sampling.Emit(logger, event)
+		}
+	}
+
+	It("emits the sampled share of each metric", func() {
+		emitN(100, metric.Event{Name: "http response time", Value: 1, Type: metric.EventTypeTimer})
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(25))
+
+		_, event := fakeEmitter.EmitArgsForCall(0)
+		Expect(event.Value).To(Equal(1))
+	})
+
+	It("scales counters by the inverse of the rate", func() {
+		emitN(100, metric.Event{Name: "error log", Value: 2, Type: metric.EventTypeCounter})
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(25))
+
+		total := 0.0
+		for i := 0; i < fakeEmitter.EmitCallCount(); i++ {
+			_, event := fakeEmitter.EmitArgsForCall(i)
+			total += event.Value.(float64)
+		}
+
+		Expect(total).To(Equal(200.0))
+	})
+
+	DescribeTable("scaling counters of every numeric type",
+		func(value interface{}) {
+			emitN(4, metric.Event{Name: "error log", Value: value, Type: metric.EventTypeCounter})
+			Expect(fakeEmitter.EmitCallCount()).To(Equal(1))
+
+			_, event := fakeEmitter.EmitArgsForCall(0)
+			Expect(event.Value).To(Equal(8.0))
+		},
+		Entry("int", int(2)),
+		Entry("int8", int8(2)),
+		Entry("int16", int16(2)),
+		Entry("int32", int32(2)),
+		Entry("int64", int64(2)),
+		Entry("uint", uint(2)),
+		Entry("uint8", uint8(2)),
+		Entry("uint16", uint16(2)),
+		Entry("uint32", uint32(2)),
+		Entry("uint64", uint64(2)),
+		Entry("float32", float32(2)),
+		Entry("float64", float64(2)),
+	)
+
+	It("emits every event of metrics with an override of 1", func() {
+		emitN(10, metric.Event{Name: "build finished", Value: 1})
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(10))
+	})
+
+	It("rejects rates outside of (0, 1]", func() {
+		_, err := metric.NewSamplingEmitter(fakeEmitter, 0, nil)
+		Expect(err).To(HaveOccurred())
+
+		_, err = metric.NewSamplingEmitter(fakeEmitter, 1, []string{"build finished=2"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects malformed overrides", func() {
+		_, err := metric.NewSamplingEmitter(fakeEmitter, 1, []string{"build finished"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,525 @@
+package metric
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/pkg/errors"
+)
+
+// spoolReplayInterval is how often the spool is replayed while it holds any
+// events, so that an unreachable backend is not hit for every event.
+const spoolReplayInterval = 10 * time.Second
+
+// spoolReplayChunk is the number of spooled events read at once when
+// replaying, so that a large spool is never read into memory as a whole.
+const spoolReplayChunk = 100
+
+// SpoolingEmitter spools events to disk while the wrapped emitter fails to
+// send them with transient errors, and replays them in order once it
+// recovers. Events spooled before a restart are replayed on startup.
+//
+// Replaying happens in the background. Events emitted meanwhile are spooled
+// behind the ones being replayed, so that they are still sent in order. A
+// crash while replaying may send some events twice.
+//
+// Once the spool reaches its maximum size the oldest events are dropped.
+type SpoolingEmitter struct {
+	FallibleEmitter
+
+	path     string
+	maxBytes int64
+
+	lock    sync.Mutex
+	size    int64
+	dropped int
+
+	// trimmed is the number of bytes trimmed off the front of the spool so
+	// far, and replayed the position up to which its events have been sent,
+	// counting the trimmed bytes, so that both survive the spool being
+	// trimmed while events are being replayed
+	trimmed  int64
+	replayed int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type spooledEvent struct {
+	Name       string            `json:"name"`
+	Value      interface{}       `json:"value"`
+	State      EventState        `json:"state,omitempty"`
+	Type       EventType         `json:"type,omitempty"`
+	Unit       string            `json:"unit,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Host       string            `json:"host,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+func NewSpoolingEmitter(logger lager.Logger, emitter FallibleEmitter, path string, maxBytes int64) (*SpoolingEmitter, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spool directory")
+	}
+
+	spooling := &SpoolingEmitter{
+		FallibleEmitter: emitter,
+
+		path:     path,
+		maxBytes: maxBytes,
+
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	info, err := os.Stat(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "failed to stat spool")
+	}
+
+	if info != nil && info.Size() > 0 {
+		spooling.size = info.Size()
+
+		logger.Info("replaying-spooled-metrics", lager.Data{
+			"path":  path,
+			"bytes": spooling.size,
+		})
+	}
+
+	go spooling.periodicallyReplay(logger)
+
+	return spooling, nil
+}
+
+func (emitter *SpoolingEmitter) Emit(logger lager.Logger, event Event) {
+	emitter.emit(logger, []Event{event}, lager.Data{"metric-name": event.Name}, func() error {
+		return emitter.FallibleEmitter.TryEmit(logger, event)
+	})
+}
+
+// EmitBatch spools the batch as a whole if the wrapped emitter sends batches
+// as a whole, and each event on its own otherwise.
+func (emitter *SpoolingEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	if !canTryBatch(emitter.FallibleEmitter) {
+		for _, event := range events {
+			emitter.Emit(logger, event)
+		}
+
+		return
+	}
+
+	emitter.emit(logger, events, lager.Data{"metrics": len(events)}, func() error {
+		return tryEmitBatch(logger, emitter.FallibleEmitter, events)
+	})
+}
+
+// emit sends the events unless there are spooled events waiting to be
+// replayed, spooling them instead if that fails with a transient error.
+func (emitter *SpoolingEmitter) emit(logger lager.Logger, events []Event, data lager.Data, send func() error) {
+	emitter.lock.Lock()
+
+	// spool behind whatever is waiting to be replayed so that events are sent
+	// in order
+	if emitter.size > 0 {
+		for _, event := range events {
+			emitter.spool(logger, event)
+		}
+
+		emitter.lock.Unlock()
+		return
+	}
+
+	emitter.lock.Unlock()
+
+	err := send()
+	if err == nil {
+		return
+	}
+
+	if !IsTransient(err) {
+		logger.Error("failed-to-send-metric", errors.Wrap(ErrFailedToEmit, err.Error()), data)
+		return
+	}
+
+	logger.Info("spooling-metrics", lager.Data{
+		"error": err.Error(),
+	})
+
+	emitter.lock.Lock()
+	for _, event := range events {
+		emitter.spool(logger, event)
+	}
+	emitter.lock.Unlock()
+}
+
+// Close stops replaying and closes the wrapped emitter. Events which have yet
+// to be replayed stay spooled.
+func (emitter *SpoolingEmitter) Close() error {
+	emitter.once.Do(func() { close(emitter.stop) })
+	<-emitter.done
+
+	return emitter.FallibleEmitter.Close()
+}
+
+func (emitter *SpoolingEmitter) periodicallyReplay(logger lager.Logger) {
+	defer close(emitter.done)
+
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		emitter.replay(logger)
+
+		select {
+		case <-ticker.C:
+		case <-emitter.stop:
+			return
+		}
+	}
+}
+
+// replay sends the spooled events a chunk at a time, stopping at the first
+// transient error or once the emitter is closed.
+func (emitter *SpoolingEmitter) replay(logger lager.Logger) {
+	count := 0
+
+	for {
+		emitter.lock.Lock()
+
+		if emitter.size == 0 {
+			emitter.lock.Unlock()
+			break
+		}
+
+		lines, err := emitter.readLines(emitter.offset(), spoolReplayChunk)
+		if err != nil {
+			emitter.lock.Unlock()
+			logger.Error("failed-to-read-spool", err)
+			return
+		}
+
+		if len(lines) == 0 {
+			// everything was sent, including the events spooled meanwhile
+			err := emitter.trim(emitter.size)
+			emitter.lock.Unlock()
+
+			if err != nil {
+				logger.Error("failed-to-write-spool", err)
+				return
+			}
+
+			break
+		}
+
+		position := emitter.replayed
+		emitter.lock.Unlock()
+
+		for _, line := range lines {
+			select {
+			case <-emitter.stop:
+				emitter.replayedTo(logger, position)
+				return
+			default:
+			}
+
+			if !emitter.send(logger, line) {
+				emitter.replayedTo(logger, position)
+				return
+			}
+
+			position += int64(len(line))
+			count++
+		}
+
+		emitter.lock.Lock()
+		if position > emitter.replayed {
+			emitter.replayed = position
+		}
+		emitter.lock.Unlock()
+	}
+
+	if count > 0 {
+		logger.Info("replayed-spooled-metrics", lager.Data{
+			"count": count,
+		})
+	}
+}
+
+// send sends a spooled event, returning false if it failed with a transient
+// error.
+func (emitter *SpoolingEmitter) send(logger lager.Logger, line []byte) bool {
+	var spooled spooledEvent
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&spooled)
+	if err != nil {
+		logger.Error("failed-to-parse-spooled-metric", err)
+		return true
+	}
+
+	event := spooled.event()
+
+	err = emitter.FallibleEmitter.TryEmit(logger, event)
+	if err == nil {
+		return true
+	}
+
+	if !IsTransient(err) {
+		logger.Error("failed-to-send-metric", errors.Wrap(ErrFailedToEmit, err.Error()), lager.Data{
+			"metric-name": event.Name,
+		})
+		return true
+	}
+
+	return false
+}
+
+// replayedTo trims the events which were replayed up to the given position
+// off the spool, so that they are not sent again after a restart.
+func (emitter *SpoolingEmitter) replayedTo(logger lager.Logger, position int64) {
+	emitter.lock.Lock()
+	defer emitter.lock.Unlock()
+
+	if position > emitter.replayed {
+		emitter.replayed = position
+	}
+
+	err := emitter.trim(emitter.offset())
+	if err != nil {
+		logger.Error("failed-to-write-spool", err)
+	}
+}
+
+// offset returns the offset in the spool file up to which events have been
+// replayed.
+func (emitter *SpoolingEmitter) offset() int64 {
+	if emitter.replayed < emitter.trimmed {
+		emitter.replayed = emitter.trimmed
+	}
+
+	return emitter.replayed - emitter.trimmed
+}
+
+func (emitter *SpoolingEmitter) spool(logger lager.Logger, event Event) {
+	line, err := json.Marshal(newSpooledEvent(event))
+	if err != nil {
+		logger.Error("failed-to-serialize-metric", err)
+		return
+	}
+
+	line = append(line, '\n')
+
+	if emitter.size+int64(len(line)) > emitter.maxBytes {
+		emitter.dropOldest(logger, int64(len(line)))
+	}
+
+	file, err := os.OpenFile(emitter.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		logger.Error("failed-to-open-spool", err)
+		return
+	}
+
+	defer file.Close()
+
+	n, err := file.Write(line)
+	emitter.size += int64(n)
+	if err != nil {
+		logger.Error("failed-to-write-spool", err)
+	}
+}
+
+// dropOldest drops the oldest spooled events to make room for the given
+// number of bytes. It frees a tenth of the spool on top, so that the spool is
+// not rewritten for every event while it stays full. Events which were
+// replayed already are trimmed without counting as dropped.
+func (emitter *SpoolingEmitter) dropOldest(logger lager.Logger, needed int64) {
+	file, err := os.Open(emitter.path)
+	if err != nil {
+		logger.Error("failed-to-read-spool", err)
+		return
+	}
+
+	defer file.Close()
+
+	limit := emitter.maxBytes - emitter.maxBytes/10 - needed
+	replayed := emitter.offset()
+
+	reader := bufio.NewReader(file)
+
+	var cut int64
+	drop := 0
+	for emitter.size-cut > limit {
+		line, err := reader.ReadBytes('\n')
+		if cut >= replayed && len(line) > 0 {
+			drop++
+		}
+
+		cut += int64(len(line))
+
+		if err != nil {
+			break
+		}
+	}
+
+	if cut < replayed {
+		cut = replayed
+	}
+
+	err = emitter.trim(cut)
+	if err != nil {
+		logger.Error("failed-to-write-spool", err)
+		return
+	}
+
+	emitter.dropped += drop
+
+	logger.Info("dropped-spooled-metrics", lager.Data{
+		"dropped":       drop,
+		"total-dropped": emitter.dropped,
+	})
+}
+
+// readLines reads up to max spooled events from the given offset.
+func (emitter *SpoolingEmitter) readLines(offset int64, max int) ([][]byte, error) {
+	file, err := os.Open(emitter.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]byte
+
+	reader := bufio.NewReader(file)
+	for len(lines) < max {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	return lines, nil
+}
+
+// trim drops the given number of bytes off the front of the spool, copying
+// the rest to a temporary file first so that a crash does not leave a
+// partial spool.
+func (emitter *SpoolingEmitter) trim(n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(emitter.path), filepath.Base(emitter.path))
+	if err != nil {
+		return err
+	}
+
+	size, err := emitter.copyFrom(tmp, n)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), emitter.path)
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	emitter.trimmed += emitter.size - size
+	emitter.size = size
+
+	return nil
+}
+
+// copyFrom copies the spool from the given offset to the writer.
+func (emitter *SpoolingEmitter) copyFrom(writer io.Writer, offset int64) (int64, error) {
+	file, err := os.Open(emitter.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, err
+	}
+
+	return io.Copy(writer, file)
+}
+
+func newSpooledEvent(event Event) spooledEvent {
+	value := event.Value
+	if duration, ok := value.(time.Duration); ok {
+		// emit() marks durations as timers in milliseconds
+		value = float64(duration) / float64(time.Millisecond)
+	}
+
+	return spooledEvent{
+		Name:       event.Name,
+		Value:      value,
+		State:      event.State,
+		Type:       event.Type,
+		Unit:       event.Unit,
+		Attributes: event.Attributes,
+		Host:       event.Host,
+		Time:       event.Timestamp(),
+	}
+}
+
+func (spooled spooledEvent) event() Event {
+	value := spooled.Value
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			value = int(i)
+		} else if f, err := number.Float64(); err == nil {
+			value = f
+		}
+	}
+
+	return Event{
+		Name:       spooled.Name,
+		Value:      value,
+		State:      spooled.State,
+		Type:       spooled.Type,
+		Unit:       spooled.Unit,
+		Attributes: spooled.Attributes,
+		Host:       spooled.Host,
+		Time:       spooled.Time,
+	}
+}
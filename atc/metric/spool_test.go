@@ -0,0 +1,234 @@
+package metric_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpoolingEmitter", func() {
+	var (
+		fallible *fallibleEmitter
+		logger   *lagertest.TestLogger
+		dir      string
+		path     string
+	)
+
+	BeforeEach(func() {
+		fallible = &fallibleEmitter{}
+		logger = lagertest.NewTestLogger("test")
+
+		var err error
+		dir, err = ioutil.TempDir("", "spool")
+		Expect(err).ToNot(HaveOccurred())
+
+		path = filepath.Join(dir, "emitter.spool")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	unavailable := func(n int) []error {
+		errs := make([]error, n)
+		for i := range errs {
+			errs[i] = metric.TransientError{Err: errors.New("unavailable")}
+		}
+		return errs
+	}
+
+	sentNames := func() []string {
+		names := []string{}
+		for _, event := range fallible.sentEvents() {
+			names = append(names, event.Name)
+		}
+		return names
+	}
+
+	spoolSize := func() int64 {
+		info, err := os.Stat(path)
+		Expect(err).ToNot(HaveOccurred())
+		return info.Size()
+	}
+
+	// replay starts a new emitter on the spool, which replays it in the
+	// background, and closes it once it has replayed the given number of
+	// events
+	replay := func(maxBytes int64, count int) {
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, maxBytes)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(sentNames).Should(HaveLen(count))
+		Expect(spooling.Close()).To(Succeed())
+	}
+
+	It("sends events straight through while the emitter succeeds", func() {
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer spooling.Close()
+
+		spooling.Emit(logger, metric.Event{Name: "build started"})
+
+		Expect(sentNames()).To(Equal([]string{"build started"}))
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+
+	It("spools events which fail with transient errors and replays them on startup", func() {
+		fallible.errs = unavailable(1)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		spooling.Emit(logger, metric.Event{Name: "build started", Value: 1, Time: time.Unix(10, 0)})
+		spooling.Emit(logger, metric.Event{Name: "build finished", Value: 1.5})
+
+		// the second event is spooled behind the first without trying to send
+		Expect(fallible.attempts).To(Equal(1))
+		Expect(fallible.sentEvents()).To(BeEmpty())
+
+		Expect(spooling.Close()).To(Succeed())
+
+		replay(1024*1024, 2)
+
+		sent := fallible.sentEvents()
+		Expect(sentNames()).To(Equal([]string{"build started", "build finished"}))
+		Expect(sent[0].Value).To(Equal(1))
+		Expect(sent[0].Time.Unix()).To(Equal(int64(10)))
+		Expect(sent[1].Value).To(Equal(1.5))
+
+		Expect(spoolSize()).To(BeZero())
+	})
+
+	It("spools batches which fail as a whole", func() {
+		batching := &fallibleBatchEmitter{}
+		batching.errs = unavailable(1)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, batching, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		spooling.EmitBatch(logger, []metric.Event{
+			{Name: "build started"},
+			{Name: "build finished"},
+		})
+
+		Expect(batching.attemptCount()).To(Equal(1))
+		Expect(spooling.Close()).To(Succeed())
+
+		fallible = &batching.fallibleEmitter
+		replay(1024*1024, 2)
+
+		Expect(sentNames()).To(Equal([]string{"build started", "build finished"}))
+	})
+
+	It("replays large spools a chunk at a time", func() {
+		fallible.errs = unavailable(1)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 250; i++ {
+			spooling.Emit(logger, metric.Event{Name: fmt.Sprintf("event %d", i)})
+		}
+
+		Expect(spooling.Close()).To(Succeed())
+
+		replay(1024*1024, 250)
+
+		names := sentNames()
+		Expect(names[0]).To(Equal("event 0"))
+		Expect(names[249]).To(Equal("event 249"))
+		Expect(spoolSize()).To(BeZero())
+	})
+
+	It("spools events emitted while replaying behind the replayed ones", func() {
+		fallible.errs = unavailable(1)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		spooling.Emit(logger, metric.Event{Name: "build started"})
+		Expect(spooling.Close()).To(Succeed())
+
+		spooling, err = metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer spooling.Close()
+
+		spooling.Emit(logger, metric.Event{Name: "build finished"})
+
+		Eventually(sentNames).Should(HaveLen(2))
+		Expect(sentNames()).To(Equal([]string{"build started", "build finished"}))
+	})
+
+	It("keeps the spool when replaying fails", func() {
+		fallible.errs = unavailable(2)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		spooling.Emit(logger, metric.Event{Name: "build started"})
+		Expect(spooling.Close()).To(Succeed())
+
+		spooling, err = metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(fallible.attemptCount).Should(Equal(2))
+		Expect(spooling.Close()).To(Succeed())
+
+		Expect(fallible.sentEvents()).To(BeEmpty())
+		Expect(spoolSize()).ToNot(BeZero())
+
+		replay(1024*1024, 1)
+		Expect(sentNames()).To(Equal([]string{"build started"}))
+	})
+
+	It("drops events which fail with other errors", func() {
+		fallible.errs = []error{errors.New("bad request")}
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 1024*1024)
+		Expect(err).ToNot(HaveOccurred())
+
+		defer spooling.Close()
+
+		spooling.Emit(logger, metric.Event{Name: "build started"})
+
+		Expect(path).ToNot(BeAnExistingFile())
+		Expect(logger.LogMessages()).To(ContainElement("test.failed-to-send-metric"))
+	})
+
+	It("drops the oldest events once the spool is full", func() {
+		fallible.errs = unavailable(1)
+
+		spooling, err := metric.NewSpoolingEmitter(logger, fallible, path, 512)
+		Expect(err).ToNot(HaveOccurred())
+
+		for i := 0; i < 20; i++ {
+			spooling.Emit(logger, metric.Event{Name: fmt.Sprintf("event %d", i), Value: i})
+		}
+
+		Expect(logger.LogMessages()).To(ContainElement("test.dropped-spooled-metrics"))
+		Expect(spoolSize()).To(BeNumerically("<=", 512))
+
+		Expect(spooling.Close()).To(Succeed())
+
+		spooling, err = metric.NewSpoolingEmitter(logger, fallible, path, 512)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(spoolSize).Should(BeZero())
+		Expect(spooling.Close()).To(Succeed())
+
+		names := sentNames()
+		Expect(names).ToNot(ContainElement("event 0"))
+		Expect(names[len(names)-1]).To(Equal("event 19"))
+	})
+})
@@ -0,0 +1,156 @@
+package metric
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// TagEmitter renames and normalizes the attributes of events before passing
+// them on, so that every emitter builds its tags from the same keys.
+type TagEmitter struct {
+	Emitter
+
+	renames   map[string]string
+	normalize bool
+
+	// collisions which have been logged, so that each is only logged once
+	warned sync.Map
+}
+
+// NewTagEmitter wraps an emitter with the given rename rules in the form
+// OLD=NEW. When normalizing, keys are lowercased (after renaming) and
+// attributes with empty values are dropped.
+//
+// When several attributes end up with the same key, the one whose original
+// key sorts first wins.
+func NewTagEmitter(emitter Emitter, renames []string, normalize bool) (*TagEmitter, error) {
+	tags := &TagEmitter{
+		Emitter: emitter,
+
+		renames:   map[string]string{},
+		normalize: normalize,
+	}
+
+	for _, rule := range renames {
+		segs := strings.SplitN(rule, "=", 2)
+		if len(segs) != 2 || segs[0] == "" || segs[1] == "" || strings.ContainsAny(rule, " \t\r\n") {
+			return nil, fmt.Errorf("invalid metric tag rename '%s': must be in the form OLD=NEW", rule)
+		}
+
+		tags.renames[segs[0]] = segs[1]
+	}
+
+	return tags, nil
+}
+
+func (emitter *TagEmitter) Emit(logger lager.Logger, event Event) {
+	event.Attributes = emitter.attributes(logger, event.Attributes)
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *TagEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	tagged := make([]Event, len(events))
+	for i, event := range events {
+		event.Attributes = emitter.attributes(logger, event.Attributes)
+		tagged[i] = event
+	}
+
+	EmitBatch(logger, emitter.Emitter, tagged)
+}
+
+func (emitter *TagEmitter) attributes(logger lager.Logger, attributes map[string]string) map[string]string {
+	if len(attributes) == 0 {
+		return attributes
+	}
+
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make(map[string]string, len(attributes))
+	origins := make(map[string]string, len(attributes))
+
+	for _, key := range keys {
+		value := attributes[key]
+		if emitter.normalize && value == "" {
+			continue
+		}
+
+		newKey := key
+		if renamed, found := emitter.renames[key]; found {
+			newKey = renamed
+		}
+
+		if emitter.normalize {
+			newKey = strings.ToLower(newKey)
+		}
+
+		if origin, found := origins[newKey]; found {
+			emitter.warnCollision(logger, newKey, origin, key)
+			continue
+		}
+
+		result[newKey] = value
+		origins[newKey] = key
+	}
+
+	return result
+}
+
+func (emitter *TagEmitter) warnCollision(logger lager.Logger, key string, kept string, dropped string) {
+	if _, warned := emitter.warned.LoadOrStore(kept+"\x00"+dropped, true); warned {
+		return
+	}
+
+	logger.Info("metric-tag-collision", lager.Data{
+		"tag":     key,
+		"kept":    kept,
+		"dropped": dropped,
+	})
+}
+
+// attributesFromEnv adds an attribute for each rule in the form NAME=ENV_VAR
+// to a copy of the given attributes, unless it is already set. Environment
+// variables which are not set are skipped with a warning.
+func attributesFromEnv(logger lager.Logger, attributes map[string]string, rules []string) (map[string]string, error) {
+	if len(rules) == 0 {
+		return attributes, nil
+	}
+
+	merged := make(map[string]string, len(attributes)+len(rules))
+	for name, value := range attributes {
+		merged[name] = value
+	}
+
+	for _, rule := range rules {
+		segs := strings.SplitN(rule, "=", 2)
+		if len(segs) != 2 || segs[0] == "" || segs[1] == "" || strings.ContainsAny(segs[0], " \t\r\n") {
+			return nil, fmt.Errorf("invalid metric tag from env '%s': must be in the form NAME=ENV_VAR", rule)
+		}
+
+		name, envVar := segs[0], segs[1]
+
+		value, found := os.LookupEnv(envVar)
+		if !found {
+			logger.Info("missing-metric-tag-env-var", lager.Data{
+				"tag":     name,
+				"env-var": envVar,
+			})
+			continue
+		}
+
+		if _, set := merged[name]; !set {
+			merged[name] = value
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,77 @@
+package metric_test
+
+import (
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/concourse/concourse/atc/metric"
+	"github.com/concourse/concourse/atc/metric/metricfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TagEmitter", func() {
+	var (
+		fakeEmitter *metricfakes.FakeEmitter
+		logger      *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		fakeEmitter = &metricfakes.FakeEmitter{}
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	emittedAttributes := func(renames []string, normalize bool, attributes map[string]string) map[string]string {
+		tags, err := metric.NewTagEmitter(fakeEmitter, renames, normalize)
+		Expect(err).ToNot(HaveOccurred())
+
+		tags.Emit(logger, metric.Event{Name: "build started", Attributes: attributes})
+
+		Expect(fakeEmitter.EmitCallCount()).To(Equal(1))
+		_, event := fakeEmitter.EmitArgsForCall(0)
+		return event.Attributes
+	}
+
+	It("renames attributes", func() {
+		Expect(emittedAttributes([]string{"job=service"}, false, map[string]string{
+			"job":  "unit",
+			"team": "main",
+		})).To(Equal(map[string]string{
+			"service": "unit",
+			"team":    "main",
+		}))
+	})
+
+	It("does not modify the original attributes", func() {
+		attributes := map[string]string{"job": "unit"}
+		emittedAttributes([]string{"job=service"}, false, attributes)
+
+		Expect(attributes).To(Equal(map[string]string{"job": "unit"}))
+	})
+
+	It("lowercases keys and drops empty values when normalizing", func() {
+		Expect(emittedAttributes(nil, true, map[string]string{
+			"Team":     "main",
+			"pipeline": "",
+		})).To(Equal(map[string]string{
+			"team": "main",
+		}))
+	})
+
+	It("keeps the attribute whose original key sorts first on collisions", func() {
+		Expect(emittedAttributes([]string{"job=service"}, false, map[string]string{
+			"job":     "unit",
+			"service": "concourse",
+		})).To(Equal(map[string]string{
+			"service": "unit",
+		}))
+
+		Expect(logger.LogMessages()).To(ContainElement("test.metric-tag-collision"))
+	})
+
+	It("rejects invalid rules", func() {
+		for _, rule := range []string{"job", "=service", "job=", "job=my service"} {
+			_, err := metric.NewTagEmitter(fakeEmitter, []string{rule}, false)
+			Expect(err).To(HaveOccurred(), rule)
+		}
+	})
+})
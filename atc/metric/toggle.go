@@ -0,0 +1,101 @@
+package metric
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/lager"
+)
+
+var ErrEmitterNotFound = errors.New("emitter not found")
+
+// EmitterState describes whether a configured emitter is emitting events. The
+// name is the sanitized description of the emitter, e.g. "google_pubsub".
+type EmitterState struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+// toggledEmitter only passes events on while it is enabled, so that a backend
+// which is down can be paused at runtime. It is closed either way.
+type toggledEmitter struct {
+	Emitter
+
+	name        string
+	description string
+	enabled     int32
+}
+
+func newToggledEmitter(emitter Emitter, description string) *toggledEmitter {
+	return &toggledEmitter{
+		Emitter: emitter,
+
+		name:        sanitizeName(description),
+		description: description,
+		enabled:     1,
+	}
+}
+
+func (emitter *toggledEmitter) Emit(logger lager.Logger, event Event) {
+	if !emitter.isEnabled() {
+		return
+	}
+
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *toggledEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	if !emitter.isEnabled() {
+		return
+	}
+
+	EmitBatch(logger, emitter.Emitter, events)
+}
+
+func (emitter *toggledEmitter) isEnabled() bool {
+	return atomic.LoadInt32(&emitter.enabled) == 1
+}
+
+func (emitter *toggledEmitter) setEnabled(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+
+	atomic.StoreInt32(&emitter.enabled, value)
+}
+
+// EmitterStates returns the state of each configured emitter.
+func EmitterStates() []EmitterState {
+	emissionsLock.RLock()
+	defer emissionsLock.RUnlock()
+
+	states := []EmitterState{}
+	for _, emitter := range toggledEmitters {
+		states = append(states, EmitterState{
+			Name:        emitter.name,
+			Description: emitter.description,
+			Enabled:     emitter.isEnabled(),
+		})
+	}
+
+	return states
+}
+
+// SetEmitterEnabled pauses or resumes the configured emitter with the given
+// name, returning ErrEmitterNotFound if there is none.
+func SetEmitterEnabled(name string, enabled bool) error {
+	emissionsLock.RLock()
+	defer emissionsLock.RUnlock()
+
+	for _, emitter := range toggledEmitters {
+		if strings.EqualFold(emitter.name, name) {
+			emitter.setEnabled(enabled)
+			return nil
+		}
+	}
+
+	return ErrEmitterNotFound
+}
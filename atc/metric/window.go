@@ -0,0 +1,174 @@
+package metric
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// windowBuffer decides which events a windowedEmitter holds back, and what it
+// emits for them at the end of each window.
+type windowBuffer interface {
+	// holds returns whether the event is held back until the end of the
+	// window rather than passed on as it comes.
+	holds(Event) bool
+
+	// merge folds an event which is held back into the entry of its series,
+	// which is nil for the first event of the series in the window.
+	merge(entry interface{}, event Event) interface{}
+
+	// flush returns the events to emit for the entries of a window, which are
+	// given in the order in which their series were first seen.
+	flush(entries []interface{}) []Event
+}
+
+// windowedEmitter passes events on to the wrapped emitter as they come, except
+// for those which its buffer holds back. These are grouped by name, host and
+// attributes, and flushed at the end of each window.
+type windowedEmitter struct {
+	Emitter
+
+	buffer windowBuffer
+
+	// serializes calls to the wrapped emitter, which are made both for events
+	// passed on as they come and when flushing
+	emitL sync.Mutex
+
+	pending  map[string]*windowEntry
+	keys     []string
+	pendingL sync.Mutex
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+type windowEntry struct {
+	value  interface{}
+	logger lager.Logger
+}
+
+func newWindowedEmitter(emitter Emitter, buffer windowBuffer, window time.Duration) *windowedEmitter {
+	windowed := &windowedEmitter{
+		Emitter: emitter,
+
+		buffer: buffer,
+
+		pending: map[string]*windowEntry{},
+
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go windowed.flushLoop(window)
+
+	return windowed
+}
+
+func (emitter *windowedEmitter) Emit(logger lager.Logger, event Event) {
+	if emitter.hold(logger, event) {
+		return
+	}
+
+	emitter.emitL.Lock()
+	defer emitter.emitL.Unlock()
+
+	emitter.Emitter.Emit(logger, event)
+}
+
+func (emitter *windowedEmitter) EmitBatch(logger lager.Logger, events []Event) {
+	passed := make([]Event, 0, len(events))
+	for _, event := range events {
+		if !emitter.hold(logger, event) {
+			passed = append(passed, event)
+		}
+	}
+
+	if len(passed) == 0 {
+		return
+	}
+
+	emitter.emitL.Lock()
+	defer emitter.emitL.Unlock()
+
+	EmitBatch(logger, emitter.Emitter, passed)
+}
+
+// Close flushes the events held back so far before closing the wrapped
+// emitter. It is safe to call more than once.
+func (emitter *windowedEmitter) Close() error {
+	emitter.once.Do(func() { close(emitter.stop) })
+	<-emitter.done
+
+	return emitter.Emitter.Close()
+}
+
+// hold returns whether the event was held back to be flushed with the window.
+func (emitter *windowedEmitter) hold(logger lager.Logger, event Event) bool {
+	if !emitter.buffer.holds(event) {
+		return false
+	}
+
+	key := aggregationKey(event)
+
+	emitter.pendingL.Lock()
+	defer emitter.pendingL.Unlock()
+
+	entry, found := emitter.pending[key]
+	if !found {
+		entry = &windowEntry{}
+		emitter.pending[key] = entry
+		emitter.keys = append(emitter.keys, key)
+	}
+
+	entry.value = emitter.buffer.merge(entry.value, event)
+	entry.logger = logger
+
+	return true
+}
+
+func (emitter *windowedEmitter) flushLoop(window time.Duration) {
+	defer close(emitter.done)
+
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			emitter.flush()
+		case <-emitter.stop:
+			emitter.flush()
+			return
+		}
+	}
+}
+
+// flush emits the events for the current window, in the order in which their
+// series were first seen.
+func (emitter *windowedEmitter) flush() {
+	emitter.pendingL.Lock()
+	pending, keys := emitter.pending, emitter.keys
+	emitter.pending, emitter.keys = map[string]*windowEntry{}, nil
+	emitter.pendingL.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+
+	entries := make([]interface{}, len(keys))
+	for i, key := range keys {
+		entries[i] = pending[key].value
+	}
+
+	events := emitter.buffer.flush(entries)
+	if len(events) == 0 {
+		return
+	}
+
+	emitter.emitL.Lock()
+	defer emitter.emitL.Unlock()
+
+	EmitBatch(pending[keys[0]].logger, emitter.Emitter, events)
+}
@@ -0,0 +1,18 @@
+package atc
+
+type MetricEmitter struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type SetMetricEmitterStateRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type MetricMetadata struct {
+	Name string `json:"name"`
+	Help string `json:"help"`
+	Unit string `json:"unit"`
+	Type string `json:"type"`
+}
@@ -12,4 +12,5 @@ const (
 	PaginationQueryLimit      = "limit"
 	PaginationWebLimit        = 100
 	PaginationAPIDefaultLimit = 100
+	PaginationAPIMaxLimit     = 1000
 )
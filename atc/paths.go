@@ -0,0 +1,66 @@
+package atc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/tedsuo/rata"
+)
+
+// PathFor returns the path of the named route with its parameters filled in.
+// The params alternate between parameter names and values, e.g.:
+//
+//	PathFor(GetBuild, "build_id", "42")
+func PathFor(name string, params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("odd number of params for route '%s'", name)
+	}
+
+	route, found := Routes.FindRouteByName(name)
+	if !found {
+		return "", fmt.Errorf("unknown route '%s'", name)
+	}
+
+	routeParams := rata.Params{}
+	for i := 0; i < len(params); i += 2 {
+		routeParams[params[i]] = params[i+1]
+	}
+
+	return route.CreatePath(routeParams)
+}
+
+// BuildPath returns the path of the build with the given ID.
+func BuildPath(buildID int) string {
+	return mustPathFor(GetBuild, "build_id", strconv.Itoa(buildID))
+}
+
+// PipelinePath returns the path of the pipeline.
+func PipelinePath(teamName string, pipelineName string) string {
+	return mustPathFor(GetPipeline, "team_name", teamName, "pipeline_name", pipelineName)
+}
+
+// JobPath returns the path of the job in the pipeline.
+func JobPath(teamName string, pipelineName string, jobName string) string {
+	return mustPathFor(GetJob, "team_name", teamName, "pipeline_name", pipelineName, "job_name", jobName)
+}
+
+// JobBuildPath returns the path of the named build of the job.
+func JobBuildPath(teamName string, pipelineName string, jobName string, buildName string) string {
+	return mustPathFor(GetJobBuild, "team_name", teamName, "pipeline_name", pipelineName, "job_name", jobName, "build_name", buildName)
+}
+
+// ResourcePath returns the path of the resource in the pipeline.
+func ResourcePath(teamName string, pipelineName string, resourceName string) string {
+	return mustPathFor(GetResource, "team_name", teamName, "pipeline_name", pipelineName, "resource_name", resourceName)
+}
+
+// mustPathFor is PathFor for the helpers above, which give every parameter of
+// their route and so can only fail if it no longer matches them.
+func mustPathFor(name string, params ...string) string {
+	path, err := PathFor(name, params...)
+	if err != nil {
+		panic(err)
+	}
+
+	return path
+}
@@ -0,0 +1,70 @@
+package atc_test
+
+import (
+	"strings"
+
+	"github.com/concourse/concourse/atc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Paths", func() {
+	Describe("PathFor", func() {
+		It("creates the path of every route from its declared template", func() {
+			for _, route := range atc.Routes {
+				params := []string{}
+				for _, component := range strings.Split(route.Path, "/") {
+					if strings.HasPrefix(component, ":") {
+						params = append(params, component[1:], component)
+					}
+				}
+
+				path, err := atc.PathFor(route.Name, params...)
+				Expect(err).ToNot(HaveOccurred(), route.Name)
+				Expect(path).To(Equal(route.Path), route.Name)
+			}
+		})
+
+		It("escapes the values of params", func() {
+			path, err := atc.PathFor(atc.GetPipeline, "team_name", "main", "pipeline_name", "some/pipeline")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(path).To(Equal("/api/v1/teams/main/pipelines/some%2Fpipeline"))
+		})
+
+		It("fails for unknown routes", func() {
+			_, err := atc.PathFor("bogus")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails when a param is missing", func() {
+			_, err := atc.PathFor(atc.GetPipeline, "team_name", "main")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails when a param has no value", func() {
+			_, err := atc.PathFor(atc.GetBuild, "build_id")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("creates the paths of builds", func() {
+		Expect(atc.BuildPath(42)).To(Equal("/api/v1/builds/42"))
+	})
+
+	It("creates the paths of pipelines", func() {
+		Expect(atc.PipelinePath("main", "some-pipeline")).To(Equal("/api/v1/teams/main/pipelines/some-pipeline"))
+	})
+
+	It("creates the paths of jobs", func() {
+		Expect(atc.JobPath("main", "some-pipeline", "some-job")).To(Equal("/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job"))
+	})
+
+	It("creates the paths of job builds", func() {
+		Expect(atc.JobBuildPath("main", "some-pipeline", "some-job", "3")).To(Equal("/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/builds/3"))
+	})
+
+	It("creates the paths of resources", func() {
+		Expect(atc.ResourcePath("main", "some-pipeline", "some-resource")).To(Equal("/api/v1/teams/main/pipelines/some-pipeline/resources/some-resource"))
+	})
+})
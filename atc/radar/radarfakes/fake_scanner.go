@@ -50,6 +50,19 @@ type FakeScanner struct {
 	scanFromVersionReturnsOnCall map[int]struct {
 		result1 error
 	}
+	TryScanFromVersionStub        func(lager.Logger, string, atc.Version) error
+	tryScanFromVersionMutex       sync.RWMutex
+	tryScanFromVersionArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 atc.Version
+	}
+	tryScanFromVersionReturns struct {
+		result1 error
+	}
+	tryScanFromVersionReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -241,6 +254,68 @@ func (fake *FakeScanner) ScanFromVersionReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeScanner) TryScanFromVersion(arg1 lager.Logger, arg2 string, arg3 atc.Version) error {
+	fake.tryScanFromVersionMutex.Lock()
+	ret, specificReturn := fake.tryScanFromVersionReturnsOnCall[len(fake.tryScanFromVersionArgsForCall)]
+	fake.tryScanFromVersionArgsForCall = append(fake.tryScanFromVersionArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 string
+		arg3 atc.Version
+	}{arg1, arg2, arg3})
+	fake.recordInvocation("TryScanFromVersion", []interface{}{arg1, arg2, arg3})
+	fake.tryScanFromVersionMutex.Unlock()
+	if fake.TryScanFromVersionStub != nil {
+		return fake.TryScanFromVersionStub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.tryScanFromVersionReturns
+	return fakeReturns.result1
+}
+
+func (fake *FakeScanner) TryScanFromVersionCallCount() int {
+	fake.tryScanFromVersionMutex.RLock()
+	defer fake.tryScanFromVersionMutex.RUnlock()
+	return len(fake.tryScanFromVersionArgsForCall)
+}
+
+func (fake *FakeScanner) TryScanFromVersionCalls(stub func(lager.Logger, string, atc.Version) error) {
+	fake.tryScanFromVersionMutex.Lock()
+	defer fake.tryScanFromVersionMutex.Unlock()
+	fake.TryScanFromVersionStub = stub
+}
+
+func (fake *FakeScanner) TryScanFromVersionArgsForCall(i int) (lager.Logger, string, atc.Version) {
+	fake.tryScanFromVersionMutex.RLock()
+	defer fake.tryScanFromVersionMutex.RUnlock()
+	argsForCall := fake.tryScanFromVersionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeScanner) TryScanFromVersionReturns(result1 error) {
+	fake.tryScanFromVersionMutex.Lock()
+	defer fake.tryScanFromVersionMutex.Unlock()
+	fake.TryScanFromVersionStub = nil
+	fake.tryScanFromVersionReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeScanner) TryScanFromVersionReturnsOnCall(i int, result1 error) {
+	fake.tryScanFromVersionMutex.Lock()
+	defer fake.tryScanFromVersionMutex.Unlock()
+	fake.TryScanFromVersionStub = nil
+	if fake.tryScanFromVersionReturnsOnCall == nil {
+		fake.tryScanFromVersionReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.tryScanFromVersionReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeScanner) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -250,6 +325,8 @@ func (fake *FakeScanner) Invocations() map[string][][]interface{} {
 	defer fake.scanMutex.RUnlock()
 	fake.scanFromVersionMutex.RLock()
 	defer fake.scanFromVersionMutex.RUnlock()
+	fake.tryScanFromVersionMutex.RLock()
+	defer fake.tryScanFromVersionMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
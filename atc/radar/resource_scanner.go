@@ -56,11 +56,12 @@ func NewResourceScanner(
 }
 
 var ErrFailedToAcquireLock = errors.New("failed to acquire lock")
+var ErrCheckInProgress = errors.New("check already in progress")
 var ErrResourceTypeNotFound = errors.New("resource type not found")
 var ErrResourceTypeCheckError = errors.New("resource type failed to check")
 
 func (scanner *resourceScanner) Run(logger lager.Logger, resourceName string) (time.Duration, error) {
-	interval, err := scanner.scan(logger.Session("tick"), resourceName, nil, false, false)
+	interval, err := scanner.scan(logger.Session("tick"), resourceName, nil, false, false, true)
 
 	err = swallowErrResourceScriptFailed(err)
 
@@ -68,20 +69,28 @@ func (scanner *resourceScanner) Run(logger lager.Logger, resourceName string) (t
 }
 
 func (scanner *resourceScanner) ScanFromVersion(logger lager.Logger, resourceName string, fromVersion atc.Version) error {
-	_, err := scanner.scan(logger, resourceName, fromVersion, true, true)
+	_, err := scanner.scan(logger, resourceName, fromVersion, true, true, true)
+
+	return err
+}
+
+// TryScanFromVersion is like ScanFromVersion, but returns ErrCheckInProgress
+// rather than waiting for a check which is already running.
+func (scanner *resourceScanner) TryScanFromVersion(logger lager.Logger, resourceName string, fromVersion atc.Version) error {
+	_, err := scanner.scan(logger, resourceName, fromVersion, true, true, false)
 
 	return err
 }
 
 func (scanner *resourceScanner) Scan(logger lager.Logger, resourceName string) error {
-	_, err := scanner.scan(logger, resourceName, nil, true, false)
+	_, err := scanner.scan(logger, resourceName, nil, true, false, true)
 
 	err = swallowErrResourceScriptFailed(err)
 
 	return err
 }
 
-func (scanner *resourceScanner) scan(logger lager.Logger, resourceName string, fromVersion atc.Version, mustComplete bool, saveGiven bool) (time.Duration, error) {
+func (scanner *resourceScanner) scan(logger lager.Logger, resourceName string, fromVersion atc.Version, mustComplete bool, saveGiven bool, waitForLock bool) (time.Duration, error) {
 	lockLogger := logger.Session("lock", lager.Data{
 		"resource": resourceName,
 	})
@@ -215,6 +224,11 @@ func (scanner *resourceScanner) scan(logger lager.Logger, resourceName string, f
 
 		if !acquired {
 			lockLogger.Debug("did-not-get-lock")
+
+			if !waitForLock {
+				return interval, ErrCheckInProgress
+			}
+
 			scanner.clock.Sleep(time.Second)
 			continue
 		}
@@ -1033,6 +1033,56 @@ var _ = Describe("ResourceScanner", func() {
 		})
 	})
 
+	Describe("TryScanFromVersion", func() {
+		var (
+			fakeResource *rfakes.FakeResource
+
+			scanErr error
+		)
+
+		BeforeEach(func() {
+			fakeWorker.NameReturns("some-worker")
+			fakePool.FindOrChooseWorkerForContainerReturns(fakeWorker, nil)
+
+			fakeContainer.HandleReturns("some-handle")
+			fakeWorker.FindOrCreateContainerReturns(fakeContainer, nil)
+
+			fakeResource = new(rfakes.FakeResource)
+			fakeResourceFactory.NewResourceForContainerReturns(fakeResource)
+		})
+
+		JustBeforeEach(func() {
+			scanErr = scanner.TryScanFromVersion(lagertest.NewTestLogger("test"), "some-resource", atc.Version{"version": "1"})
+		})
+
+		Context("if the lock cannot be acquired", func() {
+			BeforeEach(func() {
+				fakeResourceConfigScope.AcquireResourceCheckingLockReturns(nil, false, nil)
+			})
+
+			It("returns ErrCheckInProgress without waiting for the lock", func() {
+				Expect(scanErr).To(Equal(ErrCheckInProgress))
+				Expect(fakeResourceConfigScope.AcquireResourceCheckingLockCallCount()).To(Equal(1))
+				Expect(fakeResource.CheckCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("if the lock can be acquired", func() {
+			BeforeEach(func() {
+				fakeResourceConfigScope.AcquireResourceCheckingLockReturns(fakeLock, true, nil)
+				fakeResourceConfigScope.UpdateLastCheckedReturns(true, nil)
+			})
+
+			It("checks from the version", func() {
+				Expect(scanErr).ToNot(HaveOccurred())
+
+				Expect(fakeResource.CheckCallCount()).To(Equal(1))
+				_, _, version := fakeResource.CheckArgsForCall(0)
+				Expect(version).To(Equal(atc.Version{"version": "1"}))
+			})
+		})
+	})
+
 	Describe("ScanFromVersion", func() {
 		var (
 			fakeResource *rfakes.FakeResource
@@ -1058,6 +1108,33 @@ var _ = Describe("ResourceScanner", func() {
 			scanErr = scanner.ScanFromVersion(lagertest.NewTestLogger("test"), "some-resource", fromVersion)
 		})
 
+		Context("if the lock is not acquired at first", func() {
+			BeforeEach(func() {
+				results := make(chan bool, 2)
+				results <- false
+				results <- true
+				close(results)
+
+				fakeResourceConfigScope.AcquireResourceCheckingLockStub = func(logger lager.Logger, interval time.Duration) (lock.Lock, bool, error) {
+					if <-results {
+						return fakeLock, true, nil
+					} else {
+						// allow the sleep to continue
+						go fakeClock.WaitForWatcherAndIncrement(time.Second)
+						return nil, false, nil
+					}
+				}
+
+				fakeResourceConfigScope.UpdateLastCheckedReturns(true, nil)
+			})
+
+			It("waits for the lock and checks", func() {
+				Expect(scanErr).ToNot(HaveOccurred())
+				Expect(fakeResourceConfigScope.AcquireResourceCheckingLockCallCount()).To(Equal(2))
+				Expect(fakeResource.CheckCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("if the lock can be acquired and last checked updated", func() {
 			BeforeEach(func() {
 				fakeResourceConfigScope.AcquireResourceCheckingLockReturns(fakeLock, true, nil)
@@ -59,6 +59,12 @@ func (scanner *resourceTypeScanner) ScanFromVersion(logger lager.Logger, resourc
 	return err
 }
 
+// TryScanFromVersion waits for the lock like ScanFromVersion; only checks of
+// resources return ErrCheckInProgress.
+func (scanner *resourceTypeScanner) TryScanFromVersion(logger lager.Logger, resourceTypeName string, fromVersion atc.Version) error {
+	return scanner.ScanFromVersion(logger, resourceTypeName, fromVersion)
+}
+
 func (scanner *resourceTypeScanner) Scan(logger lager.Logger, resourceTypeName string) error {
 	_, err := scanner.scan(logger, resourceTypeName, nil, true, false)
 	return err
@@ -19,6 +19,7 @@ type Scanner interface {
 	Run(lager.Logger, string) (time.Duration, error)
 	Scan(lager.Logger, string) error
 	ScanFromVersion(lager.Logger, string, atc.Version) error
+	TryScanFromVersion(lager.Logger, string, atc.Version) error
 }
 
 //go:generate counterfeiter . ScanRunnerFactory
@@ -14,6 +14,8 @@ type Resource struct {
 	PinnedVersion  Version `json:"pinned_version,omitempty"`
 	PinnedInConfig bool    `json:"pinned_in_config,omitempty"`
 	PinComment     string  `json:"pin_comment,omitempty"`
+
+	LatestVersion Version `json:"latest_version,omitempty"`
 }
 
 var EnableGlobalResources bool
@@ -2,6 +2,9 @@ package atc
 
 import "github.com/tedsuo/rata"
 
+// APIVersion is the version of the API served by Routes.
+const APIVersion = "v1"
+
 const (
 	SaveConfig = "SaveConfig"
 	GetConfig  = "GetConfig"
@@ -17,6 +20,7 @@ const (
 
 	GetJob         = "GetJob"
 	CreateJobBuild = "CreateJobBuild"
+	RerunJobBuild  = "RerunJobBuild"
 	ListAllJobs    = "ListAllJobs"
 	ListJobs       = "ListJobs"
 	ListJobBuilds  = "ListJobBuilds"
@@ -78,10 +82,18 @@ const (
 	SetLogLevel = "SetLogLevel"
 	GetLogLevel = "GetLogLevel"
 
+	GetEmitters      = "GetEmitters"
+	SetEmitterState  = "SetEmitterState"
+	GetMetricCatalog = "GetMetricCatalog"
+	GetMetrics       = "GetMetrics"
+
 	DownloadCLI  = "DownloadCLI"
 	GetInfo      = "Info"
 	GetInfoCreds = "InfoCreds"
 
+	Healthz = "Healthz"
+	Readyz  = "Readyz"
+
 	ListContainers           = "ListContainers"
 	GetContainer             = "GetContainer"
 	HijackContainer          = "HijackContainer"
@@ -101,6 +113,7 @@ const (
 	CreateArtifact     = "CreateArtifact"
 	GetArtifact        = "GetArtifact"
 	ListBuildArtifacts = "ListBuildArtifacts"
+	GetBuildArtifact   = "GetBuildArtifact"
 )
 
 const (
@@ -108,102 +121,134 @@ const (
 	SaveConfigCheckCreds    = "check_creds"
 )
 
-var Routes = rata.Routes([]rata.Route{
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/config", Method: "PUT", Name: SaveConfig},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/config", Method: "GET", Name: GetConfig},
-
-	{Path: "/api/v1/teams/:team_name/builds", Method: "POST", Name: CreateBuild},
-
-	{Path: "/api/v1/builds", Method: "GET", Name: ListBuilds},
-	{Path: "/api/v1/builds/:build_id", Method: "GET", Name: GetBuild},
-	{Path: "/api/v1/builds/:build_id/plan", Method: "GET", Name: GetBuildPlan},
-	{Path: "/api/v1/builds/:build_id/events", Method: "GET", Name: BuildEvents},
-	{Path: "/api/v1/builds/:build_id/resources", Method: "GET", Name: BuildResources},
-	{Path: "/api/v1/builds/:build_id/abort", Method: "PUT", Name: AbortBuild},
-	{Path: "/api/v1/builds/:build_id/preparation", Method: "GET", Name: GetBuildPreparation},
-	{Path: "/api/v1/builds/:build_id/artifacts", Method: "GET", Name: ListBuildArtifacts},
-
-	{Path: "/api/v1/jobs", Method: "GET", Name: ListAllJobs},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs", Method: "GET", Name: ListJobs},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name", Method: "GET", Name: GetJob},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds", Method: "GET", Name: ListJobBuilds},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds", Method: "POST", Name: CreateJobBuild},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/inputs", Method: "GET", Name: ListJobInputs},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds/:build_name", Method: "GET", Name: GetJobBuild},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/pause", Method: "PUT", Name: PauseJob},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/unpause", Method: "PUT", Name: UnpauseJob},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/badge", Method: "GET", Name: JobBadge},
-	{Path: "/api/v1/pipelines/:pipeline_name/jobs/:job_name/badge", Method: "GET", Name: MainJobBadge},
-
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/tasks/:step_name/cache", Method: "DELETE", Name: ClearTaskCache},
-
-	{Path: "/api/v1/pipelines", Method: "GET", Name: ListAllPipelines},
-	{Path: "/api/v1/teams/:team_name/pipelines", Method: "GET", Name: ListPipelines},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name", Method: "GET", Name: GetPipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name", Method: "DELETE", Name: DeletePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/ordering", Method: "PUT", Name: OrderPipelines},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/pause", Method: "PUT", Name: PausePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/unpause", Method: "PUT", Name: UnpausePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/expose", Method: "PUT", Name: ExposePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/hide", Method: "PUT", Name: HidePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/versions-db", Method: "GET", Name: GetVersionsDB},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/rename", Method: "PUT", Name: RenamePipeline},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/builds", Method: "GET", Name: ListPipelineBuilds},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/builds", Method: "POST", Name: CreatePipelineBuild},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/badge", Method: "GET", Name: PipelineBadge},
-
-	{Path: "/api/v1/resources", Method: "GET", Name: ListAllResources},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources", Method: "GET", Name: ListResources},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resource-types", Method: "GET", Name: ListResourceTypes},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name", Method: "GET", Name: GetResource},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/check", Method: "POST", Name: CheckResource},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/check/webhook", Method: "POST", Name: CheckResourceWebHook},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resource-types/:resource_type_name/check", Method: "POST", Name: CheckResourceType},
-
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions", Method: "GET", Name: ListResourceVersions},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id", Method: "GET", Name: GetResourceVersion},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/enable", Method: "PUT", Name: EnableResourceVersion},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/disable", Method: "PUT", Name: DisableResourceVersion},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/pin", Method: "PUT", Name: PinResourceVersion},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/unpin", Method: "PUT", Name: UnpinResource},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/pin_comment", Method: "PUT", Name: SetPinCommentOnResource},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/input_to", Method: "GET", Name: ListBuildsWithVersionAsInput},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/output_of", Method: "GET", Name: ListBuildsWithVersionAsOutput},
-	{Path: "/api/v1/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_version_id/causality", Method: "GET", Name: GetResourceCausality},
-
-	{Path: "/api/v1/teams/:team_name/cc.xml", Method: "GET", Name: GetCC},
-
-	{Path: "/api/v1/workers", Method: "GET", Name: ListWorkers},
-	{Path: "/api/v1/workers", Method: "POST", Name: RegisterWorker},
-	{Path: "/api/v1/workers/:worker_name/land", Method: "PUT", Name: LandWorker},
-	{Path: "/api/v1/workers/:worker_name/retire", Method: "PUT", Name: RetireWorker},
-	{Path: "/api/v1/workers/:worker_name/prune", Method: "PUT", Name: PruneWorker},
-	{Path: "/api/v1/workers/:worker_name/heartbeat", Method: "PUT", Name: HeartbeatWorker},
-	{Path: "/api/v1/workers/:worker_name", Method: "DELETE", Name: DeleteWorker},
-
-	{Path: "/api/v1/log-level", Method: "GET", Name: GetLogLevel},
-	{Path: "/api/v1/log-level", Method: "PUT", Name: SetLogLevel},
-
-	{Path: "/api/v1/cli", Method: "GET", Name: DownloadCLI},
-	{Path: "/api/v1/info", Method: "GET", Name: GetInfo},
-	{Path: "/api/v1/info/creds", Method: "GET", Name: GetInfoCreds},
-
-	{Path: "/api/v1/containers/destroying", Method: "GET", Name: ListDestroyingContainers},
-	{Path: "/api/v1/containers/report", Method: "PUT", Name: ReportWorkerContainers},
-	{Path: "/api/v1/teams/:team_name/containers", Method: "GET", Name: ListContainers},
-	{Path: "/api/v1/teams/:team_name/containers/:id", Method: "GET", Name: GetContainer},
-	{Path: "/api/v1/teams/:team_name/containers/:id/hijack", Method: "GET", Name: HijackContainer},
-
-	{Path: "/api/v1/teams/:team_name/volumes", Method: "GET", Name: ListVolumes},
-	{Path: "/api/v1/volumes/destroying", Method: "GET", Name: ListDestroyingVolumes},
-	{Path: "/api/v1/volumes/report", Method: "PUT", Name: ReportWorkerVolumes},
-
-	{Path: "/api/v1/teams", Method: "GET", Name: ListTeams},
-	{Path: "/api/v1/teams/:team_name", Method: "PUT", Name: SetTeam},
-	{Path: "/api/v1/teams/:team_name/rename", Method: "PUT", Name: RenameTeam},
-	{Path: "/api/v1/teams/:team_name", Method: "DELETE", Name: DestroyTeam},
-	{Path: "/api/v1/teams/:team_name/builds", Method: "GET", Name: ListTeamBuilds},
-
-	{Path: "/api/v1/teams/:team_name/artifacts", Method: "POST", Name: CreateArtifact},
-	{Path: "/api/v1/teams/:team_name/artifacts/:artifact_id", Method: "GET", Name: GetArtifact},
+// apiRoutes are the routes of the API, relative to the prefix of its version.
+var apiRoutes = rata.Routes([]rata.Route{
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/config", Method: "PUT", Name: SaveConfig},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/config", Method: "GET", Name: GetConfig},
+
+	{Path: "/teams/:team_name/builds", Method: "POST", Name: CreateBuild},
+
+	{Path: "/builds", Method: "GET", Name: ListBuilds},
+	{Path: "/builds/:build_id", Method: "GET", Name: GetBuild},
+	{Path: "/builds/:build_id/plan", Method: "GET", Name: GetBuildPlan},
+	{Path: "/builds/:build_id/events", Method: "GET", Name: BuildEvents},
+	{Path: "/builds/:build_id/resources", Method: "GET", Name: BuildResources},
+	{Path: "/builds/:build_id/abort", Method: "PUT", Name: AbortBuild},
+	{Path: "/builds/:build_id/preparation", Method: "GET", Name: GetBuildPreparation},
+	{Path: "/builds/:build_id/artifacts", Method: "GET", Name: ListBuildArtifacts},
+	{Path: "/builds/:build_id/artifacts/:artifact_name", Method: "GET", Name: GetBuildArtifact},
+
+	{Path: "/jobs", Method: "GET", Name: ListAllJobs},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs", Method: "GET", Name: ListJobs},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name", Method: "GET", Name: GetJob},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds", Method: "GET", Name: ListJobBuilds},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds", Method: "POST", Name: CreateJobBuild},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/inputs", Method: "GET", Name: ListJobInputs},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds/:build_name", Method: "GET", Name: GetJobBuild},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/builds/:build_name/rerun", Method: "POST", Name: RerunJobBuild},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/pause", Method: "PUT", Name: PauseJob},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/unpause", Method: "PUT", Name: UnpauseJob},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/badge", Method: "GET", Name: JobBadge},
+	{Path: "/pipelines/:pipeline_name/jobs/:job_name/badge", Method: "GET", Name: MainJobBadge},
+
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/jobs/:job_name/tasks/:step_name/cache", Method: "DELETE", Name: ClearTaskCache},
+
+	{Path: "/pipelines", Method: "GET", Name: ListAllPipelines},
+	{Path: "/teams/:team_name/pipelines", Method: "GET", Name: ListPipelines},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name", Method: "GET", Name: GetPipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name", Method: "DELETE", Name: DeletePipeline},
+	{Path: "/teams/:team_name/pipelines/ordering", Method: "PUT", Name: OrderPipelines},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/pause", Method: "PUT", Name: PausePipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/unpause", Method: "PUT", Name: UnpausePipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/expose", Method: "PUT", Name: ExposePipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/hide", Method: "PUT", Name: HidePipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/versions-db", Method: "GET", Name: GetVersionsDB},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/rename", Method: "PUT", Name: RenamePipeline},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/builds", Method: "GET", Name: ListPipelineBuilds},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/builds", Method: "POST", Name: CreatePipelineBuild},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/badge", Method: "GET", Name: PipelineBadge},
+
+	{Path: "/resources", Method: "GET", Name: ListAllResources},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources", Method: "GET", Name: ListResources},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resource-types", Method: "GET", Name: ListResourceTypes},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name", Method: "GET", Name: GetResource},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/check", Method: "POST", Name: CheckResource},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/check/webhook", Method: "POST", Name: CheckResourceWebHook},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resource-types/:resource_type_name/check", Method: "POST", Name: CheckResourceType},
+
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions", Method: "GET", Name: ListResourceVersions},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id", Method: "GET", Name: GetResourceVersion},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/enable", Method: "PUT", Name: EnableResourceVersion},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/disable", Method: "PUT", Name: DisableResourceVersion},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/pin", Method: "PUT", Name: PinResourceVersion},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/unpin", Method: "PUT", Name: UnpinResource},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/pin_comment", Method: "PUT", Name: SetPinCommentOnResource},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/input_to", Method: "GET", Name: ListBuildsWithVersionAsInput},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_config_version_id/output_of", Method: "GET", Name: ListBuildsWithVersionAsOutput},
+	{Path: "/teams/:team_name/pipelines/:pipeline_name/resources/:resource_name/versions/:resource_version_id/causality", Method: "GET", Name: GetResourceCausality},
+
+	{Path: "/teams/:team_name/cc.xml", Method: "GET", Name: GetCC},
+
+	{Path: "/workers", Method: "GET", Name: ListWorkers},
+	{Path: "/workers", Method: "POST", Name: RegisterWorker},
+	{Path: "/workers/:worker_name/land", Method: "PUT", Name: LandWorker},
+	{Path: "/workers/:worker_name/retire", Method: "PUT", Name: RetireWorker},
+	{Path: "/workers/:worker_name/prune", Method: "PUT", Name: PruneWorker},
+	{Path: "/workers/:worker_name/heartbeat", Method: "PUT", Name: HeartbeatWorker},
+	{Path: "/workers/:worker_name", Method: "DELETE", Name: DeleteWorker},
+
+	{Path: "/log-level", Method: "GET", Name: GetLogLevel},
+	{Path: "/log-level", Method: "PUT", Name: SetLogLevel},
+
+	{Path: "/metrics/emitters", Method: "GET", Name: GetEmitters},
+	{Path: "/metrics/emitters/:emitter_name", Method: "PUT", Name: SetEmitterState},
+	{Path: "/metrics/catalog", Method: "GET", Name: GetMetricCatalog},
+	{Path: "/metrics", Method: "GET", Name: GetMetrics},
+
+	{Path: "/cli", Method: "GET", Name: DownloadCLI},
+	{Path: "/info", Method: "GET", Name: GetInfo},
+	{Path: "/info/creds", Method: "GET", Name: GetInfoCreds},
+
+	{Path: "/healthz", Method: "GET", Name: Healthz},
+	{Path: "/readyz", Method: "GET", Name: Readyz},
+
+	{Path: "/containers/destroying", Method: "GET", Name: ListDestroyingContainers},
+	{Path: "/containers/report", Method: "PUT", Name: ReportWorkerContainers},
+	{Path: "/teams/:team_name/containers", Method: "GET", Name: ListContainers},
+	{Path: "/teams/:team_name/containers/:id", Method: "GET", Name: GetContainer},
+	{Path: "/teams/:team_name/containers/:id/hijack", Method: "GET", Name: HijackContainer},
+
+	{Path: "/teams/:team_name/volumes", Method: "GET", Name: ListVolumes},
+	{Path: "/volumes/destroying", Method: "GET", Name: ListDestroyingVolumes},
+	{Path: "/volumes/report", Method: "PUT", Name: ReportWorkerVolumes},
+
+	{Path: "/teams", Method: "GET", Name: ListTeams},
+	{Path: "/teams/:team_name", Method: "PUT", Name: SetTeam},
+	{Path: "/teams/:team_name/rename", Method: "PUT", Name: RenameTeam},
+	{Path: "/teams/:team_name", Method: "DELETE", Name: DestroyTeam},
+	{Path: "/teams/:team_name/builds", Method: "GET", Name: ListTeamBuilds},
+
+	{Path: "/teams/:team_name/artifacts", Method: "POST", Name: CreateArtifact},
+	{Path: "/teams/:team_name/artifacts/:artifact_id", Method: "GET", Name: GetArtifact},
 })
+
+// Routes are the routes of the current version of the API.
+var Routes = VersionedRoutes(APIVersion, apiRoutes)
+
+// VersionedRoutes returns copies of the routes with their paths under the
+// prefix of the given version of the API, e.g. /api/v1.
+func VersionedRoutes(version string, routes rata.Routes) rata.Routes {
+	versioned := make(rata.Routes, len(routes))
+	for i, route := range routes {
+		route.Path = APIPrefix(version) + route.Path
+		versioned[i] = route
+	}
+
+	return versioned
+}
+
+// APIPrefix returns the path under which the given version of the API is
+// served.
+func APIPrefix(version string) string {
+	return "/api/" + version
+}
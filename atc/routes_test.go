@@ -0,0 +1,33 @@
+package atc_test
+
+import (
+	"strings"
+
+	"github.com/concourse/concourse/atc"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	It("serves every route under the prefix of the current version", func() {
+		for _, route := range atc.Routes {
+			Expect(strings.HasPrefix(route.Path, "/api/v1/")).To(BeTrue(), route.Name)
+		}
+	})
+
+	Describe("VersionedRoutes", func() {
+		It("prefixes the paths without changing the given routes", func() {
+			routes := rata.Routes{
+				{Path: "/builds/:build_id", Method: "GET", Name: atc.GetBuild},
+			}
+
+			Expect(atc.VersionedRoutes("v2", routes)).To(Equal(rata.Routes{
+				{Path: "/api/v2/builds/:build_id", Method: "GET", Name: atc.GetBuild},
+			}))
+
+			Expect(routes[0].Path).To(Equal("/builds/:build_id"))
+		})
+	})
+})
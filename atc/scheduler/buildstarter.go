@@ -95,7 +95,7 @@ func (s *buildStarter) tryStartNextPendingBuild(
 		return false, nil
 	}
 
-	if nextPendingBuild.IsManuallyTriggered() {
+	if nextPendingBuild.IsManuallyTriggered() && nextPendingBuild.RerunOf() == 0 {
 		jobBuildInputs := job.Config().Inputs()
 		for _, input := range jobBuildInputs {
 			scanLog := logger.Session("scan", lager.Data{
@@ -127,13 +127,25 @@ func (s *buildStarter) tryStartNextPendingBuild(
 		resourceTypes = dbResourceTypes.Deserialize()
 	}
 
-	buildInputs, found, err := job.GetNextBuildInputs()
-	if err != nil {
-		logger.Error("failed-to-get-next-build-inputs", err)
-		return false, err
-	}
-	if !found {
-		return false, nil
+	var buildInputs []db.BuildInput
+	if nextPendingBuild.RerunOf() != 0 {
+		// reruns were given the inputs of the build they rerun when they were
+		// created, rather than the latest versions
+		buildInputs, err = nextPendingBuild.Inputs()
+		if err != nil {
+			logger.Error("failed-to-get-rerun-build-inputs", err)
+			return false, err
+		}
+	} else {
+		var found bool
+		buildInputs, found, err = job.GetNextBuildInputs()
+		if err != nil {
+			logger.Error("failed-to-get-next-build-inputs", err)
+			return false, err
+		}
+		if !found {
+			return false, nil
+		}
 	}
 
 	pipelinePaused, err := s.pipeline.CheckPaused()
@@ -627,5 +627,62 @@ var _ = Describe("BuildStarter", func() {
 				})
 			})
 		})
+
+		Context("when rerunning a build", func() {
+			BeforeEach(func() {
+				job = new(dbfakes.FakeJob)
+				job.NameReturns("some-job")
+				job.ConfigReturns(atc.JobConfig{Name: "some-job", Plan: atc.PlanSequence{{Get: "input-1"}}})
+
+				createdBuild.RerunOfReturns(42)
+				createdBuild.ScheduleReturns(true, nil)
+
+				fakeEngine.CreateBuildReturns(new(enginefakes.FakeBuild), nil)
+			})
+
+			JustBeforeEach(func() {
+				tryStartErr = buildStarter.TryStartPendingBuildsForJob(
+					lagertest.NewTestLogger("test"),
+					job,
+					db.Resources{resource},
+					versionedResourceTypes,
+					pendingBuilds,
+				)
+			})
+
+			Context("when getting the inputs of the build fails", func() {
+				BeforeEach(func() {
+					createdBuild.InputsReturns(nil, disaster)
+				})
+
+				It("returns the error", func() {
+					Expect(tryStartErr).To(Equal(disaster))
+				})
+
+				It("doesn't try to mark the build as scheduled", func() {
+					Expect(createdBuild.ScheduleCallCount()).To(BeZero())
+				})
+			})
+
+			Context("when getting the inputs of the build succeeds", func() {
+				BeforeEach(func() {
+					createdBuild.InputsReturns([]db.BuildInput{{Name: "input-1", Version: atc.Version{"ref": "v1"}}}, nil)
+				})
+
+				It("doesn't scan for or map the latest versions", func() {
+					Expect(fakeScanner.ScanCallCount()).To(BeZero())
+					Expect(fakeInputMapper.SaveNextInputMappingCallCount()).To(BeZero())
+					Expect(job.GetNextBuildInputsCallCount()).To(BeZero())
+				})
+
+				It("creates the build plan with the inputs of the build", func() {
+					Expect(tryStartErr).NotTo(HaveOccurred())
+
+					Expect(fakeFactory.CreateCallCount()).To(Equal(1))
+					_, _, _, actualBuildInputs := fakeFactory.CreateArgsForCall(0)
+					Expect(actualBuildInputs).To(Equal([]db.BuildInput{{Name: "input-1", Version: atc.Version{"ref": "v1"}}}))
+				})
+			})
+		})
 	})
 })
@@ -40,6 +40,9 @@ func (wrappa *APIAuthWrappa) Wrap(handlers rata.Handlers) rata.Handlers {
 		case atc.DownloadCLI,
 			atc.CheckResourceWebHook,
 			atc.GetInfo,
+			atc.Healthz,
+			atc.Readyz,
+			atc.GetMetrics,
 			atc.ListTeams,
 			atc.ListAllPipelines,
 			atc.ListPipelines,
@@ -61,7 +64,8 @@ func (wrappa *APIAuthWrappa) Wrap(handlers rata.Handlers) rata.Handlers {
 			newHandler = wrappa.checkBuildReadAccessHandlerFactory.CheckIfPrivateJobHandler(handler, rejector)
 
 			// resource belongs to authorized team
-		case atc.AbortBuild:
+		case atc.AbortBuild,
+			atc.GetBuildArtifact:
 			newHandler = wrappa.checkBuildWriteAccessHandlerFactory.HandlerFor(handler, rejector)
 
 		// requester is system, admin team, or worker owning team
@@ -111,6 +115,9 @@ func (wrappa *APIAuthWrappa) Wrap(handlers rata.Handlers) rata.Handlers {
 
 		case atc.GetLogLevel,
 			atc.SetLogLevel,
+			atc.GetEmitters,
+			atc.SetEmitterState,
+			atc.GetMetricCatalog,
 			atc.GetInfoCreds:
 			newHandler = auth.CheckAdminHandler(handler, rejector)
 
@@ -118,6 +125,7 @@ func (wrappa *APIAuthWrappa) Wrap(handlers rata.Handlers) rata.Handlers {
 		case atc.CheckResource,
 			atc.CheckResourceType,
 			atc.CreateJobBuild,
+			atc.RerunJobBuild,
 			atc.CreatePipelineBuild,
 			atc.DeletePipeline,
 			atc.DisableResourceVersion,
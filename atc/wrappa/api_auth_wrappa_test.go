@@ -141,6 +141,9 @@ var _ = Describe("APIAuthWrappa", func() {
 			expectedHandlers = rata.Handlers{
 				//unauthenticated / delegating to handler
 				atc.GetInfo:              unauthenticated(inputHandlers[atc.GetInfo]),
+				atc.Healthz:              unauthenticated(inputHandlers[atc.Healthz]),
+				atc.Readyz:               unauthenticated(inputHandlers[atc.Readyz]),
+				atc.GetMetrics:           unauthenticated(inputHandlers[atc.GetMetrics]),
 				atc.DownloadCLI:          unauthenticated(inputHandlers[atc.DownloadCLI]),
 				atc.CheckResourceWebHook: unauthenticated(inputHandlers[atc.CheckResourceWebHook]),
 				atc.ListAllPipelines:     unauthenticated(inputHandlers[atc.ListAllPipelines]),
@@ -162,7 +165,8 @@ var _ = Describe("APIAuthWrappa", func() {
 				atc.GetBuildPreparation: checksIfPrivateJob(inputHandlers[atc.GetBuildPreparation]),
 
 				// resource belongs to authorized team
-				atc.AbortBuild: checkWritePermissionForBuild(inputHandlers[atc.AbortBuild]),
+				atc.AbortBuild:       checkWritePermissionForBuild(inputHandlers[atc.AbortBuild]),
+				atc.GetBuildArtifact: checkWritePermissionForBuild(inputHandlers[atc.GetBuildArtifact]),
 
 				// resource belongs to authorized team
 				atc.PruneWorker:              checkTeamAccessForWorker(inputHandlers[atc.PruneWorker]),
@@ -211,10 +215,15 @@ var _ = Describe("APIAuthWrappa", func() {
 				atc.SetLogLevel:  authenticatedAndAdmin(inputHandlers[atc.SetLogLevel]),
 				atc.GetInfoCreds: authenticatedAndAdmin(inputHandlers[atc.GetInfoCreds]),
 
+				atc.GetEmitters:      authenticatedAndAdmin(inputHandlers[atc.GetEmitters]),
+				atc.SetEmitterState:  authenticatedAndAdmin(inputHandlers[atc.SetEmitterState]),
+				atc.GetMetricCatalog: authenticatedAndAdmin(inputHandlers[atc.GetMetricCatalog]),
+
 				// authorized (requested team matches resource team)
 				atc.CheckResource:           authorized(inputHandlers[atc.CheckResource]),
 				atc.CheckResourceType:       authorized(inputHandlers[atc.CheckResourceType]),
 				atc.CreateJobBuild:          authorized(inputHandlers[atc.CreateJobBuild]),
+				atc.RerunJobBuild:           authorized(inputHandlers[atc.RerunJobBuild]),
 				atc.DeletePipeline:          authorized(inputHandlers[atc.DeletePipeline]),
 				atc.DisableResourceVersion:  authorized(inputHandlers[atc.DisableResourceVersion]),
 				atc.EnableResourceVersion:   authorized(inputHandlers[atc.EnableResourceVersion]),
@@ -6,6 +6,7 @@ import (
 	"github.com/concourse/concourse/atc"
 	"github.com/concourse/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/concourse/fly/rc"
+	"github.com/concourse/concourse/go-concourse/concourse"
 )
 
 type CheckResourceCommand struct {
@@ -30,6 +31,10 @@ func (command *CheckResourceCommand) Execute(args []string) error {
 	}
 
 	found, err := target.Team().CheckResource(command.Resource.PipelineName, command.Resource.ResourceName, version)
+	if err == concourse.ErrCheckInProgress {
+		return fmt.Errorf("a check of '%s' is already in progress, try again once it has finished", command.Resource.ResourceName)
+	}
+
 	if err != nil {
 		return err
 	}
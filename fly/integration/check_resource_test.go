@@ -123,6 +123,28 @@ var _ = Describe("CheckResource", func() {
 		})
 	})
 
+	Context("when a check of the resource is already in progress", func() {
+		BeforeEach(func() {
+			expectedURL := "/api/v1/teams/main/pipelines/mypipeline/resources/myresource/check"
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", expectedURL),
+					ghttp.RespondWith(http.StatusConflict, ""),
+				),
+			)
+		})
+
+		It("fails with error", func() {
+			flyCmd = exec.Command(flyPath, "-t", targetName, "check-resource", "-r", "mypipeline/myresource")
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			Expect(sess.Err).To(gbytes.Say("a check of 'myresource' is already in progress"))
+		})
+	})
+
 	Context("When resource check returns internal server error", func() {
 		BeforeEach(func() {
 			expectedURL := "/api/v1/teams/main/pipelines/mypipeline/resources/myresource/check"
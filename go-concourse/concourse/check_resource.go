@@ -3,6 +3,7 @@ package concourse
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/tedsuo/rata"
 )
 
+// ErrCheckInProgress is returned when the resource is already being checked.
+var ErrCheckInProgress = errors.New("check already in progress")
+
 type CheckResourceError struct {
 	atc.CheckResponseBody
 }
@@ -57,6 +61,8 @@ func (team *team) CheckResource(pipelineName string, resourceName string, versio
 				}
 
 				return false, checkResourceErr
+			case http.StatusConflict:
+				return true, ErrCheckInProgress
 			case http.StatusInternalServerError:
 				checkResourceErr := CheckResourceError{
 					atc.CheckResponseBody{
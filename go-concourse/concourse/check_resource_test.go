@@ -81,6 +81,25 @@ var _ = Describe("CheckResource", func() {
 		})
 	})
 
+	Context("when a check of the resource is already in progress", func() {
+		BeforeEach(func() {
+			expectedURL := "/api/v1/teams/some-team/pipelines/mypipeline/resources/myresource/check"
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", expectedURL),
+					ghttp.RespondWith(http.StatusConflict, ""),
+				),
+			)
+		})
+
+		It("returns ErrCheckInProgress", func() {
+			found, err := team.CheckResource("mypipeline", "myresource", atc.Version{"ref": "fake-ref"})
+			Expect(err).To(Equal(concourse.ErrCheckInProgress))
+			Expect(found).To(BeTrue())
+		})
+	})
+
 	Context("when ATC responds with an internal server error", func() {
 		BeforeEach(func() {
 			expectedURL := "/api/v1/teams/some-team/pipelines/mypipeline/resources/myresource/check"
@@ -0,0 +1,77 @@
+package publichandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sync"
+)
+
+type etagHandler struct {
+	fs      http.FileSystem
+	handler http.Handler
+
+	etags     map[string]string
+	etagsLock sync.RWMutex
+}
+
+// WithETags sets the ETag of files in fs, a hash of their contents, before
+// calling the handler serving them, so that a http.FileServer can answer
+// requests with a matching If-None-Match with 304 Not Modified.
+func WithETags(fs http.FileSystem, handler http.Handler) http.Handler {
+	return &etagHandler{
+		fs:      fs,
+		handler: handler,
+		etags:   map[string]string{},
+	}
+}
+
+func (h *etagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	etag, found := h.etag(path.Clean("/" + r.URL.Path))
+	if found {
+		w.Header().Set("ETag", etag)
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
+
+// etag returns the ETag of the file at the path, computing it the first time
+// it is requested; the files don't change while the process runs.
+func (h *etagHandler) etag(name string) (string, bool) {
+	h.etagsLock.RLock()
+	etag, found := h.etags[name]
+	h.etagsLock.RUnlock()
+
+	if found {
+		return etag, true
+	}
+
+	file, err := h.fs.Open(name)
+	if err != nil {
+		return "", false
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", false
+	}
+
+	etag = fmt.Sprintf(`"%s"`, hex.EncodeToString(hash.Sum(nil)[:16]))
+
+	h.etagsLock.Lock()
+	h.etags[name] = etag
+	h.etagsLock.Unlock()
+
+	return etag, true
+}
@@ -0,0 +1,84 @@
+package publichandler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/concourse/web/publichandler"
+)
+
+var _ = Describe("WithETags", func() {
+	var (
+		dir     string
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "public")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = os.Mkdir(filepath.Join(dir, "fonts"), 0755)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(dir, "fonts", "some-font.ttf"), []byte("some-font"), 0644)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(dir, "other-font.ttf"), []byte("other-font"), 0644)
+		Expect(err).ToNot(HaveOccurred())
+
+		fs := http.Dir(dir)
+		handler = publichandler.WithETags(fs, http.FileServer(fs))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest("GET", path, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		for name, values := range header {
+			request.Header[name] = values
+		}
+
+		handler.ServeHTTP(recorder, request)
+
+		return recorder
+	}
+
+	It("sets an ETag derived from the contents of the file", func() {
+		etag := get("/fonts/some-font.ttf", nil).Header().Get("ETag")
+		Expect(etag).To(MatchRegexp(`^"[0-9a-f]{32}"$`))
+
+		Expect(get("/fonts/some-font.ttf", nil).Header().Get("ETag")).To(Equal(etag))
+		Expect(get("/other-font.ttf", nil).Header().Get("ETag")).ToNot(Equal(etag))
+	})
+
+	It("responds with 304 when the ETag matches If-None-Match", func() {
+		etag := get("/fonts/some-font.ttf", nil).Header().Get("ETag")
+
+		recorder := get("/fonts/some-font.ttf", http.Header{"If-None-Match": {etag}})
+		Expect(recorder.Code).To(Equal(http.StatusNotModified))
+		Expect(recorder.Body.Len()).To(BeZero())
+	})
+
+	It("serves the file when the ETag does not match If-None-Match", func() {
+		recorder := get("/fonts/some-font.ttf", http.Header{"If-None-Match": {`"stale"`}})
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal("some-font"))
+	})
+
+	It("does not set an ETag for directories or missing files", func() {
+		Expect(get("/fonts/", nil).Header().Get("ETag")).To(BeEmpty())
+		Expect(get("/missing.ttf", nil).Header().Get("ETag")).To(BeEmpty())
+	})
+})
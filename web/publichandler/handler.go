@@ -7,5 +7,7 @@ import (
 )
 
 func NewHandler() (http.Handler, error) {
-	return CacheNearlyForever(http.StripPrefix("/public/", http.FileServer(packr.NewBox("../public")))), nil
+	box := packr.NewBox("../public")
+
+	return CacheNearlyForever(http.StripPrefix("/public/", WithETags(box, http.FileServer(box)))), nil
 }
@@ -0,0 +1,71 @@
+package publichandler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/concourse/web/publichandler"
+)
+
+var _ = Describe("Handler", func() {
+	var handler http.Handler
+
+	BeforeEach(func() {
+		var err error
+		handler, err = publichandler.NewHandler()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("when the request is a HEAD", func() {
+		It("responds with the asset's headers but no body", func() {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("HEAD", "/public/fonts/OFL.txt", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Length")).ToNot(BeEmpty())
+			Expect(recorder.Header().Get("Last-Modified")).ToNot(BeEmpty())
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+	})
+
+	Context("when the request is conditional", func() {
+		var response *httptest.ResponseRecorder
+
+		BeforeEach(func() {
+			response = httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/public/fonts/OFL.txt", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			handler.ServeHTTP(response, request)
+			Expect(response.Code).To(Equal(http.StatusOK))
+		})
+
+		It("responds with 304 when the ETag matches", func() {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/public/fonts/OFL.txt", nil)
+			Expect(err).ToNot(HaveOccurred())
+			request.Header.Set("If-None-Match", response.Header().Get("ETag"))
+
+			handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotModified))
+		})
+
+		It("responds with 304 when the asset was not modified since", func() {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/public/fonts/OFL.txt", nil)
+			Expect(err).ToNot(HaveOccurred())
+			request.Header.Set("If-Modified-Since", response.Header().Get("Last-Modified"))
+
+			handler.ServeHTTP(recorder, request)
+
+			Expect(recorder.Code).To(Equal(http.StatusNotModified))
+		})
+	})
+})